@@ -0,0 +1,178 @@
+package adapters
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"time"
+
+	"github.com/boreq/errors"
+	"github.com/contentforward/bolt-ui/application"
+	"github.com/contentforward/bolt-ui/logging"
+	bolt "go.etcd.io/bbolt"
+)
+
+// AuditLog records the published changes in a bucket of a separate bolt
+// database so that the browsed database isn't modified. Each browsed
+// database uses a bucket named after it. An audit log without a database is
+// disabled.
+type AuditLog struct {
+	db     *bolt.DB
+	bucket []byte
+	now    func() time.Time
+	log    logging.Logger
+}
+
+func NewAuditLog(db *bolt.DB, bucket string) *AuditLog {
+	return &AuditLog{
+		db:     db,
+		bucket: []byte(bucket),
+		now:    time.Now,
+		log:    logging.New("adapters.AuditLog"),
+	}
+}
+
+// Publish appends an entry describing the change. The entry is written
+// before returning so that it is stored by the time the response is sent.
+func (a *AuditLog) Publish(change application.Change) {
+	if a.db == nil {
+		return
+	}
+
+	if err := a.append(change); err != nil {
+		a.log.Error("could not append the audit entry", "err", err)
+	}
+}
+
+func (a *AuditLog) append(change application.Change) error {
+	entry := auditEntry{
+		Time:      a.now(),
+		Operation: string(change.Operation),
+	}
+
+	for _, key := range change.Path {
+		entry.Path = append(entry.Path, key.Bytes())
+	}
+
+	if change.Key != nil {
+		entry.Key = change.Key.Bytes()
+	}
+
+	value, err := json.Marshal(entry)
+	if err != nil {
+		return errors.Wrap(err, "marshal failed")
+	}
+
+	return a.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(a.bucket)
+		if err != nil {
+			return errors.Wrap(err, "could not create the bucket")
+		}
+
+		sequence, err := bucket.NextSequence()
+		if err != nil {
+			return errors.Wrap(err, "could not get the next sequence")
+		}
+
+		return bucket.Put(auditEntryKey(sequence), value)
+	})
+}
+
+// ListAuditEntries returns the entries recorded at or after the given time
+// with IDs lower than before, newest first. The entries are stored under
+// their sequence numbers so they are found by walking the bucket backwards.
+// A limit of zero returns all of them.
+func (a *AuditLog) ListAuditEntries(since time.Time, before uint64, limit int) ([]application.AuditEntry, error) {
+	if a.db == nil {
+		return nil, application.ErrAuditLogDisabled
+	}
+
+	var entries []application.AuditEntry
+
+	if err := a.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(a.bucket)
+		if bucket == nil {
+			return nil
+		}
+
+		c := bucket.Cursor()
+		for k, v := seekBefore(c, before); k != nil; k, v = c.Prev() {
+			if limit > 0 && len(entries) >= limit {
+				return nil
+			}
+
+			var stored auditEntry
+			if err := json.Unmarshal(v, &stored); err != nil {
+				return errors.Wrap(err, "unmarshal failed")
+			}
+
+			if stored.Time.Before(since) {
+				return nil
+			}
+
+			entry, err := toAuditEntry(binary.BigEndian.Uint64(k), stored)
+			if err != nil {
+				return errors.Wrap(err, "invalid entry")
+			}
+
+			entries = append(entries, entry)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, errors.Wrap(err, "transaction failed")
+	}
+
+	return entries, nil
+}
+
+// seekBefore moves the cursor to the last entry with a sequence lower than
+// before, zero moves it to the last entry.
+func seekBefore(c *bolt.Cursor, before uint64) ([]byte, []byte) {
+	if before == 0 {
+		return c.Last()
+	}
+
+	if k, _ := c.Seek(auditEntryKey(before)); k == nil {
+		return c.Last()
+	}
+	return c.Prev()
+}
+
+func auditEntryKey(sequence uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, sequence)
+	return key
+}
+
+type auditEntry struct {
+	Time      time.Time `json:"time"`
+	Operation string    `json:"operation"`
+	Path      [][]byte  `json:"path"`
+	Key       []byte    `json:"key,omitempty"`
+}
+
+func toAuditEntry(id uint64, stored auditEntry) (application.AuditEntry, error) {
+	entry := application.AuditEntry{
+		ID:        id,
+		Time:      stored.Time,
+		Operation: application.ChangeOperation(stored.Operation),
+	}
+
+	for _, b := range stored.Path {
+		key, err := application.NewKey(b)
+		if err != nil {
+			return application.AuditEntry{}, errors.Wrap(err, "invalid path")
+		}
+		entry.Path = append(entry.Path, key)
+	}
+
+	if stored.Key != nil {
+		key, err := application.NewKey(stored.Key)
+		if err != nil {
+			return application.AuditEntry{}, errors.Wrap(err, "invalid key")
+		}
+		entry.Key = &key
+	}
+
+	return entry, nil
+}
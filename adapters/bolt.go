@@ -8,7 +8,16 @@ import (
 	bolt "go.etcd.io/bbolt"
 )
 
-func NewBolt(path string) (*bolt.DB, error) {
+// DefaultOpenTimeout is the suggested amount of time NewBolt should wait for
+// the file lock before giving up.
+const DefaultOpenTimeout = 5 * time.Second
+
+// NewBolt opens an existing database file. An error wrapping os.ErrNotExist
+// is returned if the file doesn't exist so that a mistyped path doesn't
+// result in a new empty database. If the file lock can't be obtained within
+// the specified timeout (e.g. because another process holds it) an error
+// wrapping bolt.ErrTimeout is returned.
+func NewBolt(path string, readOnly bool, timeout time.Duration) (*bolt.DB, error) {
 	_, err := os.Stat(path)
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
@@ -18,14 +27,25 @@ func NewBolt(path string) (*bolt.DB, error) {
 		return nil, errors.Wrap(err, "could not stat the database file")
 	}
 
+	return openBolt(path, readOnly, timeout)
+}
+
+// CreateBolt opens the database file creating it if it doesn't exist. See
+// NewBolt.
+func CreateBolt(path string, timeout time.Duration) (*bolt.DB, error) {
+	return openBolt(path, false, timeout)
+}
+
+func openBolt(path string, readOnly bool, timeout time.Duration) (*bolt.DB, error) {
 	options := &bolt.Options{
-		Timeout: 5 * time.Second,
+		Timeout:  timeout,
+		ReadOnly: readOnly,
 	}
 
 	db, err := bolt.Open(path, 0600, options)
 	if err != nil {
 		if errors.Is(err, bolt.ErrTimeout) {
-			return nil, errors.Wrap(err, "error opening the database (is another instance of the program running?)")
+			return nil, errors.Wrap(err, "database is locked (is another instance of the program running?)")
 		}
 		return nil, errors.Wrap(err, "error opening the database")
 	}
@@ -0,0 +1,105 @@
+package adapters
+
+import (
+	"encoding/hex"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/contentforward/bolt-ui/application"
+)
+
+// Cache is an in-memory cache whose entries expire after the configured
+// amount of time or when a related change is published. A ttl of zero
+// disables the cache.
+type Cache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mutex      sync.Mutex
+	entries    map[string]cacheEntry
+	generation uint64
+}
+
+type cacheEntry struct {
+	path    []application.Key
+	value   interface{}
+	expires time.Time
+}
+
+func NewCache(ttl time.Duration) *Cache {
+	return &Cache{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]cacheEntry),
+	}
+}
+
+func (c *Cache) GetOrLoad(query string, path []application.Key, load func() (interface{}, error)) (interface{}, error) {
+	if c.ttl <= 0 {
+		return load()
+	}
+
+	key := cacheKey(query, path)
+
+	c.mutex.Lock()
+	entry, ok := c.entries[key]
+	generation := c.generation
+	c.mutex.Unlock()
+
+	if ok && c.now().Before(entry.expires) {
+		return entry.value, nil
+	}
+
+	value, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	// A change published while the value was loading could have made it
+	// stale already.
+	if c.generation == generation {
+		c.entries[key] = cacheEntry{
+			path:    path,
+			value:   value,
+			expires: c.now().Add(c.ttl),
+		}
+	}
+
+	return value, nil
+}
+
+// Publish invalidates the entries related to the changed path. It is called
+// synchronously after each change is committed.
+func (c *Cache) Publish(change application.Change) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.generation++
+
+	for key, entry := range c.entries {
+		if pathsRelated(entry.path, change.Path) {
+			delete(c.entries, key)
+		}
+	}
+}
+
+func cacheKey(query string, path []application.Key) string {
+	elements := []string{query}
+	for _, key := range path {
+		elements = append(elements, hex.EncodeToString(key.Bytes()))
+	}
+	return strings.Join(elements, "/")
+}
+
+// ChangePublishers passes each change to all publishers in order.
+type ChangePublishers []application.ChangePublisher
+
+func (p ChangePublishers) Publish(change application.Change) {
+	for _, publisher := range p {
+		publisher.Publish(change)
+	}
+}
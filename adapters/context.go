@@ -0,0 +1,32 @@
+package adapters
+
+import (
+	"context"
+)
+
+// contextCheckInterval specifies how many keys are visited between checking
+// if the context is done.
+const contextCheckInterval = 256
+
+// contextChecker is used by the operations which iterate over an arbitrary
+// number of keys to stop early once the context is done.
+type contextChecker struct {
+	ctx     context.Context
+	visited int
+}
+
+func newContextChecker(ctx context.Context) *contextChecker {
+	return &contextChecker{
+		ctx: ctx,
+	}
+}
+
+// Visit should be called for each visited key. It periodically returns the
+// error of the context.
+func (c *contextChecker) Visit() error {
+	c.visited++
+	if c.visited%contextCheckInterval == 0 {
+		return c.ctx.Err()
+	}
+	return nil
+}
@@ -1,6 +1,10 @@
 package adapters
 
 import (
+	"bytes"
+	"context"
+	"io"
+
 	"github.com/boreq/errors"
 	"github.com/contentforward/bolt-ui/application"
 	"go.etcd.io/bbolt"
@@ -9,12 +13,14 @@ import (
 const perPage = 10
 
 type Database struct {
-	tx *bbolt.Tx
+	tx          *bbolt.Tx
+	compression *ValueCompression
 }
 
-func NewDatabase(tx *bbolt.Tx) *Database {
+func NewDatabase(tx *bbolt.Tx, compression *ValueCompression) *Database {
 	return &Database{
-		tx: tx,
+		tx:          tx,
+		compression: compression,
 	}
 }
 
@@ -34,7 +40,431 @@ func (d *Database) Browse(path []application.Key, before, after, from *applicati
 	}
 
 	c := bucket.Cursor()
-	return d.iterate(c, before, after, from, isBucket)
+	entries, err := d.iterate(c, before, after, from, isBucket)
+	if err != nil {
+		return nil, errors.Wrap(err, "iteration failed")
+	}
+
+	return d.decodeEntries(entries)
+}
+
+func (d *Database) ResolvePath(path []application.Key) (application.PathInfo, error) {
+	_, info := d.resolvePath(path)
+	return info, nil
+}
+
+func (d *Database) ListBuckets(path []application.Key) ([]application.Key, error) {
+	var buckets []application.Key
+
+	appendBucket := func(name []byte) error {
+		key, err := application.NewKey(name)
+		if err != nil {
+			return errors.Wrap(err, "could not create a key")
+		}
+
+		buckets = append(buckets, key)
+		return nil
+	}
+
+	if len(path) == 0 {
+		if err := d.tx.ForEach(func(name []byte, _ *bbolt.Bucket) error {
+			return appendBucket(name)
+		}); err != nil {
+			return nil, errors.Wrap(err, "iteration failed")
+		}
+
+		return buckets, nil
+	}
+
+	bucket, err := d.getBucket(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get the bucket")
+	}
+
+	if err := bucket.ForEach(func(k, v []byte) error {
+		if v != nil {
+			return nil
+		}
+		return appendBucket(k)
+	}); err != nil {
+		return nil, errors.Wrap(err, "iteration failed")
+	}
+
+	return buckets, nil
+}
+
+func (d *Database) ListKeys(path []application.Key, after *application.Key, limit int, sizes, descending bool) (application.KeysPage, error) {
+	if len(path) == 0 {
+		return listKeys(d.tx.Cursor(), after, limit, sizes, descending, isAlwaysBucket, d.compression)
+	}
+
+	bucket, err := d.getBucket(path)
+	if err != nil {
+		return application.KeysPage{}, errors.Wrap(err, "could not get the bucket")
+	}
+
+	isBucket := func(key []byte) bool {
+		return bucket.Bucket(key) != nil
+	}
+
+	return listKeys(bucket.Cursor(), after, limit, sizes, descending, isBucket, d.compression)
+}
+
+// CountKeys iterates over the bucket as the KeyN field of the bucket stats
+// also includes the keys of the nested buckets.
+func (d *Database) CountKeys(ctx context.Context, path []application.Key) (int, error) {
+	cursor := d.tx.Cursor()
+
+	if len(path) != 0 {
+		bucket, err := d.getBucket(path)
+		if err != nil {
+			return 0, errors.Wrap(err, "could not get the bucket")
+		}
+		cursor = bucket.Cursor()
+	}
+
+	checker := newContextChecker(ctx)
+
+	n := 0
+	for k, _ := cursor.First(); k != nil; k, _ = cursor.Next() {
+		if err := checker.Visit(); err != nil {
+			return 0, err
+		}
+		n++
+	}
+
+	return n, nil
+}
+
+func (d *Database) SearchKeysByPrefix(path []application.Key, prefix []byte, limit int) ([]application.KeyInfo, error) {
+	if len(path) == 0 {
+		return searchKeysByPrefix(d.tx.Cursor(), prefix, limit, isAlwaysBucket)
+	}
+
+	bucket, err := d.getBucket(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get the bucket")
+	}
+
+	isBucket := func(key []byte) bool {
+		return bucket.Bucket(key) != nil
+	}
+
+	return searchKeysByPrefix(bucket.Cursor(), prefix, limit, isBucket)
+}
+
+func (d *Database) CountKeysByPrefix(ctx context.Context, path []application.Key, prefix []byte) (int, error) {
+	cursor := d.tx.Cursor()
+
+	if len(path) != 0 {
+		bucket, err := d.getBucket(path)
+		if err != nil {
+			return 0, errors.Wrap(err, "could not get the bucket")
+		}
+		cursor = bucket.Cursor()
+	}
+
+	checker := newContextChecker(ctx)
+
+	n := 0
+	for k, _ := cursor.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = cursor.Next() {
+		if err := checker.Visit(); err != nil {
+			return 0, err
+		}
+		n++
+	}
+
+	return n, nil
+}
+
+func (d *Database) GetValue(path []application.Key, key application.Key) (application.Value, error) {
+	b, err := d.getValue(path, key)
+	if err != nil {
+		return application.Value{}, errors.Wrap(err, "could not get the value")
+	}
+
+	value, err := application.NewValue(b)
+	if err != nil {
+		return application.Value{}, errors.Wrap(err, "could not create a value")
+	}
+
+	return value, nil
+}
+
+func (d *Database) StreamValue(path []application.Key, key application.Key, w application.ValueWriter) error {
+	b, err := d.getValue(path, key)
+	if err != nil {
+		return errors.Wrap(err, "could not get the value")
+	}
+
+	return w(len(b), bytes.NewReader(b))
+}
+
+// getValue returns the value which is only valid for the life of the
+// transaction.
+func (d *Database) getValue(path []application.Key, key application.Key) ([]byte, error) {
+	if len(path) == 0 {
+		return nil, d.rootKeyError(key)
+	}
+
+	bucket, err := d.getBucket(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get the bucket")
+	}
+
+	if bucket.Bucket(key.Bytes()) != nil {
+		return nil, application.ErrNotAValue
+	}
+
+	if !keyExists(bucket, key.Bytes()) {
+		return nil, application.ErrKeyNotFound
+	}
+
+	return d.compression.Decode(bucket.Get(key.Bytes())), nil
+}
+
+func (d *Database) PutValue(path []application.Key, key application.Key, value application.Value) error {
+	if len(path) == 0 {
+		return errors.New("values can not be stored in the root of the database")
+	}
+
+	bucket, err := d.getBucket(path)
+	if err != nil {
+		return errors.Wrap(err, "could not get the bucket")
+	}
+
+	if bucket.Bucket(key.Bytes()) != nil {
+		return application.ErrNotAValue
+	}
+
+	stored, err := d.compression.Encode(value.Bytes())
+	if err != nil {
+		return errors.Wrap(err, "could not encode the value")
+	}
+
+	return bucket.Put(key.Bytes(), stored)
+}
+
+func (d *Database) AppendValue(path []application.Key, value application.Value) (uint64, error) {
+	if len(path) == 0 {
+		return 0, errors.New("values can not be stored in the root of the database")
+	}
+
+	bucket, err := d.getBucket(path)
+	if err != nil {
+		return 0, errors.Wrap(err, "could not get the bucket")
+	}
+
+	sequence, err := bucket.NextSequence()
+	if err != nil {
+		return 0, errors.Wrap(err, "could not get the next sequence")
+	}
+
+	key := application.NewUint64Key(sequence)
+
+	if keyExists(bucket, key.Bytes()) {
+		return 0, application.ErrValueExists
+	}
+
+	stored, err := d.compression.Encode(value.Bytes())
+	if err != nil {
+		return 0, errors.Wrap(err, "could not encode the value")
+	}
+
+	if err := bucket.Put(key.Bytes(), stored); err != nil {
+		return 0, errors.Wrap(err, "could not put the value")
+	}
+
+	return sequence, nil
+}
+
+func (d *Database) DeleteKey(path []application.Key, key application.Key) error {
+	if len(path) == 0 {
+		return d.rootKeyError(key)
+	}
+
+	bucket, err := d.getBucket(path)
+	if err != nil {
+		return errors.Wrap(err, "could not get the bucket")
+	}
+
+	if bucket.Bucket(key.Bytes()) != nil {
+		return application.ErrNotAValue
+	}
+
+	if !keyExists(bucket, key.Bytes()) {
+		return application.ErrKeyNotFound
+	}
+
+	return bucket.Delete(key.Bytes())
+}
+
+func (d *Database) CreateBucket(path []application.Key, name application.Key) error {
+	if len(path) == 0 {
+		if _, err := d.tx.CreateBucket(name.Bytes()); err != nil {
+			return convertCreateBucketError(err)
+		}
+		return nil
+	}
+
+	bucket, err := d.getBucket(path)
+	if err != nil {
+		return errors.Wrap(err, "could not get the bucket")
+	}
+
+	if _, err := bucket.CreateBucket(name.Bytes()); err != nil {
+		return convertCreateBucketError(err)
+	}
+
+	return nil
+}
+
+func (d *Database) DeleteBucket(path []application.Key) error {
+	name := path[len(path)-1]
+
+	if len(path) == 1 {
+		if err := d.tx.DeleteBucket(name.Bytes()); err != nil {
+			return convertDeleteBucketError(err)
+		}
+		return nil
+	}
+
+	parent, err := d.getBucket(path[:len(path)-1])
+	if err != nil {
+		return errors.Wrap(err, "could not get the parent bucket")
+	}
+
+	if err := parent.DeleteBucket(name.Bytes()); err != nil {
+		return convertDeleteBucketError(err)
+	}
+
+	return nil
+}
+
+func (d *Database) MoveBucket(source []application.Key, destinationParent []application.Key, name application.Key) error {
+	if _, err := d.getBucket(source); err != nil {
+		return errors.Wrap(err, "could not get the source bucket")
+	}
+
+	if err := d.CreateBucket(destinationParent, name); err != nil {
+		return errors.Wrap(err, "could not create the destination bucket")
+	}
+
+	destination := make([]application.Key, len(destinationParent)+1)
+	copy(destination, destinationParent)
+	destination[len(destinationParent)] = name
+
+	destinationBucket, err := d.getBucket(destination)
+	if err != nil {
+		return errors.Wrap(err, "could not get the destination bucket")
+	}
+
+	sourceBucket, err := d.getBucket(source)
+	if err != nil {
+		return errors.Wrap(err, "could not get the source bucket")
+	}
+
+	if err := copyBucket(sourceBucket, destinationBucket); err != nil {
+		return errors.Wrap(err, "could not copy the bucket")
+	}
+
+	if err := d.DeleteBucket(source); err != nil {
+		return errors.Wrap(err, "could not delete the source bucket")
+	}
+
+	return nil
+}
+
+func (d *Database) CountBucketContents(ctx context.Context, path []application.Key) (application.BucketContents, error) {
+	var contents application.BucketContents
+	checker := newContextChecker(ctx)
+
+	if len(path) == 0 {
+		if err := d.tx.ForEach(func(_ []byte, b *bbolt.Bucket) error {
+			contents.Buckets++
+			return countBucketContents(b, &contents, checker)
+		}); err != nil {
+			return contents, errors.Wrap(err, "iteration failed")
+		}
+
+		return contents, nil
+	}
+
+	bucket, err := d.getBucket(path)
+	if err != nil {
+		return contents, errors.Wrap(err, "could not get the bucket")
+	}
+
+	if err := countBucketContents(bucket, &contents, checker); err != nil {
+		return contents, errors.Wrap(err, "could not count")
+	}
+
+	return contents, nil
+}
+
+func (d *Database) BucketStats(path []application.Key) (application.BucketStats, error) {
+	if len(path) == 0 {
+		var stats bbolt.BucketStats
+
+		if err := d.tx.ForEach(func(_ []byte, b *bbolt.Bucket) error {
+			stats.Add(b.Stats())
+			return nil
+		}); err != nil {
+			return application.BucketStats{}, errors.Wrap(err, "iteration failed")
+		}
+
+		return toBucketStats(stats), nil
+	}
+
+	bucket, err := d.getBucket(path)
+	if err != nil {
+		return application.BucketStats{}, errors.Wrap(err, "could not get the bucket")
+	}
+
+	return toBucketStats(bucket.Stats()), nil
+}
+
+func (d *Database) BucketTree(ctx context.Context, path []application.Key, maxDepth int) ([]application.BucketTreeNode, error) {
+	checker := newContextChecker(ctx)
+
+	if len(path) == 0 {
+		return bucketTree(d.tx.Cursor(), d.tx.Bucket, 1, maxDepth, checker)
+	}
+
+	bucket, err := d.getBucket(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get the bucket")
+	}
+
+	return bucketTree(bucket.Cursor(), bucket.Bucket, 1, maxDepth, checker)
+}
+
+func (d *Database) Backup(w io.Writer) (int64, error) {
+	return d.tx.WriteTo(w)
+}
+
+func (d *Database) CheckConsistency() ([]string, error) {
+	problems := make([]string, 0)
+	for err := range d.tx.Check() {
+		problems = append(problems, err.Error())
+	}
+	return problems, nil
+}
+
+func (d *Database) DatabaseStats() (application.DatabaseStats, error) {
+	stats := d.tx.DB().Stats()
+
+	return application.DatabaseStats{
+		Size:          d.tx.Size(),
+		FreePageN:     stats.FreePageN,
+		PendingPageN:  stats.PendingPageN,
+		FreeAlloc:     stats.FreeAlloc,
+		FreelistInuse: stats.FreelistInuse,
+		TxN:           stats.TxN,
+		OpenTxN:       stats.OpenTxN,
+		PageCount:     stats.TxStats.PageCount,
+		PageAlloc:     stats.TxStats.PageAlloc,
+	}, nil
 }
 
 func (d *Database) iterate(c *bbolt.Cursor, before, after, from *application.Key, isBucket isBucketFn) ([]application.Entry, error) {
@@ -54,19 +484,231 @@ func (d *Database) iterate(c *bbolt.Cursor, before, after, from *application.Key
 }
 
 func (d *Database) getBucket(path []application.Key) (*bbolt.Bucket, error) {
-	bucket := d.tx.Bucket(path[0].Bytes())
-	if bucket == nil {
+	bucket, info := d.resolvePath(path)
+	if !info.Resolved() {
+		if info.Segments[len(info.Segments)-1].Exists {
+			return nil, application.ErrNotABucket
+		}
 		return nil, application.ErrBucketNotFound
 	}
 
-	for i := 1; i < len(path); i++ {
-		bucket = bucket.Bucket(path[i].Bytes())
+	return bucket, nil
+}
+
+// resolvePath walks the path and returns the bucket it points to or nil if
+// the path couldn't be resolved.
+func (d *Database) resolvePath(path []application.Key) (*bbolt.Bucket, application.PathInfo) {
+	var info application.PathInfo
+	var bucket *bbolt.Bucket
+
+	for i, key := range path {
+		var child *bbolt.Bucket
+		if i == 0 {
+			child = d.tx.Bucket(key.Bytes())
+		} else {
+			child = bucket.Bucket(key.Bytes())
+		}
+
+		segment := application.PathSegment{
+			Key:    key,
+			Exists: child != nil,
+			Bucket: child != nil,
+		}
+
+		if child == nil && i > 0 {
+			segment.Exists = keyExists(bucket, key.Bytes())
+		}
+
+		info.Segments = append(info.Segments, segment)
+
+		if child == nil {
+			return nil, info
+		}
+
+		bucket = child
+	}
+
+	return bucket, info
+}
+
+// decodeEntries replaces the stored values with the decoded ones.
+func (d *Database) decodeEntries(entries []application.Entry) ([]application.Entry, error) {
+	for i, entry := range entries {
+		if entry.Bucket {
+			continue
+		}
+
+		value, err := application.NewValue(d.compression.Decode(entry.Value.Bytes()))
+		if err != nil {
+			return nil, errors.Wrap(err, "could not create a value")
+		}
+
+		entries[i].Value = value
+	}
+
+	return entries, nil
+}
+
+// rootKeyError returns an error describing why the value stored under the
+// provided key can't be accessed in the root of the database which can only
+// contain buckets.
+func (d *Database) rootKeyError(key application.Key) error {
+	if d.tx.Bucket(key.Bytes()) != nil {
+		return application.ErrNotAValue
+	}
+	return application.ErrKeyNotFound
+}
+
+func convertCreateBucketError(err error) error {
+	if errors.Is(err, bbolt.ErrBucketExists) {
+		return application.ErrBucketExists
+	}
+
+	if errors.Is(err, bbolt.ErrIncompatibleValue) {
+		return application.ErrValueExists
+	}
+
+	return errors.Wrap(err, "could not create the bucket")
+}
+
+func convertDeleteBucketError(err error) error {
+	if errors.Is(err, bbolt.ErrBucketNotFound) {
+		return application.ErrBucketNotFound
+	}
+
+	if errors.Is(err, bbolt.ErrIncompatibleValue) {
+		return application.ErrNotABucket
+	}
+
+	return errors.Wrap(err, "could not delete the bucket")
+}
+
+// copyBucket recursively copies the contents and the sequences of the source
+// bucket. Keys and values are copied as they are only valid while the source
+// bucket exists.
+func copyBucket(source, destination *bbolt.Bucket) error {
+	if err := destination.SetSequence(source.Sequence()); err != nil {
+		return errors.Wrap(err, "could not set the sequence")
+	}
+
+	return source.ForEach(func(k, v []byte) error {
+		key := append([]byte{}, k...)
+
+		if v == nil {
+			if child := source.Bucket(k); child != nil {
+				destinationChild, err := destination.CreateBucket(key)
+				if err != nil {
+					return errors.Wrap(err, "could not create a bucket")
+				}
+				return copyBucket(child, destinationChild)
+			}
+		}
+
+		if err := destination.Put(key, append([]byte{}, v...)); err != nil {
+			return errors.Wrap(err, "could not put a value")
+		}
+
+		return nil
+	})
+}
+
+func countBucketContents(bucket *bbolt.Bucket, contents *application.BucketContents, checker *contextChecker) error {
+	return bucket.ForEach(func(k, v []byte) error {
+		if err := checker.Visit(); err != nil {
+			return err
+		}
+
+		if v != nil {
+			contents.Values++
+			return nil
+		}
+
+		child := bucket.Bucket(k)
+		if child == nil {
+			contents.Values++
+			return nil
+		}
+
+		contents.Buckets++
+		return countBucketContents(child, contents, checker)
+	})
+}
+
+// bucketTree describes the buckets found using the cursor which are at the
+// provided depth.
+func bucketTree(cursor *bbolt.Cursor, getBucket func([]byte) *bbolt.Bucket, depth, maxDepth int, checker *contextChecker) ([]application.BucketTreeNode, error) {
+	nodes := make([]application.BucketTreeNode, 0)
+
+	for k, v := cursor.First(); k != nil; k, v = cursor.Next() {
+		if err := checker.Visit(); err != nil {
+			return nil, err
+		}
+
+		if v != nil {
+			continue
+		}
+
+		bucket := getBucket(k)
 		if bucket == nil {
-			return nil, application.ErrBucketNotFound
+			continue
+		}
+
+		name, err := application.NewKey(k)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not create a key")
+		}
+
+		node := application.BucketTreeNode{
+			Name: name,
+			KeyN: bucket.Stats().KeyN,
 		}
+
+		if maxDepth <= 0 || depth < maxDepth {
+			node.Buckets, err = bucketTree(bucket.Cursor(), bucket.Bucket, depth+1, maxDepth, checker)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			node.Truncated = hasBuckets(bucket)
+		}
+
+		nodes = append(nodes, node)
 	}
 
-	return bucket, nil
+	return nodes, nil
+}
+
+func hasBuckets(bucket *bbolt.Bucket) bool {
+	c := bucket.Cursor()
+	for k, v := c.First(); k != nil; k, v = c.Next() {
+		if v == nil && bucket.Bucket(k) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func toBucketStats(stats bbolt.BucketStats) application.BucketStats {
+	return application.BucketStats{
+		BranchPageN:       stats.BranchPageN,
+		BranchOverflowN:   stats.BranchOverflowN,
+		LeafPageN:         stats.LeafPageN,
+		LeafOverflowN:     stats.LeafOverflowN,
+		KeyN:              stats.KeyN,
+		Depth:             stats.Depth,
+		BranchAlloc:       stats.BranchAlloc,
+		BranchInuse:       stats.BranchInuse,
+		LeafAlloc:         stats.LeafAlloc,
+		LeafInuse:         stats.LeafInuse,
+		BucketN:           stats.BucketN,
+		InlineBucketN:     stats.InlineBucketN,
+		InlineBucketInuse: stats.InlineBucketInuse,
+	}
+}
+
+func keyExists(bucket *bbolt.Bucket, key []byte) bool {
+	k, _ := bucket.Cursor().Seek(key)
+	return bytes.Equal(k, key)
 }
 
 func iterBefore(c *bbolt.Cursor, before application.Key, isBucket isBucketFn) ([]application.Entry, error) {
@@ -153,6 +795,87 @@ func iter(c *bbolt.Cursor, isBucket isBucketFn) ([]application.Entry, error) {
 	return entries, nil
 }
 
+func listKeys(c *bbolt.Cursor, after *application.Key, limit int, sizes, descending bool, isBucket isBucketFn, compression *ValueCompression) (application.KeysPage, error) {
+	var page application.KeysPage
+
+	first, next := c.First, c.Next
+	if descending {
+		first, next = c.Last, c.Prev
+	}
+
+	key, value := first()
+	if after != nil {
+		key, value = seekAfter(c, after.Bytes(), descending)
+	}
+
+	for ; key != nil; key, value = next() {
+		if len(page.Keys) >= limit {
+			next := page.Keys[len(page.Keys)-1].Key
+			page.Next = &next
+			break
+		}
+
+		k, err := application.NewKey(key)
+		if err != nil {
+			return application.KeysPage{}, errors.Wrap(err, "could not create a key")
+		}
+
+		keyInfo := application.KeyInfo{
+			Bucket: len(value) == 0 && isBucket(key),
+			Key:    k,
+		}
+
+		if sizes && !keyInfo.Bucket {
+			size := compression.DecodedLen(value)
+			keyInfo.Size = &size
+		}
+
+		page.Keys = append(page.Keys, keyInfo)
+	}
+
+	return page, nil
+}
+
+// seekAfter moves the cursor to the key which follows the provided key in the
+// iteration order.
+func seekAfter(c *bbolt.Cursor, key []byte, descending bool) ([]byte, []byte) {
+	k, v := c.Seek(key)
+
+	if descending {
+		if k == nil {
+			return c.Last()
+		}
+		return c.Prev()
+	}
+
+	if bytes.Equal(k, key) {
+		return c.Next()
+	}
+	return k, v
+}
+
+func searchKeysByPrefix(c *bbolt.Cursor, prefix []byte, limit int, isBucket isBucketFn) ([]application.KeyInfo, error) {
+	keys := make([]application.KeyInfo, 0)
+
+	for key, value := c.Seek(prefix); key != nil && bytes.HasPrefix(key, prefix); key, value = c.Next() {
+		k, err := application.NewKey(key)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not create a key")
+		}
+
+		keys = append(keys, application.KeyInfo{
+			Bucket: len(value) == 0 && isBucket(key),
+			Key:    k,
+		})
+
+		if len(keys) >= limit {
+			break
+		}
+	}
+
+	return keys, nil
+}
+
 type isBucketFn func(k []byte) bool
 
 func isAlwaysBucket(_ []byte) bool {
@@ -0,0 +1,299 @@
+package adapters
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/boreq/errors"
+	"github.com/contentforward/bolt-ui/application"
+	bolt "go.etcd.io/bbolt"
+)
+
+// compactionTxMaxSize is the approximate number of bytes written in a single
+// transaction when copying the data into the compacted file.
+const compactionTxMaxSize = 64 * 1024 * 1024
+
+// DatabaseFile holds the open database. The database can be replaced with a
+// compacted copy of its file while the program is running. Transactions
+// started using DatabaseFile are never executed while the database is being
+// compacted.
+type DatabaseFile struct {
+	mutex sync.RWMutex
+	db    *bolt.DB
+}
+
+func NewDatabaseFile(db *bolt.DB) *DatabaseFile {
+	return &DatabaseFile{
+		db: db,
+	}
+}
+
+func (f *DatabaseFile) View(fn func(tx *bolt.Tx) error) error {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	return f.db.View(fn)
+}
+
+func (f *DatabaseFile) Update(fn func(tx *bolt.Tx) error) error {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	return f.db.Update(fn)
+}
+
+func (f *DatabaseFile) IsReadOnly() bool {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	return f.db.IsReadOnly()
+}
+
+func (f *DatabaseFile) Close() error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return f.db.Close()
+}
+
+// Inspect returns the information about the file read from the file system.
+func (f *DatabaseFile) Inspect() (application.FileInfo, error) {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	info, err := os.Stat(f.db.Path())
+	if err != nil {
+		return application.FileInfo{}, errors.Wrap(err, "could not stat the database file")
+	}
+
+	return application.FileInfo{
+		Path:     f.db.Path(),
+		Size:     info.Size(),
+		Modified: info.ModTime(),
+		ReadOnly: f.db.IsReadOnly(),
+	}, nil
+}
+
+// Compact copies the data into a new file and replaces the database file
+// with it. It waits for the running transactions to finish and blocks new
+// ones until it is done. The original file is left untouched until the copy
+// is complete and it is replaced using a rename so the data is not lost if
+// the program stops in the middle of the compaction.
+func (f *DatabaseFile) Compact() (application.CompactionResult, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.db.IsReadOnly() {
+		return application.CompactionResult{}, application.ErrReadOnly
+	}
+
+	path := f.db.Path()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return application.CompactionResult{}, errors.Wrap(err, "could not stat the database file")
+	}
+
+	tmpPath, err := f.compactInto(path, info.Mode().Perm())
+	if err != nil {
+		return application.CompactionResult{}, errors.Wrap(err, "could not compact the database")
+	}
+
+	if err := f.db.Close(); err != nil {
+		os.Remove(tmpPath)
+		return application.CompactionResult{}, errors.Wrap(err, "could not close the database")
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return application.CompactionResult{}, f.reopen(path, errors.Wrap(err, "could not replace the database file"))
+	}
+
+	if err := syncDir(filepath.Dir(path)); err != nil {
+		return application.CompactionResult{}, f.reopen(path, errors.Wrap(err, "could not sync the directory"))
+	}
+
+	if err := f.reopen(path, nil); err != nil {
+		return application.CompactionResult{}, err
+	}
+
+	compactedInfo, err := os.Stat(path)
+	if err != nil {
+		return application.CompactionResult{}, errors.Wrap(err, "could not stat the compacted database file")
+	}
+
+	return application.CompactionResult{
+		SizeBefore: info.Size(),
+		SizeAfter:  compactedInfo.Size(),
+	}, nil
+}
+
+// compactInto copies the data into a temporary file created next to the
+// database file and returns its path.
+func (f *DatabaseFile) compactInto(path string, perm os.FileMode) (string, error) {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".compact-")
+	if err != nil {
+		return "", errors.Wrap(err, "could not create a temporary file")
+	}
+	tmpPath := tmp.Name()
+
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return "", errors.Wrap(err, "could not close the temporary file")
+	}
+
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		os.Remove(tmpPath)
+		return "", errors.Wrap(err, "could not set the permissions")
+	}
+
+	dst, err := bolt.Open(tmpPath, perm, &bolt.Options{Timeout: DefaultOpenTimeout})
+	if err != nil {
+		os.Remove(tmpPath)
+		return "", errors.Wrap(err, "could not open the temporary file")
+	}
+
+	if err := f.db.View(func(tx *bolt.Tx) error {
+		return compact(dst, tx)
+	}); err != nil {
+		dst.Close()
+		os.Remove(tmpPath)
+		return "", errors.Wrap(err, "could not copy the data")
+	}
+
+	if err := dst.Close(); err != nil {
+		os.Remove(tmpPath)
+		return "", errors.Wrap(err, "could not close the temporary file")
+	}
+
+	return tmpPath, nil
+}
+
+// reopen opens the database file again after it was closed. The passed error
+// is returned if the database could be opened.
+func (f *DatabaseFile) reopen(path string, err error) error {
+	db, openErr := bolt.Open(path, 0600, &bolt.Options{Timeout: DefaultOpenTimeout})
+	if openErr != nil {
+		return errors.Wrap(openErr, "could not reopen the database")
+	}
+
+	f.db = db
+	return err
+}
+
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return errors.Wrap(err, "open failed")
+	}
+	defer d.Close()
+
+	return d.Sync()
+}
+
+func compact(dst *bolt.DB, src *bolt.Tx) error {
+	tx, err := dst.Begin(true)
+	if err != nil {
+		return errors.Wrap(err, "could not begin a transaction")
+	}
+
+	c := &compactor{
+		db: dst,
+		tx: tx,
+	}
+
+	if err := src.ForEach(func(name []byte, b *bolt.Bucket) error {
+		return c.copyBucket(nil, name, b)
+	}); err != nil {
+		c.tx.Rollback()
+		return err
+	}
+
+	return c.tx.Commit()
+}
+
+// compactor writes the copied data using multiple transactions so that the
+// entire database doesn't have to be kept in memory.
+type compactor struct {
+	db   *bolt.DB
+	tx   *bolt.Tx
+	size int64
+}
+
+func (c *compactor) copyBucket(parent [][]byte, name []byte, src *bolt.Bucket) error {
+	if err := c.reserve(len(name)); err != nil {
+		return errors.Wrap(err, "could not reserve space")
+	}
+
+	var created *bolt.Bucket
+	var err error
+
+	if len(parent) == 0 {
+		created, err = c.tx.CreateBucket(name)
+	} else {
+		created, err = c.bucket(parent).CreateBucket(name)
+	}
+	if err != nil {
+		return errors.Wrap(err, "could not create the bucket")
+	}
+
+	if err := created.SetSequence(src.Sequence()); err != nil {
+		return errors.Wrap(err, "could not set the sequence")
+	}
+
+	path := make([][]byte, len(parent), len(parent)+1)
+	copy(path, parent)
+	path = append(path, name)
+
+	return src.ForEach(func(k, v []byte) error {
+		if v == nil {
+			return c.copyBucket(path, k, src.Bucket(k))
+		}
+
+		if err := c.reserve(len(k) + len(v)); err != nil {
+			return errors.Wrap(err, "could not reserve space")
+		}
+
+		if err := c.bucket(path).Put(k, v); err != nil {
+			return errors.Wrap(err, "could not put the value")
+		}
+
+		return nil
+	})
+}
+
+// reserve commits the current transaction and begins a new one if writing n
+// more bytes would make the transaction too large.
+func (c *compactor) reserve(n int) error {
+	if c.size+int64(n) <= compactionTxMaxSize {
+		c.size += int64(n)
+		return nil
+	}
+
+	if err := c.tx.Commit(); err != nil {
+		return errors.Wrap(err, "could not commit the transaction")
+	}
+
+	tx, err := c.db.Begin(true)
+	if err != nil {
+		return errors.Wrap(err, "could not begin a transaction")
+	}
+
+	c.tx = tx
+	c.size = int64(n)
+	return nil
+}
+
+// bucket returns the bucket specified by the path in the current
+// transaction. The buckets are filled completely as the keys are inserted in
+// order.
+func (c *compactor) bucket(path [][]byte) *bolt.Bucket {
+	b := c.tx.Bucket(path[0])
+	for _, name := range path[1:] {
+		b = b.Bucket(name)
+	}
+	b.FillPercent = 1
+	return b
+}
@@ -0,0 +1,180 @@
+package adapters
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"unicode/utf8"
+
+	"github.com/boreq/errors"
+	"github.com/contentforward/bolt-ui/application"
+	"go.etcd.io/bbolt"
+)
+
+// Buckets are exported as JSON arrays of entries. Each entry contains a key
+// and either a base64 encoded value or an array of entries of a nested
+// bucket:
+//
+//	[
+//		{"key": "bucket", "keyEncoding": "utf8", "bucket": [
+//			{"key": "/wA=", "keyEncoding": "base64", "value": "dmFsdWU="}
+//		]}
+//	]
+//
+// Keys which are valid UTF-8 strings are exported as is, other keys are
+// base64 encoded.
+const (
+	keyEncodingUTF8   = "utf8"
+	keyEncodingBase64 = "base64"
+)
+
+type exportedEntry struct {
+	Key         string          `json:"key"`
+	KeyEncoding string          `json:"keyEncoding"`
+	Value       *string         `json:"value,omitempty"`
+	Bucket      []exportedEntry `json:"bucket,omitempty"`
+}
+
+func (d *Database) ExportJSON(ctx context.Context, path []application.Key, w io.Writer) error {
+	buffered := bufio.NewWriter(w)
+	checker := newContextChecker(ctx)
+
+	if len(path) == 0 {
+		if err := exportRoot(d.tx, buffered, checker, d.compression); err != nil {
+			return errors.Wrap(err, "could not export the root")
+		}
+	} else {
+		bucket, err := d.getBucket(path)
+		if err != nil {
+			return errors.Wrap(err, "could not get the bucket")
+		}
+
+		if err := exportBucket(bucket, buffered, checker, d.compression); err != nil {
+			return errors.Wrap(err, "could not export the bucket")
+		}
+	}
+
+	return buffered.Flush()
+}
+
+func exportRoot(tx *bbolt.Tx, w io.Writer, checker *contextChecker, compression *ValueCompression) error {
+	first := true
+
+	if _, err := io.WriteString(w, "["); err != nil {
+		return errors.Wrap(err, "write failed")
+	}
+
+	if err := tx.ForEach(func(name []byte, b *bbolt.Bucket) error {
+		if err := writeEntrySeparator(w, &first); err != nil {
+			return errors.Wrap(err, "could not write the separator")
+		}
+		return exportNestedBucket(name, b, w, checker, compression)
+	}); err != nil {
+		return errors.Wrap(err, "iteration failed")
+	}
+
+	if _, err := io.WriteString(w, "]"); err != nil {
+		return errors.Wrap(err, "write failed")
+	}
+
+	return nil
+}
+
+func exportBucket(bucket *bbolt.Bucket, w io.Writer, checker *contextChecker, compression *ValueCompression) error {
+	first := true
+
+	if _, err := io.WriteString(w, "["); err != nil {
+		return errors.Wrap(err, "write failed")
+	}
+
+	if err := bucket.ForEach(func(k, v []byte) error {
+		if err := checker.Visit(); err != nil {
+			return err
+		}
+
+		if err := writeEntrySeparator(w, &first); err != nil {
+			return errors.Wrap(err, "could not write the separator")
+		}
+
+		if v == nil {
+			if child := bucket.Bucket(k); child != nil {
+				return exportNestedBucket(k, child, w, checker, compression)
+			}
+		}
+
+		return exportValue(k, compression.Decode(v), w)
+	}); err != nil {
+		return errors.Wrap(err, "iteration failed")
+	}
+
+	if _, err := io.WriteString(w, "]"); err != nil {
+		return errors.Wrap(err, "write failed")
+	}
+
+	return nil
+}
+
+func exportNestedBucket(k []byte, bucket *bbolt.Bucket, w io.Writer, checker *contextChecker, compression *ValueCompression) error {
+	key, keyEncoding := encodeKey(k)
+
+	keyJSON, err := json.Marshal(key)
+	if err != nil {
+		return errors.Wrap(err, "could not marshal the key")
+	}
+
+	if _, err := fmt.Fprintf(w, `{"key":%s,"keyEncoding":"%s","bucket":`, keyJSON, keyEncoding); err != nil {
+		return errors.Wrap(err, "write failed")
+	}
+
+	if err := exportBucket(bucket, w, checker, compression); err != nil {
+		return errors.Wrap(err, "could not export the nested bucket")
+	}
+
+	if _, err := io.WriteString(w, "}"); err != nil {
+		return errors.Wrap(err, "write failed")
+	}
+
+	return nil
+}
+
+func exportValue(k, v []byte, w io.Writer) error {
+	key, keyEncoding := encodeKey(k)
+	value := base64.StdEncoding.EncodeToString(v)
+
+	entry := exportedEntry{
+		Key:         key,
+		KeyEncoding: keyEncoding,
+		Value:       &value,
+	}
+
+	j, err := json.Marshal(entry)
+	if err != nil {
+		return errors.Wrap(err, "could not marshal the entry")
+	}
+
+	if _, err := w.Write(j); err != nil {
+		return errors.Wrap(err, "write failed")
+	}
+
+	return nil
+}
+
+func writeEntrySeparator(w io.Writer, first *bool) error {
+	if *first {
+		*first = false
+		return nil
+	}
+
+	_, err := io.WriteString(w, ",")
+	return err
+}
+
+func encodeKey(k []byte) (string, string) {
+	if utf8.Valid(k) {
+		return string(k), keyEncodingUTF8
+	}
+	return base64.StdEncoding.EncodeToString(k), keyEncodingBase64
+}
@@ -0,0 +1,117 @@
+package adapters
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/csv"
+	"io"
+	"unicode/utf8"
+
+	"github.com/boreq/errors"
+	"github.com/contentforward/bolt-ui/application"
+)
+
+// Buckets are exported as CSV files with a header and two columns. Keys and
+// values are written as is if all of them are valid UTF-8 strings. Otherwise
+// the entire column is base64 encoded which is indicated by the header:
+//
+//	key,value_base64
+//	first,dmFsdWU=
+//	second,/wA=
+const (
+	csvColumnKey   = "key"
+	csvColumnValue = "value"
+
+	csvBase64Suffix = "_base64"
+)
+
+func (d *Database) ExportCSV(ctx context.Context, path []application.Key, w io.Writer) error {
+	if len(path) == 0 {
+		// The root can only contain buckets.
+		if k, _ := d.tx.Cursor().First(); k != nil {
+			return application.ErrContainsBuckets
+		}
+		return writeCSV(w, forEachNothing, newContextChecker(ctx), true, true)
+	}
+
+	bucket, err := d.getBucket(path)
+	if err != nil {
+		return errors.Wrap(err, "could not get the bucket")
+	}
+
+	// The first pass makes sure that no errors are returned after the
+	// header was written.
+	keysValid := true
+	valuesValid := true
+	checker := newContextChecker(ctx)
+	forEach := d.compression.forEach(bucket.ForEach)
+
+	if err := forEach(func(k, v []byte) error {
+		if err := checker.Visit(); err != nil {
+			return err
+		}
+		if v == nil {
+			return application.ErrContainsBuckets
+		}
+		keysValid = keysValid && utf8.Valid(k)
+		valuesValid = valuesValid && utf8.Valid(v)
+		return nil
+	}); err != nil {
+		return errors.Wrap(err, "could not check the bucket")
+	}
+
+	return writeCSV(w, forEach, checker, keysValid, valuesValid)
+}
+
+func writeCSV(w io.Writer, forEach func(func(k, v []byte) error) error, checker *contextChecker, keysValid, valuesValid bool) error {
+	buffered := bufio.NewWriter(w)
+	writer := csv.NewWriter(buffered)
+
+	header := []string{
+		csvColumnName(csvColumnKey, keysValid),
+		csvColumnName(csvColumnValue, valuesValid),
+	}
+
+	if err := writer.Write(header); err != nil {
+		return errors.Wrap(err, "could not write the header")
+	}
+
+	if err := forEach(func(k, v []byte) error {
+		if err := checker.Visit(); err != nil {
+			return err
+		}
+		record := []string{
+			csvEncode(k, keysValid),
+			csvEncode(v, valuesValid),
+		}
+		return writer.Write(record)
+	}); err != nil {
+		return errors.Wrap(err, "could not write the records")
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return errors.Wrap(err, "could not flush the csv writer")
+	}
+
+	return buffered.Flush()
+}
+
+func csvColumnName(name string, valid bool) string {
+	if valid {
+		return name
+	}
+	return name + csvBase64Suffix
+}
+
+func csvEncode(b []byte, valid bool) string {
+	if valid {
+		return string(b)
+	}
+	return base64.StdEncoding.EncodeToString(b)
+}
+
+func forEachNothing(fn func(k, v []byte) error) error {
+	return nil
+}
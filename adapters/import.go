@@ -0,0 +1,362 @@
+package adapters
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/boreq/errors"
+	"github.com/contentforward/bolt-ui/application"
+	"go.etcd.io/bbolt"
+)
+
+// ImportDecoder decodes the imported data in all supported formats.
+type ImportDecoder struct {
+}
+
+func NewImportDecoder() *ImportDecoder {
+	return &ImportDecoder{}
+}
+
+func (d *ImportDecoder) Decode(r io.Reader, format application.ImportFormat) ([]application.ImportedEntry, error) {
+	var entries []application.ImportedEntry
+	var err error
+
+	switch format {
+	case application.ImportFormatCSV:
+		entries, err = decodeCSVImport(r)
+	default:
+		entries, err = decodeImport(r)
+	}
+	if err != nil {
+		return nil, invalidImportError{err}
+	}
+
+	return entries, nil
+}
+
+// Import validates the entries against the current contents of the database
+// and then writes them unless this is a dry run. Both dry runs and real
+// imports use the same code path so that the summary of a dry run accurately
+// describes the effects of the real import.
+func (d *Database) Import(path []application.Key, entries []application.ImportedEntry, mode application.ImportMode, dryRun bool) (application.ImportSummary, error) {
+	var bucket *bbolt.Bucket
+
+	if len(path) > 0 {
+		b, err := d.getBucket(path)
+		if err != nil {
+			return application.ImportSummary{}, errors.Wrap(err, "could not get the bucket")
+		}
+		bucket = b
+	}
+
+	p := &importPlanner{}
+	if len(path) == 0 {
+		p.planRoot(d.tx, entries, mode)
+	} else {
+		if mode == application.ImportModeReplace {
+			// The contents of the bucket will be removed so nothing is
+			// overwritten.
+			bucket = nil
+		}
+		p.planEntries(bucket, nil, entries)
+	}
+
+	summary := p.Summary()
+
+	if dryRun {
+		return summary, nil
+	}
+
+	if len(p.errs) > 0 {
+		return application.ImportSummary{}, p.errs[0]
+	}
+
+	if len(path) == 0 {
+		if err := d.importRoot(entries, mode); err != nil {
+			return application.ImportSummary{}, errors.Wrap(err, "could not import the root")
+		}
+		return summary, nil
+	}
+
+	if mode == application.ImportModeReplace {
+		if err := d.DeleteBucket(path); err != nil {
+			return application.ImportSummary{}, errors.Wrap(err, "could not delete the bucket")
+		}
+
+		if err := d.CreateBucket(path[:len(path)-1], path[len(path)-1]); err != nil {
+			return application.ImportSummary{}, errors.Wrap(err, "could not recreate the bucket")
+		}
+	}
+
+	bucket, err := d.getBucket(path)
+	if err != nil {
+		return application.ImportSummary{}, errors.Wrap(err, "could not get the bucket")
+	}
+
+	if err := writeImportedEntries(bucket, entries, d.compression); err != nil {
+		return application.ImportSummary{}, errors.Wrap(err, "could not import the bucket")
+	}
+
+	return summary, nil
+}
+
+func (d *Database) importRoot(entries []application.ImportedEntry, mode application.ImportMode) error {
+	if mode == application.ImportModeReplace {
+		var names [][]byte
+
+		if err := d.tx.ForEach(func(name []byte, _ *bbolt.Bucket) error {
+			names = append(names, name)
+			return nil
+		}); err != nil {
+			return errors.Wrap(err, "iteration failed")
+		}
+
+		for _, name := range names {
+			if err := d.tx.DeleteBucket(name); err != nil {
+				return errors.Wrap(err, "could not delete a bucket")
+			}
+		}
+	}
+
+	for _, entry := range entries {
+		bucket, err := d.tx.CreateBucketIfNotExists(entry.Key)
+		if err != nil {
+			return errors.Wrap(err, "could not create a bucket")
+		}
+
+		if err := writeImportedEntries(bucket, entry.Bucket, d.compression); err != nil {
+			return errors.Wrap(err, "could not import a bucket")
+		}
+	}
+
+	return nil
+}
+
+func writeImportedEntries(bucket *bbolt.Bucket, entries []application.ImportedEntry, compression *ValueCompression) error {
+	for _, entry := range entries {
+		if entry.IsBucket() {
+			child, err := bucket.CreateBucketIfNotExists(entry.Key)
+			if err != nil {
+				if errors.Is(err, bbolt.ErrIncompatibleValue) {
+					return application.ErrValueExists
+				}
+				return errors.Wrap(err, "could not create a bucket")
+			}
+
+			if err := writeImportedEntries(child, entry.Bucket, compression); err != nil {
+				return errors.Wrap(err, "could not import a bucket")
+			}
+
+			continue
+		}
+
+		value, err := compression.Encode(entry.Value)
+		if err != nil {
+			return errors.Wrap(err, "could not encode a value")
+		}
+
+		if err := bucket.Put(entry.Key, value); err != nil {
+			if errors.Is(err, bbolt.ErrIncompatibleValue) {
+				return application.ErrNotAValue
+			}
+			return errors.Wrap(err, "could not put a value")
+		}
+	}
+
+	return nil
+}
+
+// invalidImportError marks errors caused by the imported data while keeping
+// the underlying error.
+type invalidImportError struct {
+	err error
+}
+
+func (e invalidImportError) Error() string {
+	return e.err.Error()
+}
+
+func (e invalidImportError) Unwrap() error {
+	return e.err
+}
+
+func (e invalidImportError) Is(target error) bool {
+	return target == application.ErrInvalidImport
+}
+
+// importPlanner compares the imported entries with the existing contents of
+// the database without modifying it.
+type importPlanner struct {
+	created     int
+	overwritten int
+	errs        []error
+}
+
+func (p *importPlanner) planRoot(tx *bbolt.Tx, entries []application.ImportedEntry, mode application.ImportMode) {
+	for _, entry := range entries {
+		path := [][]byte{entry.Key}
+
+		if !entry.IsBucket() {
+			p.errs = append(p.errs, invalidImportError{fmt.Errorf("value '%s' can not be stored in the root of the database", formatImportPath(path))})
+			continue
+		}
+
+		var existing *bbolt.Bucket
+		if mode != application.ImportModeReplace {
+			existing = tx.Bucket(entry.Key)
+		}
+
+		if existing == nil {
+			p.created++
+		}
+
+		p.planEntries(existing, path, entry.Bucket)
+	}
+}
+
+// planEntries plans importing the entries into the bucket, nil bucket means
+// that the bucket doesn't exist yet.
+func (p *importPlanner) planEntries(bucket *bbolt.Bucket, parent [][]byte, entries []application.ImportedEntry) {
+	for _, entry := range entries {
+		path := append(append([][]byte{}, parent...), entry.Key)
+
+		exists, isBucket := false, false
+		if bucket != nil {
+			exists, isBucket = lookupKey(bucket, entry.Key)
+		}
+
+		if entry.IsBucket() {
+			if exists && !isBucket {
+				p.errs = append(p.errs, errors.Wrapf(application.ErrValueExists, "bucket '%s' would overwrite a value", formatImportPath(path)))
+				continue
+			}
+
+			var existing *bbolt.Bucket
+			if exists {
+				existing = bucket.Bucket(entry.Key)
+			} else {
+				p.created++
+			}
+
+			p.planEntries(existing, path, entry.Bucket)
+			continue
+		}
+
+		switch {
+		case exists && isBucket:
+			p.errs = append(p.errs, errors.Wrapf(application.ErrNotAValue, "value '%s' would overwrite a bucket", formatImportPath(path)))
+		case exists:
+			p.overwritten++
+		default:
+			p.created++
+		}
+	}
+}
+
+func (p *importPlanner) Summary() application.ImportSummary {
+	summary := application.ImportSummary{
+		Created:     p.created,
+		Overwritten: p.overwritten,
+	}
+
+	for _, err := range p.errs {
+		summary.Errors = append(summary.Errors, err.Error())
+	}
+
+	return summary
+}
+
+func lookupKey(bucket *bbolt.Bucket, key []byte) (exists bool, isBucket bool) {
+	k, v := bucket.Cursor().Seek(key)
+	if !bytes.Equal(k, key) {
+		return false, false
+	}
+	return true, v == nil
+}
+
+func formatImportPath(path [][]byte) string {
+	var elements []string
+	for _, element := range path {
+		key, _ := encodeKey(element)
+		elements = append(elements, key)
+	}
+	return strings.Join(elements, "/")
+}
+
+func decodeImport(r io.Reader) ([]application.ImportedEntry, error) {
+	var exported []exportedEntry
+
+	decoder := json.NewDecoder(r)
+	if err := decoder.Decode(&exported); err != nil {
+		return nil, errors.Wrap(err, "json decoding failed")
+	}
+
+	return toImportedEntries(exported)
+}
+
+func toImportedEntries(exported []exportedEntry) ([]application.ImportedEntry, error) {
+	entries := make([]application.ImportedEntry, 0)
+
+	for _, e := range exported {
+		entry, err := toImportedEntry(e)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid entry '%s'", e.Key)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+func toImportedEntry(e exportedEntry) (application.ImportedEntry, error) {
+	key, err := decodeKey(e.Key, e.KeyEncoding)
+	if err != nil {
+		return application.ImportedEntry{}, errors.Wrap(err, "could not decode the key")
+	}
+
+	if len(key) == 0 {
+		return application.ImportedEntry{}, errors.New("key can not be empty")
+	}
+
+	if (e.Value == nil) == (e.Bucket == nil) {
+		return application.ImportedEntry{}, errors.New("entry must contain either a value or a bucket")
+	}
+
+	if e.Bucket != nil {
+		children, err := toImportedEntries(e.Bucket)
+		if err != nil {
+			return application.ImportedEntry{}, errors.Wrap(err, "invalid bucket")
+		}
+
+		return application.ImportedEntry{
+			Key:    key,
+			Bucket: children,
+		}, nil
+	}
+
+	value, err := base64.StdEncoding.DecodeString(*e.Value)
+	if err != nil {
+		return application.ImportedEntry{}, errors.Wrap(err, "could not decode the value")
+	}
+
+	return application.ImportedEntry{
+		Key:   key,
+		Value: value,
+	}, nil
+}
+
+func decodeKey(key string, encoding string) ([]byte, error) {
+	switch encoding {
+	case keyEncodingUTF8:
+		return []byte(key), nil
+	case keyEncodingBase64:
+		return base64.StdEncoding.DecodeString(key)
+	default:
+		return nil, errors.New("unknown key encoding")
+	}
+}
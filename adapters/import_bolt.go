@@ -0,0 +1,258 @@
+package adapters
+
+import (
+	"github.com/boreq/errors"
+	"github.com/contentforward/bolt-ui/application"
+	bolt "go.etcd.io/bbolt"
+)
+
+// ImportBoltFile copies the contents of the file into the database using the
+// same batching as the compaction. The compaction can't run during the
+// import but the other transactions can run between the batches.
+func (f *DatabaseFile) ImportBoltFile(sourcePath string, path []application.Key, mode application.ImportMode) error {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	if f.db.IsReadOnly() {
+		return application.ErrReadOnly
+	}
+
+	src, err := NewBolt(sourcePath, true, DefaultOpenTimeout)
+	if err != nil {
+		return errors.Wrap(invalidImportError{err}, "could not open the file")
+	}
+	defer src.Close()
+
+	return src.View(func(srcTx *bolt.Tx) error {
+		return importBoltFile(f.db, srcTx, path, mode)
+	})
+}
+
+func importBoltFile(dst *bolt.DB, src *bolt.Tx, path []application.Key, mode application.ImportMode) error {
+	tx, err := dst.Begin(true)
+	if err != nil {
+		return errors.Wrap(err, "could not begin a transaction")
+	}
+
+	i := &boltFileImporter{
+		compactor: compactor{
+			db: dst,
+			tx: tx,
+		},
+		path: path,
+	}
+
+	// The destination is checked and cleared in the first transaction so
+	// nothing is written if the import conflicts with the existing data.
+	if err := i.prepare(src, mode); err != nil {
+		i.tx.Rollback()
+		return err
+	}
+
+	if err := src.ForEach(func(name []byte, b *bolt.Bucket) error {
+		return i.copyBucket(nil, name, b)
+	}); err != nil {
+		i.tx.Rollback()
+		return err
+	}
+
+	return i.tx.Commit()
+}
+
+// boltFileImporter merges the copied data with the existing buckets. Paths
+// passed to its methods are relative to the destination bucket.
+type boltFileImporter struct {
+	compactor
+	path []application.Key
+}
+
+func (i *boltFileImporter) prepare(src *bolt.Tx, mode application.ImportMode) error {
+	var destination *bolt.Bucket
+
+	if len(i.path) > 0 {
+		b, err := NewDatabase(i.tx, nil).getBucket(i.path)
+		if err != nil {
+			return errors.Wrap(err, "could not get the bucket")
+		}
+		destination = b
+	}
+
+	if mode == application.ImportModeReplace {
+		return i.clear()
+	}
+
+	return src.ForEach(func(name []byte, b *bolt.Bucket) error {
+		path := [][]byte{name}
+
+		if destination == nil {
+			if existing := i.tx.Bucket(name); existing != nil {
+				return checkBoltImport(existing, b, path)
+			}
+			return nil
+		}
+
+		exists, isBucket := lookupKey(destination, name)
+		if !exists {
+			return nil
+		}
+
+		if !isBucket {
+			return errors.Wrapf(application.ErrValueExists, "bucket '%s' would overwrite a value", formatImportPath(path))
+		}
+
+		return checkBoltImport(destination.Bucket(name), b, path)
+	})
+}
+
+// clear removes the contents of the destination.
+func (i *boltFileImporter) clear() error {
+	if len(i.path) == 0 {
+		var names [][]byte
+
+		if err := i.tx.ForEach(func(name []byte, _ *bolt.Bucket) error {
+			names = append(names, name)
+			return nil
+		}); err != nil {
+			return errors.Wrap(err, "iteration failed")
+		}
+
+		for _, name := range names {
+			if err := i.tx.DeleteBucket(name); err != nil {
+				return errors.Wrap(err, "could not delete a bucket")
+			}
+		}
+
+		return nil
+	}
+
+	database := NewDatabase(i.tx, nil)
+
+	if err := database.DeleteBucket(i.path); err != nil {
+		return errors.Wrap(err, "could not delete the bucket")
+	}
+
+	if err := database.CreateBucket(i.path[:len(i.path)-1], i.path[len(i.path)-1]); err != nil {
+		return errors.Wrap(err, "could not recreate the bucket")
+	}
+
+	return nil
+}
+
+// checkBoltImport returns an error if merging the source bucket into the
+// existing bucket would overwrite a value with a bucket or the other way
+// around.
+func checkBoltImport(existing, src *bolt.Bucket, path [][]byte) error {
+	return src.ForEach(func(k, v []byte) error {
+		exists, isBucket := lookupKey(existing, k)
+		if !exists {
+			return nil
+		}
+
+		childPath := append(append([][]byte{}, path...), k)
+
+		if child := src.Bucket(k); v == nil && child != nil {
+			if !isBucket {
+				return errors.Wrapf(application.ErrValueExists, "bucket '%s' would overwrite a value", formatImportPath(childPath))
+			}
+			return checkBoltImport(existing.Bucket(k), child, childPath)
+		}
+
+		if isBucket {
+			return errors.Wrapf(application.ErrNotAValue, "value '%s' would overwrite a bucket", formatImportPath(childPath))
+		}
+
+		return nil
+	})
+}
+
+func (i *boltFileImporter) copyBucket(parent [][]byte, name []byte, src *bolt.Bucket) error {
+	if err := i.reserve(len(name)); err != nil {
+		return errors.Wrap(err, "could not reserve space")
+	}
+
+	b, err := i.bucket(parent)
+	if err != nil {
+		return errors.Wrap(err, "could not get the parent bucket")
+	}
+
+	var created *bolt.Bucket
+	if b != nil {
+		created, err = b.CreateBucketIfNotExists(name)
+	} else {
+		created, err = i.tx.CreateBucketIfNotExists(name)
+	}
+	if err != nil {
+		if errors.Is(err, bolt.ErrIncompatibleValue) {
+			return application.ErrValueExists
+		}
+		return errors.Wrap(err, "could not create the bucket")
+	}
+
+	// The sequence is never decreased so that the keys which were already
+	// generated in the existing bucket aren't generated again.
+	if src.Sequence() > created.Sequence() {
+		if err := created.SetSequence(src.Sequence()); err != nil {
+			return errors.Wrap(err, "could not set the sequence")
+		}
+	}
+
+	path := make([][]byte, len(parent), len(parent)+1)
+	copy(path, parent)
+	path = append(path, name)
+
+	return src.ForEach(func(k, v []byte) error {
+		if v == nil {
+			if child := src.Bucket(k); child != nil {
+				return i.copyBucket(path, k, child)
+			}
+		}
+
+		if err := i.reserve(len(k) + len(v)); err != nil {
+			return errors.Wrap(err, "could not reserve space")
+		}
+
+		b, err := i.bucket(path)
+		if err != nil {
+			return errors.Wrap(err, "could not get the bucket")
+		}
+
+		if err := b.Put(k, v); err != nil {
+			if errors.Is(err, bolt.ErrIncompatibleValue) {
+				return application.ErrNotAValue
+			}
+			return errors.Wrap(err, "could not put a value")
+		}
+
+		return nil
+	})
+}
+
+// bucket returns the bucket specified by the path in the current
+// transaction, nil is returned for the root of the database. The buckets have
+// to be looked up again after each call to reserve as it can replace the
+// transaction. Returns ErrBucketNotFound if the bucket was removed by another
+// transaction in the meantime.
+func (i *boltFileImporter) bucket(path [][]byte) (*bolt.Bucket, error) {
+	var elements [][]byte
+	for _, key := range i.path {
+		elements = append(elements, key.Bytes())
+	}
+	elements = append(elements, path...)
+
+	if len(elements) == 0 {
+		return nil, nil
+	}
+
+	b := i.tx.Bucket(elements[0])
+	for _, element := range elements[1:] {
+		if b == nil {
+			break
+		}
+		b = b.Bucket(element)
+	}
+
+	if b == nil {
+		return nil, application.ErrBucketNotFound
+	}
+	return b, nil
+}
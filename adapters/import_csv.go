@@ -0,0 +1,83 @@
+package adapters
+
+import (
+	"encoding/base64"
+	"encoding/csv"
+	"fmt"
+	"io"
+
+	"github.com/boreq/errors"
+	"github.com/contentforward/bolt-ui/application"
+)
+
+// decodeCSVImport decodes CSV in the format produced by ExportCSV.
+func decodeCSVImport(r io.Reader) ([]application.ImportedEntry, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 2
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read the header")
+	}
+
+	keysEncoded, err := readCSVColumnName(header[0], csvColumnKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid key column")
+	}
+
+	valuesEncoded, err := readCSVColumnName(header[1], csvColumnValue)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid value column")
+	}
+
+	entries := make([]application.ImportedEntry, 0)
+
+	for n := 1; ; n++ {
+		record, err := reader.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return entries, nil
+			}
+			return nil, errors.Wrap(err, "could not read a record")
+		}
+
+		key, err := csvDecode(record[0], keysEncoded)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not decode the key of record %d", n)
+		}
+
+		if len(key) == 0 {
+			return nil, fmt.Errorf("key of record %d can not be empty", n)
+		}
+
+		value, err := csvDecode(record[1], valuesEncoded)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not decode the value of record %d", n)
+		}
+
+		entries = append(entries, application.ImportedEntry{
+			Key:   key,
+			Value: value,
+		})
+	}
+}
+
+// readCSVColumnName returns true if the column is base64 encoded.
+func readCSVColumnName(s string, name string) (bool, error) {
+	switch s {
+	case name:
+		return false, nil
+	case name + csvBase64Suffix:
+		return true, nil
+	default:
+		return false, errors.New("unknown column name")
+	}
+}
+
+func csvDecode(s string, encoded bool) ([]byte, error) {
+	if encoded {
+		return base64.StdEncoding.DecodeString(s)
+	}
+	return []byte(s), nil
+}
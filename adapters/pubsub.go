@@ -0,0 +1,77 @@
+package adapters
+
+import (
+	"bytes"
+	"sync"
+
+	"github.com/contentforward/bolt-ui/application"
+)
+
+// subscriptionBufferSize is the number of changes which can be queued for a
+// subscriber before the following changes are dropped.
+const subscriptionBufferSize = 100
+
+// PubSub distributes the changes to the subscribers in process.
+type PubSub struct {
+	mutex         sync.Mutex
+	subscriptions map[*subscription]struct{}
+}
+
+type subscription struct {
+	path []application.Key
+	c    chan application.Change
+}
+
+func NewPubSub() *PubSub {
+	return &PubSub{
+		subscriptions: make(map[*subscription]struct{}),
+	}
+}
+
+func (p *PubSub) Publish(change application.Change) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for s := range p.subscriptions {
+		if !pathsRelated(s.path, change.Path) {
+			continue
+		}
+
+		select {
+		case s.c <- change:
+		default:
+		}
+	}
+}
+
+func (p *PubSub) Subscribe(path []application.Key) (<-chan application.Change, func()) {
+	s := &subscription{
+		path: path,
+		c:    make(chan application.Change, subscriptionBufferSize),
+	}
+
+	p.mutex.Lock()
+	p.subscriptions[s] = struct{}{}
+	p.mutex.Unlock()
+
+	var once sync.Once
+
+	return s.c, func() {
+		once.Do(func() {
+			p.mutex.Lock()
+			delete(p.subscriptions, s)
+			p.mutex.Unlock()
+			close(s.c)
+		})
+	}
+}
+
+// pathsRelated returns true if one of the paths is a prefix of the other one.
+func pathsRelated(a, b []application.Key) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if !bytes.Equal(a[i].Bytes(), b[i].Bytes()) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,180 @@
+package adapters
+
+import (
+	"context"
+
+	"github.com/boreq/errors"
+	"github.com/contentforward/bolt-ui/application"
+	"go.etcd.io/bbolt"
+)
+
+var errSearchLimitReached = errors.New("search limit reached")
+
+func (d *Database) SearchAllBuckets(ctx context.Context, matcher application.KeyMatcher, limit int) ([]application.SearchHit, error) {
+	s := &bucketSearch{
+		checker: newContextChecker(ctx),
+		matcher: matcher,
+		limit:   limit,
+	}
+
+	c := d.tx.Cursor()
+	for k, _ := c.First(); k != nil; k, _ = c.Next() {
+		if err := s.visit(nil, k, d.tx.Bucket(k)); err != nil {
+			if errors.Is(err, errSearchLimitReached) {
+				break
+			}
+			return nil, errors.Wrap(err, "search failed")
+		}
+	}
+
+	return s.hits, nil
+}
+
+type bucketSearch struct {
+	checker *contextChecker
+	matcher application.KeyMatcher
+	limit   int
+
+	hits []application.SearchHit
+}
+
+// visit checks the key stored in the bucket specified by the path and
+// searches its contents if it is a bucket, nil bucket means that the key
+// points to a value.
+func (s *bucketSearch) visit(path []application.Key, k []byte, bucket *bbolt.Bucket) error {
+	if err := s.checker.Visit(); err != nil {
+		return err
+	}
+
+	key, err := application.NewKey(k)
+	if err != nil {
+		return errors.Wrap(err, "could not create a key")
+	}
+
+	if s.matcher(k) {
+		s.hits = append(s.hits, application.SearchHit{
+			Path:   path,
+			Bucket: bucket != nil,
+			Key:    key,
+		})
+
+		if len(s.hits) >= s.limit {
+			return errSearchLimitReached
+		}
+	}
+
+	if bucket == nil {
+		return nil
+	}
+
+	childPath := make([]application.Key, len(path)+1)
+	copy(childPath, path)
+	childPath[len(path)] = key
+
+	c := bucket.Cursor()
+	for k, v := c.First(); k != nil; k, v = c.Next() {
+		var child *bbolt.Bucket
+		if v == nil {
+			child = bucket.Bucket(k)
+		}
+
+		if err := s.visit(childPath, k, child); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (d *Database) SearchValues(ctx context.Context, path []application.Key, matcher application.ValueMatcher, limit int, w application.SearchHitWriter) error {
+	s := &valueSearch{
+		ctx:         ctx,
+		matcher:     matcher,
+		limit:       limit,
+		w:           w,
+		compression: d.compression,
+	}
+
+	var err error
+
+	if len(path) == 0 {
+		err = d.tx.ForEach(func(name []byte, bucket *bbolt.Bucket) error {
+			key, err := application.NewKey(name)
+			if err != nil {
+				return errors.Wrap(err, "could not create a key")
+			}
+			return s.searchBucket([]application.Key{key}, bucket)
+		})
+	} else {
+		bucket, getErr := d.getBucket(path)
+		if getErr != nil {
+			return errors.Wrap(getErr, "could not get the bucket")
+		}
+		err = s.searchBucket(path, bucket)
+	}
+
+	if err != nil && !errors.Is(err, errSearchLimitReached) {
+		return errors.Wrap(err, "search failed")
+	}
+
+	return nil
+}
+
+type valueSearch struct {
+	ctx         context.Context
+	matcher     application.ValueMatcher
+	limit       int
+	w           application.SearchHitWriter
+	compression *ValueCompression
+
+	hits int
+}
+
+func (s *valueSearch) searchBucket(path []application.Key, bucket *bbolt.Bucket) error {
+	c := bucket.Cursor()
+	for k, v := c.First(); k != nil; k, v = c.Next() {
+		// Matching values can be expensive so the context is checked
+		// before each of them.
+		if err := s.ctx.Err(); err != nil {
+			return err
+		}
+
+		key, err := application.NewKey(k)
+		if err != nil {
+			return errors.Wrap(err, "could not create a key")
+		}
+
+		if v == nil {
+			if child := bucket.Bucket(k); child != nil {
+				childPath := make([]application.Key, len(path)+1)
+				copy(childPath, path)
+				childPath[len(path)] = key
+
+				if err := s.searchBucket(childPath, child); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+
+		snippet, ok := s.matcher(s.compression.Decode(v))
+		if !ok {
+			continue
+		}
+
+		if err := s.w(application.SearchHit{
+			Path:    path,
+			Key:     key,
+			Snippet: snippet,
+		}); err != nil {
+			return errors.Wrap(err, "writer failed")
+		}
+
+		s.hits++
+		if s.hits >= s.limit {
+			return errSearchLimitReached
+		}
+	}
+
+	return nil
+}
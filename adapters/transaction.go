@@ -11,12 +11,12 @@ type AdaptersProvider interface {
 }
 
 type TransactionProvider struct {
-	db       *bolt.DB
+	db       *DatabaseFile
 	provider AdaptersProvider
 }
 
 func NewTransactionProvider(
-	db *bolt.DB,
+	db *DatabaseFile,
 	provider AdaptersProvider,
 ) *TransactionProvider {
 	return &TransactionProvider{
@@ -36,6 +36,10 @@ func (p *TransactionProvider) Read(handler application.TransactionHandler) error
 }
 
 func (p *TransactionProvider) Write(handler application.TransactionHandler) error {
+	if p.db.IsReadOnly() {
+		return application.ErrReadOnly
+	}
+
 	return p.db.Update(func(tx *bolt.Tx) error {
 		adapters, err := p.provider.Provide(tx)
 		if err != nil {
@@ -44,3 +48,69 @@ func (p *TransactionProvider) Write(handler application.TransactionHandler) erro
 		return handler(adapters)
 	})
 }
+
+// ReadOnlyTransactionProvider refuses to execute write transactions making it
+// impossible for the application to mutate the database.
+type ReadOnlyTransactionProvider struct {
+	provider application.TransactionProvider
+}
+
+func NewReadOnlyTransactionProvider(provider application.TransactionProvider) *ReadOnlyTransactionProvider {
+	return &ReadOnlyTransactionProvider{
+		provider: provider,
+	}
+}
+
+func (p *ReadOnlyTransactionProvider) Read(handler application.TransactionHandler) error {
+	return p.provider.Read(handler)
+}
+
+func (p *ReadOnlyTransactionProvider) Write(handler application.TransactionHandler) error {
+	return application.ErrReadOnly
+}
+
+// ReadOnlyCompactor refuses to compact the database.
+type ReadOnlyCompactor struct {
+}
+
+func NewReadOnlyCompactor() *ReadOnlyCompactor {
+	return &ReadOnlyCompactor{}
+}
+
+func (c *ReadOnlyCompactor) Compact() (application.CompactionResult, error) {
+	return application.CompactionResult{}, application.ErrReadOnly
+}
+
+// ReadOnlyBoltFileImporter refuses to import files into the database.
+type ReadOnlyBoltFileImporter struct {
+}
+
+func NewReadOnlyBoltFileImporter() *ReadOnlyBoltFileImporter {
+	return &ReadOnlyBoltFileImporter{}
+}
+
+func (i *ReadOnlyBoltFileImporter) ImportBoltFile(sourcePath string, path []application.Key, mode application.ImportMode) error {
+	return application.ErrReadOnly
+}
+
+// ReadOnlyFileInspector reports that the database is read-only even if the
+// file wasn't opened in the read-only mode.
+type ReadOnlyFileInspector struct {
+	inspector application.FileInspector
+}
+
+func NewReadOnlyFileInspector(inspector application.FileInspector) *ReadOnlyFileInspector {
+	return &ReadOnlyFileInspector{
+		inspector: inspector,
+	}
+}
+
+func (i *ReadOnlyFileInspector) Inspect() (application.FileInfo, error) {
+	info, err := i.inspector.Inspect()
+	if err != nil {
+		return application.FileInfo{}, errors.Wrap(err, "could not inspect the file")
+	}
+
+	info.ReadOnly = true
+	return info, nil
+}
@@ -0,0 +1,192 @@
+package adapters
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"math"
+
+	"github.com/boreq/errors"
+)
+
+// Compressed values are stored in the following frame:
+//
+//	magic (4 bytes) | uncompressed length (8 bytes, big endian) | gzip stream
+//
+// A stored value is only decompressed if it starts with the magic bytes and
+// contains a single gzip stream which ends together with the value and
+// decompresses to exactly the declared number of bytes. Other values are
+// returned as they are stored.
+var compressedValueMagic = []byte{0x00, 'b', 'u', 'z'}
+
+const compressedValueHeaderLen = 4 + 8
+
+// ValueCompression transparently compresses the values stored using this
+// program. Values shorter than the threshold are stored as they are unless
+// they could be confused with a compressed value in which case they are
+// always compressed so that reading them returns exactly what was written.
+// Compression is disabled if the threshold is zero in which case the values
+// are neither compressed nor decompressed.
+type ValueCompression struct {
+	threshold int
+}
+
+func NewValueCompression(threshold int) *ValueCompression {
+	return &ValueCompression{
+		threshold: threshold,
+	}
+}
+
+func (c *ValueCompression) enabled() bool {
+	return c != nil && c.threshold > 0
+}
+
+// Encode returns the representation of the value which should be stored in
+// the database.
+func (c *ValueCompression) Encode(value []byte) ([]byte, error) {
+	if !c.enabled() {
+		return value, nil
+	}
+
+	ambiguous := isCompressedValue(value)
+	if len(value) < c.threshold && !ambiguous {
+		return value, nil
+	}
+
+	compressed, err := compressValue(value)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not compress the value")
+	}
+
+	// Incompressible values are stored as they are unless they would be
+	// decompressed when read.
+	if len(compressed) >= len(value) && !ambiguous {
+		return value, nil
+	}
+
+	return compressed, nil
+}
+
+// Decode returns the value which was passed to Encode given the stored
+// representation. Values which weren't compressed are returned unchanged.
+func (c *ValueCompression) Decode(stored []byte) []byte {
+	if !c.enabled() {
+		return stored
+	}
+
+	value, ok := decompressValue(stored)
+	if !ok {
+		return stored
+	}
+	return value
+}
+
+// DecodedLen returns the length of the value which Decode would return given
+// the stored representation. The length is read from the frame header
+// without decompressing the value so unlike Decode it doesn't verify the gzip
+// stream. Values which merely start with a valid looking header are
+// therefore only reported incorrectly here and not when they are read.
+func (c *ValueCompression) DecodedLen(stored []byte) int {
+	if !c.enabled() {
+		return len(stored)
+	}
+
+	length, ok := compressedValueLen(stored)
+	if !ok {
+		return len(stored)
+	}
+	return length
+}
+
+// forEach calls the function with the decoded values. Buckets are still
+// passed as nil values.
+func (c *ValueCompression) forEach(forEach func(func(k, v []byte) error) error) func(func(k, v []byte) error) error {
+	return func(fn func(k, v []byte) error) error {
+		return forEach(func(k, v []byte) error {
+			if v == nil {
+				return fn(k, v)
+			}
+			return fn(k, c.Decode(v))
+		})
+	}
+}
+
+func isCompressedValue(stored []byte) bool {
+	_, ok := decompressValue(stored)
+	return ok
+}
+
+func compressValue(value []byte) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	buf.Write(compressedValueMagic)
+
+	header := make([]byte, 8)
+	binary.BigEndian.PutUint64(header, uint64(len(value)))
+	buf.Write(header)
+
+	w := gzip.NewWriter(buf)
+	if _, err := w.Write(value); err != nil {
+		return nil, errors.Wrap(err, "write failed")
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, errors.Wrap(err, "close failed")
+	}
+
+	return buf.Bytes(), nil
+}
+
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// compressedValueLen returns the uncompressed length declared in the header
+// of a compressed value.
+func compressedValueLen(stored []byte) (int, bool) {
+	if len(stored) < compressedValueHeaderLen+len(gzipMagic) || !bytes.HasPrefix(stored, compressedValueMagic) {
+		return 0, false
+	}
+
+	if !bytes.HasPrefix(stored[compressedValueHeaderLen:], gzipMagic) {
+		return 0, false
+	}
+
+	length := binary.BigEndian.Uint64(stored[len(compressedValueMagic):compressedValueHeaderLen])
+	// Bolt values can not be larger than 2GB.
+	if length > math.MaxInt32 {
+		return 0, false
+	}
+
+	return int(length), true
+}
+
+func decompressValue(stored []byte) ([]byte, bool) {
+	if len(stored) < compressedValueHeaderLen || !bytes.HasPrefix(stored, compressedValueMagic) {
+		return nil, false
+	}
+
+	length := binary.BigEndian.Uint64(stored[len(compressedValueMagic):compressedValueHeaderLen])
+	if length >= math.MaxInt64 {
+		return nil, false
+	}
+
+	src := bytes.NewReader(stored[compressedValueHeaderLen:])
+	r, err := gzip.NewReader(src)
+	if err != nil {
+		return nil, false
+	}
+	r.Multistream(false)
+
+	// The declared length isn't trusted when allocating the buffer as the
+	// value may only happen to look like a compressed value.
+	value, err := ioutil.ReadAll(io.LimitReader(r, int64(length)+1))
+	if err != nil || uint64(len(value)) != length {
+		return nil, false
+	}
+
+	if src.Len() != 0 {
+		return nil, false
+	}
+
+	return value, true
+}
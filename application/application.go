@@ -1,6 +1,13 @@
 package application
 
-import "errors"
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"time"
+)
 
 type Key struct {
 	b []byte
@@ -30,6 +37,11 @@ func (k Key) Bytes() []byte {
 	return tmp
 }
 
+// IsZero returns true if the key wasn't created using NewKey.
+func (k Key) IsZero() bool {
+	return len(k.b) == 0
+}
+
 type Value struct {
 	b []byte
 }
@@ -58,17 +70,384 @@ func (v Value) IsEmpty() bool {
 	return len(v.b) == 0
 }
 
+// ETag returns a string which changes whenever the contents of the value
+// change. It can be used to detect that a value was modified since it was
+// read.
+func (v Value) ETag() string {
+	h := sha256.Sum256(v.b)
+	return hex.EncodeToString(h[:])
+}
+
 type Tree struct {
 	Path    []Key
 	Entries []Entry
 }
 
 var ErrBucketNotFound = errors.New("err bucket not found")
+var ErrNotABucket = errors.New("err not a bucket")
+var ErrKeyNotFound = errors.New("err key not found")
+var ErrNotAValue = errors.New("err not a value")
+var ErrBucketExists = errors.New("err bucket already exists")
+var ErrValueExists = errors.New("err value already exists")
+var ErrReadOnly = errors.New("err read only")
+var ErrContainsBuckets = errors.New("err bucket contains nested buckets")
+var ErrValueChanged = errors.New("err value changed")
+var ErrMoveIntoDescendant = errors.New("err bucket can not be moved into itself or its descendant")
+var ErrConfirmationMismatch = errors.New("err confirmation mismatch")
+var ErrValueTooLarge = errors.New("err value too large")
+var ErrInvalidImport = errors.New("err invalid import")
 
 type Database interface {
 	// Browse returns ErrBucketNotFound if the bucket specified by the path
-	// does not exist.
+	// does not exist and ErrNotABucket if one of the path elements is a
+	// value.
 	Browse(path []Key, before, after, from *Key) ([]Entry, error)
+
+	// ResolvePath describes each segment of the path. Resolution stops at
+	// the first segment which doesn't exist or isn't a bucket.
+	ResolvePath(path []Key) (PathInfo, error)
+
+	// ListBuckets returns the names of all buckets nested directly in the
+	// bucket specified by the path. An empty path refers to the root. Returns
+	// ErrBucketNotFound if the bucket does not exist and ErrNotABucket if one
+	// of the path elements is a value.
+	ListBuckets(path []Key) ([]Key, error)
+
+	// ListKeys returns up to limit keys stored in the bucket specified by the
+	// path starting after the provided key. The returned page contains the
+	// key which should be used to retrieve the next page or nil if the end
+	// of the bucket was reached. If sizes is set the sizes of the values are
+	// included. If descending is set the keys are returned in the reverse
+	// byte order and the page starts before the provided key. Returns
+	// ErrBucketNotFound if the bucket does not exist and ErrNotABucket if
+	// one of the path elements is a value.
+	ListKeys(path []Key, after *Key, limit int, sizes, descending bool) (KeysPage, error)
+
+	// CountKeys returns the number of keys stored directly in the bucket
+	// specified by the path, keys of nested buckets are not counted. This
+	// requires iterating over the entire bucket. Returns ErrBucketNotFound if
+	// the bucket does not exist and ErrNotABucket if one of the path
+	// elements is a value. The error of the context is returned once the
+	// context is done.
+	CountKeys(ctx context.Context, path []Key) (int, error)
+
+	// SearchKeysByPrefix returns up to limit keys which start with the
+	// provided prefix stored in the bucket specified by the path. An empty
+	// prefix matches all keys. Returns ErrBucketNotFound if the bucket does
+	// not exist and ErrNotABucket if one of the path elements is a value.
+	SearchKeysByPrefix(path []Key, prefix []byte, limit int) ([]KeyInfo, error)
+
+	// CountKeysByPrefix returns the number of keys which start with the
+	// provided prefix stored directly in the bucket specified by the path,
+	// zero if none of them match. An empty prefix matches all keys. Returns
+	// ErrBucketNotFound if the bucket does not exist and ErrNotABucket if
+	// one of the path elements is a value. The error of the context is
+	// returned once the context is done.
+	CountKeysByPrefix(ctx context.Context, path []Key, prefix []byte) (int, error)
+
+	// SearchAllBuckets walks all buckets recursively and returns up to limit
+	// keys accepted by the matcher, including the names of the buckets. The
+	// search stops and the error of the context is returned once the
+	// context is done.
+	SearchAllBuckets(ctx context.Context, matcher KeyMatcher, limit int) ([]SearchHit, error)
+
+	// SearchValues walks the bucket specified by the path and all its nested
+	// buckets and passes up to limit values accepted by the matcher to the
+	// writer as soon as they are found. An empty path searches the entire
+	// database. The search stops and the error of the context is returned
+	// once the context is done. Returns ErrBucketNotFound if the bucket does
+	// not exist and ErrNotABucket if one of the path elements is a value.
+	SearchValues(ctx context.Context, path []Key, matcher ValueMatcher, limit int, w SearchHitWriter) error
+
+	// GetValue returns the value stored under the provided key in the bucket
+	// specified by the path. Returns ErrBucketNotFound if the bucket does not
+	// exist, ErrNotABucket if one of the path elements is a value,
+	// ErrKeyNotFound if the key does not exist and ErrNotAValue if the key
+	// points to a bucket.
+	GetValue(path []Key, key Key) (Value, error)
+
+	// StreamValue passes the value stored under the key in the bucket
+	// specified by the path to the value writer without copying it. The
+	// reader is only valid until the value writer returns. Returns the same
+	// errors as GetValue, those errors are returned without calling the value
+	// writer.
+	StreamValue(path []Key, key Key, w ValueWriter) error
+
+	// PutValue creates or overwrites the value stored under the provided key
+	// in the bucket specified by the path. Returns ErrBucketNotFound if the
+	// bucket does not exist, ErrNotABucket if one of the path elements is a
+	// value and ErrNotAValue if the key points to a bucket.
+	PutValue(path []Key, key Key, value Value) error
+
+	// AppendValue stores the value under a key generated using the next
+	// sequence number of the bucket specified by the path. The key contains
+	// the sequence number encoded as 8 big-endian bytes. Returns
+	// ErrBucketNotFound if the bucket does not exist, ErrNotABucket if one of
+	// the path elements is a value and ErrValueExists if the generated key is
+	// already used.
+	AppendValue(path []Key, value Value) (uint64, error)
+
+	// DeleteKey removes the value stored under the provided key in the bucket
+	// specified by the path. Returns ErrBucketNotFound if the bucket does not
+	// exist, ErrNotABucket if one of the path elements is a value,
+	// ErrKeyNotFound if the key does not exist and ErrNotAValue if the key
+	// points to a bucket.
+	DeleteKey(path []Key, key Key) error
+
+	// CreateBucket creates a new bucket with the provided name in the bucket
+	// specified by the path. An empty path refers to the root. Returns
+	// ErrBucketNotFound if the parent bucket does not exist, ErrNotABucket if
+	// one of the path elements is a value, ErrBucketExists if the bucket
+	// already exists and ErrValueExists if a value is stored under the
+	// provided name.
+	CreateBucket(path []Key, name Key) error
+
+	// DeleteBucket removes the bucket specified by the path together with
+	// all its contents. Returns ErrBucketNotFound if the bucket does not
+	// exist and ErrNotABucket if one of the path elements is a value.
+	DeleteBucket(path []Key) error
+
+	// MoveBucket recursively copies the bucket specified by the source path
+	// together with all its contents to a new bucket with the provided name
+	// created in the bucket specified by the destination parent path and
+	// then removes the source bucket. An empty destination parent path
+	// refers to the root. Returns ErrBucketNotFound if one of the buckets
+	// does not exist, ErrNotABucket if one of the path elements is a value,
+	// ErrBucketExists if the destination bucket already exists and
+	// ErrValueExists if a value is stored under the destination name.
+	MoveBucket(source []Key, destinationParent []Key, name Key) error
+
+	// CountBucketContents counts all values and buckets nested in the bucket
+	// specified by the path, including the contents of nested buckets.
+	// Returns ErrBucketNotFound if the bucket does not exist and
+	// ErrNotABucket if one of the path elements is a value. The error of the
+	// context is returned once the context is done.
+	CountBucketContents(ctx context.Context, path []Key) (BucketContents, error)
+
+	// BucketStats returns the statistics of the bucket specified by the path.
+	// An empty path returns the combined statistics of all top-level
+	// buckets. Returns ErrBucketNotFound if the bucket does not exist and
+	// ErrNotABucket if one of the path elements is a value.
+	BucketStats(path []Key) (BucketStats, error)
+
+	// BucketTree returns the buckets nested in the bucket specified by the
+	// path together with the buckets nested in them up to maxDepth levels,
+	// zero means no limit. The buckets at the last level are marked as
+	// truncated if they contain nested buckets. Values are not included. An
+	// empty path refers to the root. Returns ErrBucketNotFound if the bucket does not exist and
+	// ErrNotABucket if one of the path elements is a value. The error of the
+	// context is returned once the context is done.
+	BucketTree(ctx context.Context, path []Key, maxDepth int) ([]BucketTreeNode, error)
+
+	// ExportJSON writes the contents of the bucket specified by the path,
+	// including nested buckets, to the writer as JSON. An empty path exports
+	// the entire database. Returns ErrBucketNotFound if the bucket does not
+	// exist and ErrNotABucket if one of the path elements is a value. Those
+	// errors are returned before anything is written to the writer. The
+	// export stops and the error of the context is returned once the context
+	// is done.
+	ExportJSON(ctx context.Context, path []Key, w io.Writer) error
+
+	// ExportCSV writes the values stored in the bucket specified by the
+	// path to the writer as CSV with two columns: key and value. An empty
+	// path refers to the root. Returns ErrBucketNotFound if the bucket does
+	// not exist, ErrNotABucket if one of the path elements is a value and
+	// ErrContainsBuckets if the bucket contains nested buckets. Those errors
+	// are returned before anything is written to the writer. The export
+	// stops and the error of the context is returned once the context is
+	// done.
+	ExportCSV(ctx context.Context, path []Key, w io.Writer) error
+
+	// Import writes the entries decoded by ImportDecoder to the bucket
+	// specified by the path. An empty path refers to the root. The entries
+	// are validated before any changes are made. Returns ErrBucketNotFound
+	// if the bucket does not exist, ErrNotABucket if one of the path
+	// elements is a value, ErrNotAValue if an imported value would
+	// overwrite a bucket and ErrValueExists if an imported bucket would
+	// overwrite a value. Returns ErrInvalidImport if the entries contain
+	// values which can not be stored in the root of the database. If dryRun
+	// is set nothing is written and the problems with the entries are
+	// reported in the summary instead of being returned as errors.
+	Import(path []Key, entries []ImportedEntry, mode ImportMode, dryRun bool) (ImportSummary, error)
+
+	// Backup writes a consistent copy of the entire database file to the
+	// writer and returns the number of written bytes.
+	Backup(w io.Writer) (int64, error)
+
+	// DatabaseStats returns the statistics of the entire database.
+	DatabaseStats() (DatabaseStats, error)
+
+	// CheckConsistency verifies the structure of the database file and
+	// returns all found problems. An empty list means that the database is
+	// consistent.
+	CheckConsistency() ([]string, error)
+}
+
+// ValueWriter receives the size of the value and a reader returning its
+// contents.
+type ValueWriter func(size int, r io.Reader) error
+
+// ImportDecoder decodes the imported data. It is used before a transaction is
+// opened so that the clients sending the data slowly don't block the other
+// transactions.
+type ImportDecoder interface {
+	// Decode reads the entire input. Returns ErrInvalidImport if the input
+	// can not be decoded.
+	Decode(r io.Reader, format ImportFormat) ([]ImportedEntry, error)
+}
+
+// BoltFileImporter copies the contents of another Bolt database file into the
+// database.
+type BoltFileImporter interface {
+	// ImportBoltFile opens the file read-only and copies its top-level
+	// buckets into the bucket specified by the path, an empty path refers
+	// to the root. The values are copied exactly as they are stored in the
+	// file. The data is written using multiple transactions so that large
+	// files don't have to be kept in memory, if the import fails the
+	// already written data is not rolled back. Conflicts with the existing
+	// data are detected before anything is written. Returns ErrReadOnly if
+	// mutations are disabled, ErrInvalidImport if the file can not be
+	// opened, ErrBucketNotFound if the bucket does not exist, ErrNotABucket
+	// if one of the path elements is a value, ErrNotAValue if an imported
+	// value would overwrite a bucket and ErrValueExists if an imported
+	// bucket would overwrite a value.
+	ImportBoltFile(sourcePath string, path []Key, mode ImportMode) error
+}
+
+// Compactor rewrites the database file to return the free pages to the
+// operating system.
+type Compactor interface {
+	// Compact returns ErrReadOnly if mutations are disabled.
+	Compact() (CompactionResult, error)
+}
+
+// CompactionResult reports the size of the database file before and after
+// the compaction in bytes.
+type CompactionResult struct {
+	SizeBefore int64
+	SizeAfter  int64
+}
+
+// FileInspector reports the information about the database file.
+type FileInspector interface {
+	Inspect() (FileInfo, error)
+}
+
+// FileInfo describes the database file. ReadOnly is set if mutations are
+// disabled.
+type FileInfo struct {
+	Path     string
+	Size     int64
+	Modified time.Time
+	ReadOnly bool
+}
+
+// DatabaseStats mirrors the database statistics reported by Bolt.
+type DatabaseStats struct {
+	// Size of the database in bytes.
+	Size int64
+
+	// Freelist statistics.
+	FreePageN     int
+	PendingPageN  int
+	FreeAlloc     int
+	FreelistInuse int
+
+	// Transaction statistics.
+	TxN     int
+	OpenTxN int
+
+	// Page statistics.
+	PageCount int
+	PageAlloc int
+}
+
+// BucketStats mirrors the statistics reported by Bolt.
+type BucketStats struct {
+	// Page count statistics.
+	BranchPageN     int
+	BranchOverflowN int
+	LeafPageN       int
+	LeafOverflowN   int
+
+	// Tree statistics.
+	KeyN  int
+	Depth int
+
+	// Page size utilization.
+	BranchAlloc int
+	BranchInuse int
+	LeafAlloc   int
+	LeafInuse   int
+
+	// Bucket statistics.
+	BucketN           int
+	InlineBucketN     int
+	InlineBucketInuse int
+}
+
+// PathInfo describes the segments of a path. The last segment is the first
+// one which doesn't exist or isn't a bucket if the path couldn't be resolved.
+type PathInfo struct {
+	Segments []PathSegment
+}
+
+// Resolved returns true if all segments of the path are existing buckets.
+func (p PathInfo) Resolved() bool {
+	if len(p.Segments) == 0 {
+		return true
+	}
+	return p.Segments[len(p.Segments)-1].Bucket
+}
+
+type PathSegment struct {
+	Key    Key
+	Exists bool
+	Bucket bool
+}
+
+type BucketContents struct {
+	Values  int
+	Buckets int
+}
+
+type KeysPage struct {
+	Keys []KeyInfo
+	Next *Key
+
+	// Total is the number of keys in the bucket, nil unless requested.
+	Total *int
+}
+
+// KeyMatcher returns true if the key matches the search criteria.
+type KeyMatcher func(key []byte) bool
+
+// ValueMatcher returns true and a short fragment of the value if the value
+// matches the search criteria.
+type ValueMatcher func(value []byte) (snippet string, ok bool)
+
+// SearchHit describes where a matching key is located without including the
+// value stored under it.
+type SearchHit struct {
+	Path   []Key
+	Bucket bool
+	Key    Key
+
+	// Snippet is a short fragment of the matching value, empty when
+	// searching keys.
+	Snippet string
+}
+
+// SearchHitWriter receives the search hits as they are found.
+type SearchHitWriter func(hit SearchHit) error
+
+type KeyInfo struct {
+	Bucket bool
+	Key    Key
+
+	// Size is the length of the value in bytes, nil for buckets and unless
+	// requested.
+	Size *int
 }
 
 type Entry struct {
@@ -78,11 +457,47 @@ type Entry struct {
 }
 
 type Application struct {
-	Browse *BrowseHandler
+	Browse              *BrowseHandler
+	ResolvePath         *ResolvePathHandler
+	ListBuckets         *ListBucketsHandler
+	ListKeys            *ListKeysHandler
+	SearchKeys          *SearchKeysHandler
+	CountKeysByPrefix   *CountKeysByPrefixHandler
+	SearchAllBuckets    *SearchAllBucketsHandler
+	SearchValues        *SearchValuesHandler
+	GetValue            *GetValueHandler
+	PutValue            *PutValueHandler
+	AppendValue         *AppendValueHandler
+	DeleteKey           *DeleteKeyHandler
+	RenameKey           *RenameKeyHandler
+	CopyKey             *CopyKeyHandler
+	CreateBucket        *CreateBucketHandler
+	DeleteBucket        *DeleteBucketHandler
+	PreviewDeleteBucket *PreviewDeleteBucketHandler
+	MoveBucket          *MoveBucketHandler
+	CountBucketContents *CountBucketContentsHandler
+	GetBucketStats      *GetBucketStatsHandler
+	GetBucketTree       *GetBucketTreeHandler
+	ExportBucket        *ExportBucketHandler
+	ExportBucketCSV     *ExportBucketCSVHandler
+	ImportBucket        *ImportBucketHandler
+	ImportBoltFile      *ImportBoltFileHandler
+	Backup              *BackupHandler
+	CompactDatabase     *CompactDatabaseHandler
+	CheckHealth         *CheckHealthHandler
+	CheckConsistency    *CheckConsistencyHandler
+	GetDatabaseStats    *GetDatabaseStatsHandler
+	GetFileInfo         *GetFileInfoHandler
+	SubscribeToChanges  *SubscribeToChangesHandler
+	StreamValue         *StreamValueHandler
+	BatchWrite          *BatchWriteHandler
+	ListAuditEntries    *ListAuditEntriesHandler
 }
 
 type TransactionProvider interface {
 	Read(handler TransactionHandler) error
+
+	// Write returns ErrReadOnly if mutations are disabled.
 	Write(handler TransactionHandler) error
 }
 
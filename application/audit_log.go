@@ -0,0 +1,35 @@
+package application
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrAuditLogDisabled = errors.New("err audit log disabled")
+
+// AuditEntry describes a modification of the database recorded in the audit
+// log.
+type AuditEntry struct {
+	// ID increases with each recorded entry and can be used to page
+	// through the entries.
+	ID uint64
+
+	Time      time.Time
+	Operation ChangeOperation
+
+	// Path of the bucket which was modified.
+	Path []Key
+
+	// Key which was modified, nil if the entire bucket was modified.
+	Key *Key
+}
+
+// AuditLog provides access to the recorded modifications. The entries are
+// recorded by a ChangePublisher.
+type AuditLog interface {
+	// ListAuditEntries returns the entries recorded at or after the given
+	// time with IDs lower than before, newest first. A before of zero
+	// starts with the newest entry. A limit of zero returns all entries.
+	// Returns ErrAuditLogDisabled if the audit log is disabled.
+	ListAuditEntries(since time.Time, before uint64, limit int) ([]AuditEntry, error)
+}
@@ -0,0 +1,9 @@
+package application
+
+// Cache stores the results of queries related to a specific path. The cached
+// results are invalidated when changes related to the path are made.
+type Cache interface {
+	// GetOrLoad returns the cached result of the query or calls load to
+	// compute it. Errors returned by load are not cached.
+	GetOrLoad(query string, path []Key, load func() (interface{}, error)) (interface{}, error)
+}
@@ -0,0 +1,39 @@
+package application
+
+// ChangeOperation describes what kind of modification was made.
+type ChangeOperation string
+
+const (
+	ChangeOperationPutValue     ChangeOperation = "putValue"
+	ChangeOperationDeleteKey    ChangeOperation = "deleteKey"
+	ChangeOperationCreateBucket ChangeOperation = "createBucket"
+	ChangeOperationDeleteBucket ChangeOperation = "deleteBucket"
+	ChangeOperationImport       ChangeOperation = "import"
+)
+
+// Change describes a modification of the database made by this program.
+type Change struct {
+	// Path of the bucket which was modified.
+	Path []Key
+
+	// Key which was modified, nil if the entire bucket was modified.
+	Key *Key
+
+	Operation ChangeOperation
+}
+
+// ChangePublisher is notified about the changes after they are committed.
+type ChangePublisher interface {
+	// Publish must not block.
+	Publish(change Change)
+}
+
+// ChangeSubscriber provides a way to receive changes made within a bucket.
+type ChangeSubscriber interface {
+	// Subscribe returns a channel which receives changes made in the bucket
+	// specified by the path, its children and its parents. An empty path
+	// receives all changes. Changes are dropped instead of blocking the
+	// publisher if the channel is full. The returned function has to be
+	// called to unsubscribe, after that the channel is closed.
+	Subscribe(path []Key) (<-chan Change, func())
+}
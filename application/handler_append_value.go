@@ -0,0 +1,70 @@
+package application
+
+import (
+	"github.com/boreq/errors"
+)
+
+type AppendValue struct {
+	Path  []Key
+	Value Value
+}
+
+// AppendedValue describes the key under which the value was stored.
+type AppendedValue struct {
+	Key      Key
+	Sequence uint64
+}
+
+type AppendValueHandler struct {
+	transactionProvider TransactionProvider
+	changePublisher     ChangePublisher
+	maxValueSize        MaxValueSize
+}
+
+func NewAppendValueHandler(transactionProvider TransactionProvider, changePublisher ChangePublisher, maxValueSize MaxValueSize) *AppendValueHandler {
+	return &AppendValueHandler{
+		transactionProvider: transactionProvider,
+		changePublisher:     changePublisher,
+		maxValueSize:        maxValueSize,
+	}
+}
+
+// Execute increments the sequence of the bucket and stores the value in a
+// single transaction so that the sequence number is not used if the value
+// can't be stored.
+func (h *AppendValueHandler) Execute(cmd AppendValue) (AppendedValue, error) {
+	if len(cmd.Path) == 0 {
+		return AppendedValue{}, errors.New("values can not be stored in the root of the database")
+	}
+
+	if err := h.maxValueSize.Check(cmd.Value); err != nil {
+		return AppendedValue{}, err
+	}
+
+	var sequence uint64
+
+	if err := h.transactionProvider.Write(func(adapters *TransactableAdapters) error {
+		var err error
+		sequence, err = adapters.Database.AppendValue(cmd.Path, cmd.Value)
+		if err != nil {
+			return errors.Wrap(err, "could not append the value")
+		}
+
+		return nil
+	}); err != nil {
+		return AppendedValue{}, errors.Wrap(err, "transaction failed")
+	}
+
+	key := NewUint64Key(sequence)
+
+	h.changePublisher.Publish(Change{
+		Path:      cmd.Path,
+		Key:       &key,
+		Operation: ChangeOperationPutValue,
+	})
+
+	return AppendedValue{
+		Key:      key,
+		Sequence: sequence,
+	}, nil
+}
@@ -0,0 +1,36 @@
+package application
+
+import (
+	"io"
+
+	"github.com/boreq/errors"
+)
+
+type Backup struct {
+	Writer io.Writer
+}
+
+type BackupHandler struct {
+	transactionProvider TransactionProvider
+}
+
+func NewBackupHandler(transactionProvider TransactionProvider) *BackupHandler {
+	return &BackupHandler{
+		transactionProvider: transactionProvider,
+	}
+}
+
+func (h *BackupHandler) Execute(query Backup) (n int64, err error) {
+	if err := h.transactionProvider.Read(func(adapters *TransactableAdapters) error {
+		n, err = adapters.Database.Backup(query.Writer)
+		if err != nil {
+			return errors.Wrap(err, "could not write the backup")
+		}
+
+		return nil
+	}); err != nil {
+		return n, errors.Wrap(err, "transaction failed")
+	}
+
+	return n, nil
+}
@@ -0,0 +1,106 @@
+package application
+
+import (
+	"fmt"
+
+	"github.com/boreq/errors"
+)
+
+const MaxBatchWriteOperations = 10000
+
+type KeyOperationType string
+
+const (
+	KeyOperationTypePut    KeyOperationType = "put"
+	KeyOperationTypeDelete KeyOperationType = "delete"
+)
+
+type KeyOperation struct {
+	Type KeyOperationType
+	Key  Key
+
+	// Value is ignored for delete operations.
+	Value Value
+}
+
+type BatchWrite struct {
+	Path       []Key
+	Operations []KeyOperation
+}
+
+type BatchWriteHandler struct {
+	transactionProvider TransactionProvider
+	changePublisher     ChangePublisher
+	maxValueSize        MaxValueSize
+}
+
+func NewBatchWriteHandler(transactionProvider TransactionProvider, changePublisher ChangePublisher, maxValueSize MaxValueSize) *BatchWriteHandler {
+	return &BatchWriteHandler{
+		transactionProvider: transactionProvider,
+		changePublisher:     changePublisher,
+		maxValueSize:        maxValueSize,
+	}
+}
+
+// Execute applies all operations in order in a single transaction, either all
+// of them are applied or none of them are.
+func (h *BatchWriteHandler) Execute(cmd BatchWrite) error {
+	if len(cmd.Path) == 0 {
+		return errors.New("values can not be stored in the root of the database")
+	}
+
+	if len(cmd.Operations) == 0 || len(cmd.Operations) > MaxBatchWriteOperations {
+		return fmt.Errorf("number of operations must be between 1 and %d", MaxBatchWriteOperations)
+	}
+
+	for i, operation := range cmd.Operations {
+		if operation.Key.IsZero() {
+			return fmt.Errorf("operation %d has an empty key", i)
+		}
+
+		if operation.Type != KeyOperationTypePut && operation.Type != KeyOperationTypeDelete {
+			return fmt.Errorf("operation %d has an invalid type '%s'", i, operation.Type)
+		}
+
+		if operation.Type == KeyOperationTypePut {
+			if err := h.maxValueSize.Check(operation.Value); err != nil {
+				return errors.Wrapf(err, "operation %d", i)
+			}
+		}
+	}
+
+	if err := h.transactionProvider.Write(func(adapters *TransactableAdapters) error {
+		for i, operation := range cmd.Operations {
+			switch operation.Type {
+			case KeyOperationTypePut:
+				if err := adapters.Database.PutValue(cmd.Path, operation.Key, operation.Value); err != nil {
+					return errors.Wrapf(err, "could not put the value in operation %d", i)
+				}
+			case KeyOperationTypeDelete:
+				if err := adapters.Database.DeleteKey(cmd.Path, operation.Key); err != nil {
+					return errors.Wrapf(err, "could not delete the key in operation %d", i)
+				}
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return errors.Wrap(err, "transaction failed")
+	}
+
+	for i := range cmd.Operations {
+		change := Change{
+			Path:      cmd.Path,
+			Key:       &cmd.Operations[i].Key,
+			Operation: ChangeOperationPutValue,
+		}
+
+		if cmd.Operations[i].Type == KeyOperationTypeDelete {
+			change.Operation = ChangeOperationDeleteKey
+		}
+
+		h.changePublisher.Publish(change)
+	}
+
+	return nil
+}
@@ -0,0 +1,46 @@
+package application
+
+import (
+	"github.com/boreq/errors"
+)
+
+type GetBucketStats struct {
+	Path []Key
+}
+
+type GetBucketStatsHandler struct {
+	transactionProvider TransactionProvider
+	cache               Cache
+}
+
+func NewGetBucketStatsHandler(transactionProvider TransactionProvider, cache Cache) *GetBucketStatsHandler {
+	return &GetBucketStatsHandler{
+		transactionProvider: transactionProvider,
+		cache:               cache,
+	}
+}
+
+func (h *GetBucketStatsHandler) Execute(query GetBucketStats) (BucketStats, error) {
+	v, err := h.cache.GetOrLoad("bucketStats", query.Path, func() (interface{}, error) {
+		return h.bucketStats(query)
+	})
+	if err != nil {
+		return BucketStats{}, err
+	}
+	return v.(BucketStats), nil
+}
+
+func (h *GetBucketStatsHandler) bucketStats(query GetBucketStats) (stats BucketStats, err error) {
+	if err := h.transactionProvider.Read(func(adapters *TransactableAdapters) error {
+		stats, err = adapters.Database.BucketStats(query.Path)
+		if err != nil {
+			return errors.Wrap(err, "could not get the bucket stats")
+		}
+
+		return nil
+	}); err != nil {
+		return stats, errors.Wrap(err, "transaction failed")
+	}
+
+	return stats, nil
+}
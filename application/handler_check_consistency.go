@@ -0,0 +1,35 @@
+package application
+
+import (
+	"github.com/boreq/errors"
+)
+
+type CheckConsistency struct {
+}
+
+type CheckConsistencyHandler struct {
+	transactionProvider TransactionProvider
+}
+
+func NewCheckConsistencyHandler(transactionProvider TransactionProvider) *CheckConsistencyHandler {
+	return &CheckConsistencyHandler{
+		transactionProvider: transactionProvider,
+	}
+}
+
+// Execute reads the entire database so it takes a long time for large
+// databases.
+func (h *CheckConsistencyHandler) Execute(query CheckConsistency) (problems []string, err error) {
+	if err := h.transactionProvider.Read(func(adapters *TransactableAdapters) error {
+		problems, err = adapters.Database.CheckConsistency()
+		if err != nil {
+			return errors.Wrap(err, "could not check the consistency")
+		}
+
+		return nil
+	}); err != nil {
+		return nil, errors.Wrap(err, "transaction failed")
+	}
+
+	return problems, nil
+}
@@ -0,0 +1,30 @@
+package application
+
+import (
+	"github.com/boreq/errors"
+)
+
+type CheckHealth struct {
+}
+
+type CheckHealthHandler struct {
+	transactionProvider TransactionProvider
+}
+
+func NewCheckHealthHandler(transactionProvider TransactionProvider) *CheckHealthHandler {
+	return &CheckHealthHandler{
+		transactionProvider: transactionProvider,
+	}
+}
+
+// Execute returns an error if a read transaction can't be started which
+// usually means that the database was closed.
+func (h *CheckHealthHandler) Execute(query CheckHealth) error {
+	if err := h.transactionProvider.Read(func(adapters *TransactableAdapters) error {
+		return nil
+	}); err != nil {
+		return errors.Wrap(err, "transaction failed")
+	}
+
+	return nil
+}
@@ -0,0 +1,27 @@
+package application
+
+import (
+	"github.com/boreq/errors"
+)
+
+type CompactDatabase struct {
+}
+
+type CompactDatabaseHandler struct {
+	compactor Compactor
+}
+
+func NewCompactDatabaseHandler(compactor Compactor) *CompactDatabaseHandler {
+	return &CompactDatabaseHandler{
+		compactor: compactor,
+	}
+}
+
+func (h *CompactDatabaseHandler) Execute(cmd CompactDatabase) (CompactionResult, error) {
+	result, err := h.compactor.Compact()
+	if err != nil {
+		return CompactionResult{}, errors.Wrap(err, "could not compact the database")
+	}
+
+	return result, nil
+}
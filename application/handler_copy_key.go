@@ -0,0 +1,116 @@
+package application
+
+import (
+	"bytes"
+
+	"github.com/boreq/errors"
+)
+
+type CopyKey struct {
+	SourcePath []Key
+	SourceKey  Key
+
+	DestinationPath []Key
+	DestinationKey  Key
+
+	// Move deletes the source key after copying the value.
+	Move bool
+
+	// Overwrite allows replacing a value which is already stored under the
+	// destination key, otherwise ErrValueExists is returned.
+	Overwrite bool
+}
+
+type CopyKeyHandler struct {
+	transactionProvider TransactionProvider
+	changePublisher     ChangePublisher
+}
+
+func NewCopyKeyHandler(transactionProvider TransactionProvider, changePublisher ChangePublisher) *CopyKeyHandler {
+	return &CopyKeyHandler{
+		transactionProvider: transactionProvider,
+		changePublisher:     changePublisher,
+	}
+}
+
+// Execute copies or moves the value in a single transaction. Both buckets are
+// resolved before anything is written. Copying a key onto itself only checks
+// that the value exists.
+func (h *CopyKeyHandler) Execute(cmd CopyKey) error {
+	if len(cmd.SourcePath) == 0 || len(cmd.DestinationPath) == 0 {
+		return errors.New("values can not be stored in the root of the database")
+	}
+
+	if cmd.SourceKey.IsZero() || cmd.DestinationKey.IsZero() {
+		return errors.New("keys can not be empty")
+	}
+
+	same := samePath(cmd.SourcePath, cmd.DestinationPath) && bytes.Equal(cmd.SourceKey.Bytes(), cmd.DestinationKey.Bytes())
+
+	if err := h.transactionProvider.Write(func(adapters *TransactableAdapters) error {
+		value, err := adapters.Database.GetValue(cmd.SourcePath, cmd.SourceKey)
+		if err != nil {
+			return errors.Wrap(err, "could not get the source value")
+		}
+
+		if same {
+			return nil
+		}
+
+		if _, err := adapters.Database.GetValue(cmd.DestinationPath, cmd.DestinationKey); err != nil {
+			if !errors.Is(err, ErrKeyNotFound) {
+				return errors.Wrap(err, "could not check the destination key")
+			}
+		} else if !cmd.Overwrite {
+			return ErrValueExists
+		}
+
+		if err := adapters.Database.PutValue(cmd.DestinationPath, cmd.DestinationKey, value); err != nil {
+			return errors.Wrap(err, "could not put the value")
+		}
+
+		if cmd.Move {
+			if err := adapters.Database.DeleteKey(cmd.SourcePath, cmd.SourceKey); err != nil {
+				return errors.Wrap(err, "could not delete the source key")
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return errors.Wrap(err, "transaction failed")
+	}
+
+	if same {
+		return nil
+	}
+
+	h.changePublisher.Publish(Change{
+		Path:      cmd.DestinationPath,
+		Key:       &cmd.DestinationKey,
+		Operation: ChangeOperationPutValue,
+	})
+
+	if cmd.Move {
+		h.changePublisher.Publish(Change{
+			Path:      cmd.SourcePath,
+			Key:       &cmd.SourceKey,
+			Operation: ChangeOperationDeleteKey,
+		})
+	}
+
+	return nil
+}
+
+func samePath(a, b []Key) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if !bytes.Equal(a[i].Bytes(), b[i].Bytes()) {
+			return false
+		}
+	}
+
+	return true
+}
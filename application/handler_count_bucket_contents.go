@@ -0,0 +1,43 @@
+package application
+
+import (
+	"context"
+
+	"github.com/boreq/errors"
+)
+
+type CountBucketContents struct {
+	// Context stops the operation once it is done.
+	Context context.Context
+
+	Path []Key
+}
+
+type CountBucketContentsHandler struct {
+	transactionProvider TransactionProvider
+}
+
+func NewCountBucketContentsHandler(transactionProvider TransactionProvider) *CountBucketContentsHandler {
+	return &CountBucketContentsHandler{
+		transactionProvider: transactionProvider,
+	}
+}
+
+func (h *CountBucketContentsHandler) Execute(query CountBucketContents) (contents BucketContents, err error) {
+	if query.Context == nil {
+		return contents, errors.New("context is nil")
+	}
+
+	if err := h.transactionProvider.Read(func(adapters *TransactableAdapters) error {
+		contents, err = adapters.Database.CountBucketContents(query.Context, query.Path)
+		if err != nil {
+			return errors.Wrap(err, "could not count the bucket contents")
+		}
+
+		return nil
+	}); err != nil {
+		return contents, errors.Wrap(err, "transaction failed")
+	}
+
+	return contents, nil
+}
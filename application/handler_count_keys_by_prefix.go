@@ -0,0 +1,44 @@
+package application
+
+import (
+	"context"
+
+	"github.com/boreq/errors"
+)
+
+type CountKeysByPrefix struct {
+	// Context stops the operation once it is done.
+	Context context.Context
+
+	Path   []Key
+	Prefix []byte
+}
+
+type CountKeysByPrefixHandler struct {
+	transactionProvider TransactionProvider
+}
+
+func NewCountKeysByPrefixHandler(transactionProvider TransactionProvider) *CountKeysByPrefixHandler {
+	return &CountKeysByPrefixHandler{
+		transactionProvider: transactionProvider,
+	}
+}
+
+func (h *CountKeysByPrefixHandler) Execute(query CountKeysByPrefix) (n int, err error) {
+	if query.Context == nil {
+		return 0, errors.New("context is nil")
+	}
+
+	if err := h.transactionProvider.Read(func(adapters *TransactableAdapters) error {
+		n, err = adapters.Database.CountKeysByPrefix(query.Context, query.Path, query.Prefix)
+		if err != nil {
+			return errors.Wrap(err, "could not count the keys")
+		}
+
+		return nil
+	}); err != nil {
+		return 0, errors.Wrap(err, "transaction failed")
+	}
+
+	return n, nil
+}
@@ -0,0 +1,42 @@
+package application
+
+import (
+	"github.com/boreq/errors"
+)
+
+type CreateBucket struct {
+	Path []Key
+	Name Key
+}
+
+type CreateBucketHandler struct {
+	transactionProvider TransactionProvider
+	changePublisher     ChangePublisher
+}
+
+func NewCreateBucketHandler(transactionProvider TransactionProvider, changePublisher ChangePublisher) *CreateBucketHandler {
+	return &CreateBucketHandler{
+		transactionProvider: transactionProvider,
+		changePublisher:     changePublisher,
+	}
+}
+
+func (h *CreateBucketHandler) Execute(cmd CreateBucket) error {
+	if err := h.transactionProvider.Write(func(adapters *TransactableAdapters) error {
+		if err := adapters.Database.CreateBucket(cmd.Path, cmd.Name); err != nil {
+			return errors.Wrap(err, "could not create the bucket")
+		}
+
+		return nil
+	}); err != nil {
+		return errors.Wrap(err, "transaction failed")
+	}
+
+	h.changePublisher.Publish(Change{
+		Path:      cmd.Path,
+		Key:       &cmd.Name,
+		Operation: ChangeOperationCreateBucket,
+	})
+
+	return nil
+}
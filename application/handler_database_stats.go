@@ -0,0 +1,33 @@
+package application
+
+import (
+	"github.com/boreq/errors"
+)
+
+type GetDatabaseStats struct {
+}
+
+type GetDatabaseStatsHandler struct {
+	transactionProvider TransactionProvider
+}
+
+func NewGetDatabaseStatsHandler(transactionProvider TransactionProvider) *GetDatabaseStatsHandler {
+	return &GetDatabaseStatsHandler{
+		transactionProvider: transactionProvider,
+	}
+}
+
+func (h *GetDatabaseStatsHandler) Execute(query GetDatabaseStats) (stats DatabaseStats, err error) {
+	if err := h.transactionProvider.Read(func(adapters *TransactableAdapters) error {
+		stats, err = adapters.Database.DatabaseStats()
+		if err != nil {
+			return errors.Wrap(err, "could not get the database stats")
+		}
+
+		return nil
+	}); err != nil {
+		return stats, errors.Wrap(err, "transaction failed")
+	}
+
+	return stats, nil
+}
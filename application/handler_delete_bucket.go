@@ -0,0 +1,64 @@
+package application
+
+import (
+	"context"
+
+	"github.com/boreq/errors"
+)
+
+type DeleteBucket struct {
+	Path []Key
+
+	// Confirmation returned by PreviewDeleteBucket. If set, the bucket is
+	// only deleted if the number of values and buckets it contains didn't
+	// change since the preview. Otherwise ErrConfirmationMismatch is
+	// returned.
+	Confirmation string
+}
+
+type DeleteBucketHandler struct {
+	transactionProvider TransactionProvider
+	changePublisher     ChangePublisher
+}
+
+func NewDeleteBucketHandler(transactionProvider TransactionProvider, changePublisher ChangePublisher) *DeleteBucketHandler {
+	return &DeleteBucketHandler{
+		transactionProvider: transactionProvider,
+		changePublisher:     changePublisher,
+	}
+}
+
+func (h *DeleteBucketHandler) Execute(cmd DeleteBucket) error {
+	if len(cmd.Path) == 0 {
+		return errors.New("path can not be empty")
+	}
+
+	if err := h.transactionProvider.Write(func(adapters *TransactableAdapters) error {
+		if cmd.Confirmation != "" {
+			contents, err := adapters.Database.CountBucketContents(context.Background(), cmd.Path)
+			if err != nil {
+				return errors.Wrap(err, "could not count the bucket contents")
+			}
+
+			if deleteBucketConfirmation(cmd.Path, contents) != cmd.Confirmation {
+				return ErrConfirmationMismatch
+			}
+		}
+
+		if err := adapters.Database.DeleteBucket(cmd.Path); err != nil {
+			return errors.Wrap(err, "could not delete the bucket")
+		}
+
+		return nil
+	}); err != nil {
+		return errors.Wrap(err, "transaction failed")
+	}
+
+	h.changePublisher.Publish(Change{
+		Path:      cmd.Path[:len(cmd.Path)-1],
+		Key:       &cmd.Path[len(cmd.Path)-1],
+		Operation: ChangeOperationDeleteBucket,
+	})
+
+	return nil
+}
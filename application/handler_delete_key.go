@@ -0,0 +1,42 @@
+package application
+
+import (
+	"github.com/boreq/errors"
+)
+
+type DeleteKey struct {
+	Path []Key
+	Key  Key
+}
+
+type DeleteKeyHandler struct {
+	transactionProvider TransactionProvider
+	changePublisher     ChangePublisher
+}
+
+func NewDeleteKeyHandler(transactionProvider TransactionProvider, changePublisher ChangePublisher) *DeleteKeyHandler {
+	return &DeleteKeyHandler{
+		transactionProvider: transactionProvider,
+		changePublisher:     changePublisher,
+	}
+}
+
+func (h *DeleteKeyHandler) Execute(cmd DeleteKey) error {
+	if err := h.transactionProvider.Write(func(adapters *TransactableAdapters) error {
+		if err := adapters.Database.DeleteKey(cmd.Path, cmd.Key); err != nil {
+			return errors.Wrap(err, "could not delete the key")
+		}
+
+		return nil
+	}); err != nil {
+		return errors.Wrap(err, "transaction failed")
+	}
+
+	h.changePublisher.Publish(Change{
+		Path:      cmd.Path,
+		Key:       &cmd.Key,
+		Operation: ChangeOperationDeleteKey,
+	})
+
+	return nil
+}
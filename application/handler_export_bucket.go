@@ -0,0 +1,44 @@
+package application
+
+import (
+	"context"
+	"io"
+
+	"github.com/boreq/errors"
+)
+
+type ExportBucket struct {
+	// Context stops the operation once it is done.
+	Context context.Context
+
+	Path   []Key
+	Writer io.Writer
+}
+
+type ExportBucketHandler struct {
+	transactionProvider TransactionProvider
+}
+
+func NewExportBucketHandler(transactionProvider TransactionProvider) *ExportBucketHandler {
+	return &ExportBucketHandler{
+		transactionProvider: transactionProvider,
+	}
+}
+
+func (h *ExportBucketHandler) Execute(query ExportBucket) error {
+	if query.Context == nil {
+		return errors.New("context is nil")
+	}
+
+	if err := h.transactionProvider.Read(func(adapters *TransactableAdapters) error {
+		if err := adapters.Database.ExportJSON(query.Context, query.Path, query.Writer); err != nil {
+			return errors.Wrap(err, "could not export the bucket")
+		}
+
+		return nil
+	}); err != nil {
+		return errors.Wrap(err, "transaction failed")
+	}
+
+	return nil
+}
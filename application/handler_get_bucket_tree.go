@@ -0,0 +1,66 @@
+package application
+
+import (
+	"context"
+
+	"github.com/boreq/errors"
+)
+
+// BucketTreeMaxDepth is the maximum number of levels of nested buckets
+// included in a bucket tree, zero means that the depth is not limited.
+type BucketTreeMaxDepth int
+
+type GetBucketTree struct {
+	// Context stops the operation once it is done.
+	Context context.Context
+
+	Path []Key
+}
+
+// BucketTreeNode describes a bucket and the buckets nested in it.
+type BucketTreeNode struct {
+	Name Key
+
+	// KeyN is the number of keys reported by Bolt which includes the keys
+	// stored in the nested buckets.
+	KeyN int
+
+	// Truncated is set if the nested buckets were omitted as the maximum
+	// depth was reached.
+	Truncated bool
+
+	Buckets []BucketTreeNode
+}
+
+type GetBucketTreeHandler struct {
+	transactionProvider TransactionProvider
+	maxDepth            BucketTreeMaxDepth
+}
+
+func NewGetBucketTreeHandler(transactionProvider TransactionProvider, maxDepth BucketTreeMaxDepth) *GetBucketTreeHandler {
+	return &GetBucketTreeHandler{
+		transactionProvider: transactionProvider,
+		maxDepth:            maxDepth,
+	}
+}
+
+// Execute returns the tree of buckets nested in the bucket specified by the
+// path. An empty path refers to the root.
+func (h *GetBucketTreeHandler) Execute(query GetBucketTree) (tree []BucketTreeNode, err error) {
+	if query.Context == nil {
+		return nil, errors.New("context is nil")
+	}
+
+	if err := h.transactionProvider.Read(func(adapters *TransactableAdapters) error {
+		tree, err = adapters.Database.BucketTree(query.Context, query.Path, int(h.maxDepth))
+		if err != nil {
+			return errors.Wrap(err, "could not get the bucket tree")
+		}
+
+		return nil
+	}); err != nil {
+		return nil, errors.Wrap(err, "transaction failed")
+	}
+
+	return tree, nil
+}
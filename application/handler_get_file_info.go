@@ -0,0 +1,27 @@
+package application
+
+import (
+	"github.com/boreq/errors"
+)
+
+type GetFileInfo struct {
+}
+
+type GetFileInfoHandler struct {
+	inspector FileInspector
+}
+
+func NewGetFileInfoHandler(inspector FileInspector) *GetFileInfoHandler {
+	return &GetFileInfoHandler{
+		inspector: inspector,
+	}
+}
+
+func (h *GetFileInfoHandler) Execute(query GetFileInfo) (FileInfo, error) {
+	info, err := h.inspector.Inspect()
+	if err != nil {
+		return FileInfo{}, errors.Wrap(err, "could not inspect the database file")
+	}
+
+	return info, nil
+}
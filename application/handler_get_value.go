@@ -0,0 +1,35 @@
+package application
+
+import (
+	"github.com/boreq/errors"
+)
+
+type GetValue struct {
+	Path []Key
+	Key  Key
+}
+
+type GetValueHandler struct {
+	transactionProvider TransactionProvider
+}
+
+func NewGetValueHandler(transactionProvider TransactionProvider) *GetValueHandler {
+	return &GetValueHandler{
+		transactionProvider: transactionProvider,
+	}
+}
+
+func (h *GetValueHandler) Execute(query GetValue) (value Value, err error) {
+	if err := h.transactionProvider.Read(func(adapters *TransactableAdapters) error {
+		value, err = adapters.Database.GetValue(query.Path, query.Key)
+		if err != nil {
+			return errors.Wrap(err, "could not get the value")
+		}
+
+		return nil
+	}); err != nil {
+		return value, errors.Wrap(err, "transaction failed")
+	}
+
+	return value, nil
+}
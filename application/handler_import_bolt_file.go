@@ -0,0 +1,52 @@
+package application
+
+import (
+	"github.com/boreq/errors"
+)
+
+// ImportBoltFile merges another Bolt database file, such as the one produced
+// by Backup, into the database.
+type ImportBoltFile struct {
+	// SourcePath is the path to the imported file.
+	SourcePath string
+
+	// Path specifies the bucket into which the top-level buckets of the
+	// file are imported, an empty path refers to the root.
+	Path []Key
+
+	Mode ImportMode
+}
+
+type ImportBoltFileHandler struct {
+	boltFileImporter BoltFileImporter
+	changePublisher  ChangePublisher
+}
+
+func NewImportBoltFileHandler(boltFileImporter BoltFileImporter, changePublisher ChangePublisher) *ImportBoltFileHandler {
+	return &ImportBoltFileHandler{
+		boltFileImporter: boltFileImporter,
+		changePublisher:  changePublisher,
+	}
+}
+
+// Execute copies the data using multiple transactions, see BoltFileImporter.
+func (h *ImportBoltFileHandler) Execute(cmd ImportBoltFile) error {
+	if cmd.SourcePath == "" {
+		return errors.New("source path can not be empty")
+	}
+
+	if cmd.Mode != ImportModeMerge && cmd.Mode != ImportModeReplace {
+		return errors.New("invalid import mode")
+	}
+
+	if err := h.boltFileImporter.ImportBoltFile(cmd.SourcePath, cmd.Path, cmd.Mode); err != nil {
+		return errors.Wrap(err, "could not import the file")
+	}
+
+	h.changePublisher.Publish(Change{
+		Path:      cmd.Path,
+		Operation: ChangeOperationImport,
+	})
+
+	return nil
+}
@@ -0,0 +1,125 @@
+package application
+
+import (
+	"io"
+
+	"github.com/boreq/errors"
+)
+
+type ImportMode int
+
+const (
+	// ImportModeMerge adds the imported keys to the bucket overwriting the
+	// existing values but leaving the remaining keys in place.
+	ImportModeMerge ImportMode = iota
+
+	// ImportModeReplace removes the contents of the bucket before importing
+	// the keys.
+	ImportModeReplace
+)
+
+type ImportFormat int
+
+const (
+	// ImportFormatJSON reads the format produced by ExportBucket.
+	ImportFormatJSON ImportFormat = iota
+
+	// ImportFormatCSV reads the format produced by ExportBucketCSV.
+	ImportFormatCSV
+)
+
+// ImportedEntry is a value or a bucket decoded from the imported data.
+// Buckets always have a non-nil list of entries.
+type ImportedEntry struct {
+	Key    []byte
+	Value  []byte
+	Bucket []ImportedEntry
+}
+
+func (e ImportedEntry) IsBucket() bool {
+	return e.Bucket != nil
+}
+
+type ImportBucket struct {
+	Path   []Key
+	Reader io.Reader
+	Mode   ImportMode
+	Format ImportFormat
+
+	// DryRun validates the input and returns the summary without making
+	// any changes.
+	DryRun bool
+}
+
+// ImportSummary describes the effects of an import.
+type ImportSummary struct {
+	// Created is the number of values and buckets which don't exist yet.
+	Created int
+
+	// Overwritten is the number of existing values which are replaced by
+	// the imported values.
+	Overwritten int
+
+	// Errors lists the problems which prevent the import from succeeding,
+	// only reported during a dry run.
+	Errors []string
+}
+
+type ImportBucketHandler struct {
+	transactionProvider TransactionProvider
+	changePublisher     ChangePublisher
+	importDecoder       ImportDecoder
+}
+
+func NewImportBucketHandler(transactionProvider TransactionProvider, changePublisher ChangePublisher, importDecoder ImportDecoder) *ImportBucketHandler {
+	return &ImportBucketHandler{
+		transactionProvider: transactionProvider,
+		changePublisher:     changePublisher,
+		importDecoder:       importDecoder,
+	}
+}
+
+func (h *ImportBucketHandler) Execute(cmd ImportBucket) (summary ImportSummary, err error) {
+	if cmd.Mode != ImportModeMerge && cmd.Mode != ImportModeReplace {
+		return ImportSummary{}, errors.New("invalid import mode")
+	}
+
+	if cmd.Format != ImportFormatJSON && cmd.Format != ImportFormatCSV {
+		return ImportSummary{}, errors.New("invalid import format")
+	}
+
+	// The input is decoded before opening the transaction as it may take a
+	// long time to receive it.
+	entries, err := h.importDecoder.Decode(cmd.Reader, cmd.Format)
+	if err != nil {
+		if cmd.DryRun {
+			return ImportSummary{Errors: []string{err.Error()}}, nil
+		}
+		return ImportSummary{}, errors.Wrap(err, "could not decode the input")
+	}
+
+	transaction := h.transactionProvider.Write
+	if cmd.DryRun {
+		transaction = h.transactionProvider.Read
+	}
+
+	if err := transaction(func(adapters *TransactableAdapters) error {
+		summary, err = adapters.Database.Import(cmd.Path, entries, cmd.Mode, cmd.DryRun)
+		if err != nil {
+			return errors.Wrap(err, "could not import the bucket")
+		}
+
+		return nil
+	}); err != nil {
+		return ImportSummary{}, errors.Wrap(err, "transaction failed")
+	}
+
+	if !cmd.DryRun {
+		h.changePublisher.Publish(Change{
+			Path:      cmd.Path,
+			Operation: ChangeOperationImport,
+		})
+	}
+
+	return summary, nil
+}
@@ -0,0 +1,44 @@
+package application
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/boreq/errors"
+)
+
+type ListAuditEntries struct {
+	// Since excludes the entries recorded before this time, zero includes
+	// all entries.
+	Since time.Time
+
+	// Before excludes the entries with this or a higher ID so that the
+	// older entries can be retrieved by passing the ID of the last
+	// returned entry, zero starts with the newest entry.
+	Before uint64
+
+	Limit int
+}
+
+type ListAuditEntriesHandler struct {
+	auditLog AuditLog
+}
+
+func NewListAuditEntriesHandler(auditLog AuditLog) *ListAuditEntriesHandler {
+	return &ListAuditEntriesHandler{
+		auditLog: auditLog,
+	}
+}
+
+func (h *ListAuditEntriesHandler) Execute(query ListAuditEntries) ([]AuditEntry, error) {
+	if query.Limit <= 0 || query.Limit > MaxListKeysLimit {
+		return nil, fmt.Errorf("limit must be between 1 and %d", MaxListKeysLimit)
+	}
+
+	entries, err := h.auditLog.ListAuditEntries(query.Since, query.Before, query.Limit)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not list the audit entries")
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,46 @@
+package application
+
+import (
+	"github.com/boreq/errors"
+)
+
+type ListBuckets struct {
+	Path []Key
+}
+
+type ListBucketsHandler struct {
+	transactionProvider TransactionProvider
+	cache               Cache
+}
+
+func NewListBucketsHandler(transactionProvider TransactionProvider, cache Cache) *ListBucketsHandler {
+	return &ListBucketsHandler{
+		transactionProvider: transactionProvider,
+		cache:               cache,
+	}
+}
+
+func (h *ListBucketsHandler) Execute(query ListBuckets) ([]Key, error) {
+	v, err := h.cache.GetOrLoad("listBuckets", query.Path, func() (interface{}, error) {
+		return h.listBuckets(query)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.([]Key), nil
+}
+
+func (h *ListBucketsHandler) listBuckets(query ListBuckets) (buckets []Key, err error) {
+	if err := h.transactionProvider.Read(func(adapters *TransactableAdapters) error {
+		buckets, err = adapters.Database.ListBuckets(query.Path)
+		if err != nil {
+			return errors.Wrap(err, "could not list the buckets")
+		}
+
+		return nil
+	}); err != nil {
+		return nil, errors.Wrap(err, "transaction failed")
+	}
+
+	return buckets, nil
+}
@@ -0,0 +1,123 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/boreq/errors"
+)
+
+const MaxListKeysLimit = 1000
+
+type ListKeysOrder int
+
+const (
+	// ListKeysOrderKeyAscending returns the keys in the byte order in which
+	// they are stored.
+	ListKeysOrderKeyAscending ListKeysOrder = iota
+
+	// ListKeysOrderKeyDescending returns the keys in the reverse byte order.
+	// The next pages contain the keys preceding the last returned key.
+	ListKeysOrderKeyDescending
+
+	// ListKeysOrderSizeAscending sorts the keys by the sizes of their values
+	// with buckets placed first. Only the keys within the page are sorted,
+	// the pages are still retrieved in the byte order. Requires the sizes.
+	ListKeysOrderSizeAscending
+
+	// ListKeysOrderSizeDescending works like ListKeysOrderSizeAscending
+	// but places the largest values first and buckets last.
+	ListKeysOrderSizeDescending
+)
+
+type ListKeys struct {
+	// Context stops the operation once it is done.
+	Context context.Context
+
+	Path  []Key
+	After *Key
+	Limit int
+
+	// Count requests the total number of keys in the bucket which is
+	// expensive for large buckets.
+	Count bool
+
+	// Sizes requests the sizes of the values.
+	Sizes bool
+
+	// Order of the returned keys. Orders other than the byte order require
+	// sorting the entire page in memory which is why they are limited to
+	// sorting within the page which can contain at most MaxListKeysLimit
+	// keys.
+	Order ListKeysOrder
+}
+
+type ListKeysHandler struct {
+	transactionProvider TransactionProvider
+}
+
+func NewListKeysHandler(transactionProvider TransactionProvider) *ListKeysHandler {
+	return &ListKeysHandler{
+		transactionProvider: transactionProvider,
+	}
+}
+
+func (h *ListKeysHandler) Execute(query ListKeys) (page KeysPage, err error) {
+	if query.Context == nil {
+		return page, errors.New("context is nil")
+	}
+
+	if query.Limit <= 0 || query.Limit > MaxListKeysLimit {
+		return page, fmt.Errorf("limit must be between 1 and %d", MaxListKeysLimit)
+	}
+
+	sortBySize := query.Order == ListKeysOrderSizeAscending || query.Order == ListKeysOrderSizeDescending
+	if sortBySize && !query.Sizes {
+		return page, errors.New("sorting by size requires the sizes")
+	}
+
+	if err := h.transactionProvider.Read(func(adapters *TransactableAdapters) error {
+		page, err = adapters.Database.ListKeys(query.Path, query.After, query.Limit, query.Sizes, query.Order == ListKeysOrderKeyDescending)
+		if err != nil {
+			return errors.Wrap(err, "could not list the keys")
+		}
+
+		if sortBySize {
+			sortKeysBySize(page.Keys, query.Order == ListKeysOrderSizeDescending)
+		}
+
+		if query.Count {
+			total, err := adapters.Database.CountKeys(query.Context, query.Path)
+			if err != nil {
+				return errors.Wrap(err, "could not count the keys")
+			}
+			page.Total = &total
+		}
+
+		return nil
+	}); err != nil {
+		return page, errors.Wrap(err, "transaction failed")
+	}
+
+	return page, nil
+}
+
+// sortKeysBySize sorts the keys by the sizes of their values. Buckets are
+// treated as smaller than any value. Keys with equal sizes remain in the byte
+// order.
+func sortKeysBySize(keys []KeyInfo, descending bool) {
+	size := func(keyInfo KeyInfo) int {
+		if keyInfo.Size == nil {
+			return -1
+		}
+		return *keyInfo.Size
+	}
+
+	sort.SliceStable(keys, func(i, j int) bool {
+		if descending {
+			return size(keys[i]) > size(keys[j])
+		}
+		return size(keys[i]) < size(keys[j])
+	})
+}
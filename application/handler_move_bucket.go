@@ -0,0 +1,105 @@
+package application
+
+import (
+	"bytes"
+
+	"github.com/boreq/errors"
+)
+
+type MoveBucket struct {
+	Path []Key
+
+	// DestinationParent specifies the bucket in which the bucket is placed,
+	// an empty path refers to the root.
+	DestinationParent []Key
+
+	// Name is the new name of the bucket.
+	Name Key
+}
+
+type MoveBucketHandler struct {
+	transactionProvider TransactionProvider
+	changePublisher     ChangePublisher
+}
+
+func NewMoveBucketHandler(transactionProvider TransactionProvider, changePublisher ChangePublisher) *MoveBucketHandler {
+	return &MoveBucketHandler{
+		transactionProvider: transactionProvider,
+		changePublisher:     changePublisher,
+	}
+}
+
+// Execute moves or renames the bucket in a single transaction. Returns
+// ErrMoveIntoDescendant if the destination is inside of the moved bucket and
+// ErrBucketNotFound if the bucket doesn't exist, even if the destination is
+// the same as the source.
+func (h *MoveBucketHandler) Execute(cmd MoveBucket) error {
+	if len(cmd.Path) == 0 {
+		return errors.New("root can not be moved")
+	}
+
+	if cmd.Name.IsZero() {
+		return errors.New("name can not be empty")
+	}
+
+	destination := append(append([]Key{}, cmd.DestinationParent...), cmd.Name)
+
+	if samePath(cmd.Path, destination) {
+		// Moving a bucket onto itself does nothing but it still has to
+		// fail if the bucket doesn't exist.
+		if err := h.transactionProvider.Read(func(adapters *TransactableAdapters) error {
+			if _, err := adapters.Database.ListBuckets(cmd.Path); err != nil {
+				return errors.Wrap(err, "could not get the bucket")
+			}
+
+			return nil
+		}); err != nil {
+			return errors.Wrap(err, "transaction failed")
+		}
+
+		return nil
+	}
+
+	if isPathPrefix(cmd.Path, cmd.DestinationParent) {
+		return ErrMoveIntoDescendant
+	}
+
+	if err := h.transactionProvider.Write(func(adapters *TransactableAdapters) error {
+		if err := adapters.Database.MoveBucket(cmd.Path, cmd.DestinationParent, cmd.Name); err != nil {
+			return errors.Wrap(err, "could not move the bucket")
+		}
+
+		return nil
+	}); err != nil {
+		return errors.Wrap(err, "transaction failed")
+	}
+
+	h.changePublisher.Publish(Change{
+		Path:      cmd.DestinationParent,
+		Key:       &cmd.Name,
+		Operation: ChangeOperationCreateBucket,
+	})
+
+	h.changePublisher.Publish(Change{
+		Path:      cmd.Path[:len(cmd.Path)-1],
+		Key:       &cmd.Path[len(cmd.Path)-1],
+		Operation: ChangeOperationDeleteBucket,
+	})
+
+	return nil
+}
+
+// isPathPrefix returns true if path starts with prefix.
+func isPathPrefix(prefix, path []Key) bool {
+	if len(prefix) > len(path) {
+		return false
+	}
+
+	for i := range prefix {
+		if !bytes.Equal(prefix[i].Bytes(), path[i].Bytes()) {
+			return false
+		}
+	}
+
+	return true
+}
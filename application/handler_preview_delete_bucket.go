@@ -0,0 +1,95 @@
+package application
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+
+	"github.com/boreq/errors"
+)
+
+// deleteBucketPreviewSampleSize is the number of keys of the bucket included
+// in the preview.
+const deleteBucketPreviewSampleSize = 5
+
+type PreviewDeleteBucket struct {
+	// Context stops the operation once it is done.
+	Context context.Context
+
+	Path []Key
+}
+
+// DeleteBucketPreview describes what would be removed together with the
+// bucket. The confirmation has to be passed to DeleteBucket to delete the
+// bucket only if its contents didn't change since the preview.
+type DeleteBucketPreview struct {
+	Contents     BucketContents
+	Sample       []KeyInfo
+	Confirmation string
+}
+
+type PreviewDeleteBucketHandler struct {
+	transactionProvider TransactionProvider
+}
+
+func NewPreviewDeleteBucketHandler(transactionProvider TransactionProvider) *PreviewDeleteBucketHandler {
+	return &PreviewDeleteBucketHandler{
+		transactionProvider: transactionProvider,
+	}
+}
+
+func (h *PreviewDeleteBucketHandler) Execute(query PreviewDeleteBucket) (preview DeleteBucketPreview, err error) {
+	if query.Context == nil {
+		return preview, errors.New("context is nil")
+	}
+
+	if len(query.Path) == 0 {
+		return preview, errors.New("path can not be empty")
+	}
+
+	if err := h.transactionProvider.Read(func(adapters *TransactableAdapters) error {
+		contents, err := adapters.Database.CountBucketContents(query.Context, query.Path)
+		if err != nil {
+			return errors.Wrap(err, "could not count the bucket contents")
+		}
+
+		page, err := adapters.Database.ListKeys(query.Path, nil, deleteBucketPreviewSampleSize, false, false)
+		if err != nil {
+			return errors.Wrap(err, "could not list the keys")
+		}
+
+		preview = DeleteBucketPreview{
+			Contents:     contents,
+			Sample:       page.Keys,
+			Confirmation: deleteBucketConfirmation(query.Path, contents),
+		}
+
+		return nil
+	}); err != nil {
+		return preview, errors.Wrap(err, "transaction failed")
+	}
+
+	return preview, nil
+}
+
+// deleteBucketConfirmation derives the confirmation from the path and the
+// number of removed entries so that it stops matching if the bucket is
+// modified in a way which changes what the user agreed to remove.
+func deleteBucketConfirmation(path []Key, contents BucketContents) string {
+	h := sha256.New()
+
+	buf := make([]byte, 8)
+	for _, key := range path {
+		binary.BigEndian.PutUint64(buf, uint64(len(key.Bytes())))
+		h.Write(buf)
+		h.Write(key.Bytes())
+	}
+
+	binary.BigEndian.PutUint64(buf, uint64(contents.Values))
+	h.Write(buf)
+	binary.BigEndian.PutUint64(buf, uint64(contents.Buckets))
+	h.Write(buf)
+
+	return hex.EncodeToString(h.Sum(nil))
+}
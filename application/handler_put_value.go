@@ -0,0 +1,72 @@
+package application
+
+import (
+	"github.com/boreq/errors"
+)
+
+type PutValue struct {
+	Path  []Key
+	Key   Key
+	Value Value
+
+	// ExpectedETag, if not empty, has to be equal to the ETag of the value
+	// currently stored under the key. Otherwise ErrValueChanged is returned
+	// and the value is not modified.
+	ExpectedETag string
+}
+
+type PutValueHandler struct {
+	transactionProvider TransactionProvider
+	changePublisher     ChangePublisher
+	maxValueSize        MaxValueSize
+}
+
+func NewPutValueHandler(transactionProvider TransactionProvider, changePublisher ChangePublisher, maxValueSize MaxValueSize) *PutValueHandler {
+	return &PutValueHandler{
+		transactionProvider: transactionProvider,
+		changePublisher:     changePublisher,
+		maxValueSize:        maxValueSize,
+	}
+}
+
+func (h *PutValueHandler) Execute(cmd PutValue) error {
+	if len(cmd.Path) == 0 {
+		return errors.New("values can not be stored in the root of the database")
+	}
+
+	if err := h.maxValueSize.Check(cmd.Value); err != nil {
+		return err
+	}
+
+	if err := h.transactionProvider.Write(func(adapters *TransactableAdapters) error {
+		if cmd.ExpectedETag != "" {
+			current, err := adapters.Database.GetValue(cmd.Path, cmd.Key)
+			if err != nil {
+				if errors.Is(err, ErrKeyNotFound) {
+					return ErrValueChanged
+				}
+				return errors.Wrap(err, "could not get the current value")
+			}
+
+			if current.ETag() != cmd.ExpectedETag {
+				return ErrValueChanged
+			}
+		}
+
+		if err := adapters.Database.PutValue(cmd.Path, cmd.Key, cmd.Value); err != nil {
+			return errors.Wrap(err, "could not put the value")
+		}
+
+		return nil
+	}); err != nil {
+		return errors.Wrap(err, "transaction failed")
+	}
+
+	h.changePublisher.Publish(Change{
+		Path:      cmd.Path,
+		Key:       &cmd.Key,
+		Operation: ChangeOperationPutValue,
+	})
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package application
+
+import (
+	"bytes"
+
+	"github.com/boreq/errors"
+)
+
+type RenameKey struct {
+	Path   []Key
+	OldKey Key
+	NewKey Key
+
+	// Overwrite allows replacing a value which is already stored under the
+	// new key, otherwise ErrValueExists is returned.
+	Overwrite bool
+}
+
+type RenameKeyHandler struct {
+	transactionProvider TransactionProvider
+	changePublisher     ChangePublisher
+}
+
+func NewRenameKeyHandler(transactionProvider TransactionProvider, changePublisher ChangePublisher) *RenameKeyHandler {
+	return &RenameKeyHandler{
+		transactionProvider: transactionProvider,
+		changePublisher:     changePublisher,
+	}
+}
+
+// Execute moves the value to the new key in a single transaction.
+func (h *RenameKeyHandler) Execute(cmd RenameKey) error {
+	if len(cmd.Path) == 0 {
+		return errors.New("values can not be stored in the root of the database")
+	}
+
+	if cmd.OldKey.IsZero() || cmd.NewKey.IsZero() {
+		return errors.New("keys can not be empty")
+	}
+
+	if bytes.Equal(cmd.OldKey.Bytes(), cmd.NewKey.Bytes()) {
+		return errors.New("old and new keys are the same")
+	}
+
+	if err := h.transactionProvider.Write(func(adapters *TransactableAdapters) error {
+		value, err := adapters.Database.GetValue(cmd.Path, cmd.OldKey)
+		if err != nil {
+			return errors.Wrap(err, "could not get the value")
+		}
+
+		if _, err := adapters.Database.GetValue(cmd.Path, cmd.NewKey); err != nil {
+			if !errors.Is(err, ErrKeyNotFound) {
+				return errors.Wrap(err, "could not check the new key")
+			}
+		} else if !cmd.Overwrite {
+			return ErrValueExists
+		}
+
+		if err := adapters.Database.PutValue(cmd.Path, cmd.NewKey, value); err != nil {
+			return errors.Wrap(err, "could not put the value")
+		}
+
+		if err := adapters.Database.DeleteKey(cmd.Path, cmd.OldKey); err != nil {
+			return errors.Wrap(err, "could not delete the old key")
+		}
+
+		return nil
+	}); err != nil {
+		return errors.Wrap(err, "transaction failed")
+	}
+
+	h.changePublisher.Publish(Change{
+		Path:      cmd.Path,
+		Key:       &cmd.NewKey,
+		Operation: ChangeOperationPutValue,
+	})
+
+	h.changePublisher.Publish(Change{
+		Path:      cmd.Path,
+		Key:       &cmd.OldKey,
+		Operation: ChangeOperationDeleteKey,
+	})
+
+	return nil
+}
@@ -0,0 +1,34 @@
+package application
+
+import (
+	"github.com/boreq/errors"
+)
+
+type ResolvePath struct {
+	Path []Key
+}
+
+type ResolvePathHandler struct {
+	transactionProvider TransactionProvider
+}
+
+func NewResolvePathHandler(transactionProvider TransactionProvider) *ResolvePathHandler {
+	return &ResolvePathHandler{
+		transactionProvider: transactionProvider,
+	}
+}
+
+func (h *ResolvePathHandler) Execute(query ResolvePath) (info PathInfo, err error) {
+	if err := h.transactionProvider.Read(func(adapters *TransactableAdapters) error {
+		info, err = adapters.Database.ResolvePath(query.Path)
+		if err != nil {
+			return errors.Wrap(err, "could not resolve the path")
+		}
+
+		return nil
+	}); err != nil {
+		return PathInfo{}, errors.Wrap(err, "transaction failed")
+	}
+
+	return info, nil
+}
@@ -0,0 +1,95 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/boreq/errors"
+)
+
+type SearchAllBuckets struct {
+	// Context stops the search once it is done.
+	Context context.Context
+
+	// Pattern is matched against the keys. If the pattern contains the
+	// wildcards * or ? it is a glob matched against the entire key,
+	// otherwise keys containing the pattern are returned.
+	Pattern string
+
+	Limit int
+}
+
+type SearchAllBucketsHandler struct {
+	transactionProvider TransactionProvider
+}
+
+func NewSearchAllBucketsHandler(transactionProvider TransactionProvider) *SearchAllBucketsHandler {
+	return &SearchAllBucketsHandler{
+		transactionProvider: transactionProvider,
+	}
+}
+
+func (h *SearchAllBucketsHandler) Execute(query SearchAllBuckets) (hits []SearchHit, err error) {
+	if query.Context == nil {
+		return nil, errors.New("context is nil")
+	}
+
+	if query.Pattern == "" {
+		return nil, errors.New("pattern can not be empty")
+	}
+
+	if query.Limit <= 0 || query.Limit > MaxListKeysLimit {
+		return nil, fmt.Errorf("limit must be between 1 and %d", MaxListKeysLimit)
+	}
+
+	matcher, err := newKeyMatcher(query.Pattern)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid pattern")
+	}
+
+	if err := h.transactionProvider.Read(func(adapters *TransactableAdapters) error {
+		hits, err = adapters.Database.SearchAllBuckets(query.Context, matcher, query.Limit)
+		if err != nil {
+			return errors.Wrap(err, "could not search the buckets")
+		}
+
+		return nil
+	}); err != nil {
+		return nil, errors.Wrap(err, "transaction failed")
+	}
+
+	return hits, nil
+}
+
+func newKeyMatcher(pattern string) (KeyMatcher, error) {
+	if !strings.ContainsAny(pattern, "*?") {
+		substring := []byte(pattern)
+		return func(key []byte) bool {
+			return bytes.Contains(key, substring)
+		}, nil
+	}
+
+	var expression strings.Builder
+	expression.WriteString(`(?s)^`)
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			expression.WriteString(`.*`)
+		case '?':
+			expression.WriteString(`.`)
+		default:
+			expression.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	expression.WriteString(`$`)
+
+	re, err := regexp.Compile(expression.String())
+	if err != nil {
+		return nil, errors.Wrap(err, "could not compile the expression")
+	}
+
+	return re.Match, nil
+}
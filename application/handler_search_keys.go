@@ -0,0 +1,42 @@
+package application
+
+import (
+	"fmt"
+
+	"github.com/boreq/errors"
+)
+
+type SearchKeys struct {
+	Path   []Key
+	Prefix []byte
+	Limit  int
+}
+
+type SearchKeysHandler struct {
+	transactionProvider TransactionProvider
+}
+
+func NewSearchKeysHandler(transactionProvider TransactionProvider) *SearchKeysHandler {
+	return &SearchKeysHandler{
+		transactionProvider: transactionProvider,
+	}
+}
+
+func (h *SearchKeysHandler) Execute(query SearchKeys) (keys []KeyInfo, err error) {
+	if query.Limit <= 0 || query.Limit > MaxListKeysLimit {
+		return nil, fmt.Errorf("limit must be between 1 and %d", MaxListKeysLimit)
+	}
+
+	if err := h.transactionProvider.Read(func(adapters *TransactableAdapters) error {
+		keys, err = adapters.Database.SearchKeysByPrefix(query.Path, query.Prefix, query.Limit)
+		if err != nil {
+			return errors.Wrap(err, "could not search the keys")
+		}
+
+		return nil
+	}); err != nil {
+		return nil, errors.Wrap(err, "transaction failed")
+	}
+
+	return keys, nil
+}
@@ -0,0 +1,209 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/boreq/errors"
+)
+
+// snippetContext is the number of bytes included in a snippet on each side
+// of the matching fragment of a value.
+const snippetContext = 32
+
+type ValueQuery struct {
+	// Substring is searched for in text values and in the serialized form
+	// of JSON values.
+	Substring string
+
+	// JSONPath selects an element of JSON values using dot separated
+	// object keys and array indexes e.g. "users.0.name". If it is set then
+	// only JSON values containing the element are matched and Substring,
+	// if present, is searched for only in the serialized form of the
+	// element.
+	JSONPath string
+
+	// IncludeBinary makes Substring match binary values as well, otherwise
+	// they are skipped.
+	IncludeBinary bool
+}
+
+type SearchValues struct {
+	// Context stops the search once it is done.
+	Context context.Context
+
+	Path   []Key
+	Query  ValueQuery
+	Limit  int
+	Writer SearchHitWriter
+}
+
+type SearchValuesHandler struct {
+	transactionProvider TransactionProvider
+}
+
+func NewSearchValuesHandler(transactionProvider TransactionProvider) *SearchValuesHandler {
+	return &SearchValuesHandler{
+		transactionProvider: transactionProvider,
+	}
+}
+
+func (h *SearchValuesHandler) Execute(query SearchValues) error {
+	if query.Context == nil {
+		return errors.New("context is nil")
+	}
+
+	if query.Writer == nil {
+		return errors.New("writer is nil")
+	}
+
+	if query.Limit <= 0 || query.Limit > MaxListKeysLimit {
+		return fmt.Errorf("limit must be between 1 and %d", MaxListKeysLimit)
+	}
+
+	matcher, err := newValueMatcher(query.Query)
+	if err != nil {
+		return errors.Wrap(err, "invalid query")
+	}
+
+	if err := h.transactionProvider.Read(func(adapters *TransactableAdapters) error {
+		if err := adapters.Database.SearchValues(query.Context, query.Path, matcher, query.Limit, query.Writer); err != nil {
+			return errors.Wrap(err, "could not search the values")
+		}
+
+		return nil
+	}); err != nil {
+		return errors.Wrap(err, "transaction failed")
+	}
+
+	return nil
+}
+
+func newValueMatcher(query ValueQuery) (ValueMatcher, error) {
+	if query.Substring == "" && query.JSONPath == "" {
+		return nil, errors.New("substring or JSON path must be set")
+	}
+
+	substring := []byte(query.Substring)
+
+	if query.JSONPath != "" {
+		jsonPath := parseJSONPath(query.JSONPath)
+
+		return func(value []byte) (string, bool) {
+			if DetectValueType(value) != ValueTypeJSON {
+				return "", false
+			}
+
+			element, ok := findJSONElement(value, jsonPath)
+			if !ok {
+				return "", false
+			}
+
+			return matchSubstring(element, substring, false)
+		}, nil
+	}
+
+	return func(value []byte) (string, bool) {
+		binary := DetectValueType(value) == ValueTypeBinary
+		if binary && !query.IncludeBinary {
+			return "", false
+		}
+
+		return matchSubstring(value, substring, binary)
+	}, nil
+}
+
+// matchSubstring returns a snippet surrounding the first occurrence of the
+// substring, an empty substring matches the beginning of the value. Snippets
+// of binary values are hex encoded.
+func matchSubstring(value []byte, substring []byte, binary bool) (string, bool) {
+	i := bytes.Index(value, substring)
+	if i < 0 {
+		return "", false
+	}
+
+	start := i - snippetContext
+	if start < 0 {
+		start = 0
+	}
+
+	end := i + len(substring) + snippetContext
+	if end > len(value) {
+		end = len(value)
+	}
+
+	if binary {
+		return hex.EncodeToString(value[start:end]), true
+	}
+
+	// Avoid splitting multi-byte characters.
+	for start > 0 && !utf8.RuneStart(value[start]) {
+		start--
+	}
+	for end < len(value) && !utf8.RuneStart(value[end]) {
+		end++
+	}
+
+	snippet := string(value[start:end])
+	if start > 0 {
+		snippet = "…" + snippet
+	}
+	if end < len(value) {
+		snippet = snippet + "…"
+	}
+
+	return snippet, true
+}
+
+func parseJSONPath(s string) []string {
+	s = strings.TrimPrefix(s, "$")
+	s = strings.TrimPrefix(s, ".")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ".")
+}
+
+// findJSONElement returns the serialized element of the JSON document
+// specified by the path.
+func findJSONElement(document []byte, path []string) ([]byte, bool) {
+	decoder := json.NewDecoder(bytes.NewReader(document))
+	decoder.UseNumber()
+
+	var element interface{}
+	if err := decoder.Decode(&element); err != nil {
+		return nil, false
+	}
+
+	for _, segment := range path {
+		switch v := element.(type) {
+		case map[string]interface{}:
+			child, ok := v[segment]
+			if !ok {
+				return nil, false
+			}
+			element = child
+		case []interface{}:
+			i, err := strconv.Atoi(segment)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil, false
+			}
+			element = v[i]
+		default:
+			return nil, false
+		}
+	}
+
+	b, err := json.Marshal(element)
+	if err != nil {
+		return nil, false
+	}
+
+	return b, true
+}
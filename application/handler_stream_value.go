@@ -0,0 +1,37 @@
+package application
+
+import (
+	"github.com/boreq/errors"
+)
+
+type StreamValue struct {
+	Path   []Key
+	Key    Key
+	Writer ValueWriter
+}
+
+type StreamValueHandler struct {
+	transactionProvider TransactionProvider
+}
+
+func NewStreamValueHandler(transactionProvider TransactionProvider) *StreamValueHandler {
+	return &StreamValueHandler{
+		transactionProvider: transactionProvider,
+	}
+}
+
+// Execute calls the writer while the transaction is still open which makes it
+// possible to write out large values without copying them first.
+func (h *StreamValueHandler) Execute(query StreamValue) error {
+	if err := h.transactionProvider.Read(func(adapters *TransactableAdapters) error {
+		if err := adapters.Database.StreamValue(query.Path, query.Key, query.Writer); err != nil {
+			return errors.Wrap(err, "could not stream the value")
+		}
+
+		return nil
+	}); err != nil {
+		return errors.Wrap(err, "transaction failed")
+	}
+
+	return nil
+}
@@ -0,0 +1,21 @@
+package application
+
+type SubscribeToChanges struct {
+	Path []Key
+}
+
+type SubscribeToChangesHandler struct {
+	changeSubscriber ChangeSubscriber
+}
+
+func NewSubscribeToChangesHandler(changeSubscriber ChangeSubscriber) *SubscribeToChangesHandler {
+	return &SubscribeToChangesHandler{
+		changeSubscriber: changeSubscriber,
+	}
+}
+
+// Execute returns a channel receiving the changes related to the specified
+// path and a function which has to be called to unsubscribe.
+func (h *SubscribeToChangesHandler) Execute(query SubscribeToChanges) (<-chan Change, func()) {
+	return h.changeSubscriber.Subscribe(query.Path)
+}
@@ -0,0 +1,37 @@
+package application
+
+import (
+	"encoding/binary"
+)
+
+// uint64Size is the length of the integers encoded using binary.BigEndian
+// which is a common way of storing auto-incremented identifiers in bolt. Keys
+// encoded this way are sorted numerically by bolt.
+const uint64Size = 8
+
+// NewUint64Key returns a key containing the number encoded as 8 big-endian
+// bytes.
+func NewUint64Key(n uint64) Key {
+	b := make([]byte, uint64Size)
+	binary.BigEndian.PutUint64(b, n)
+	return Key{b}
+}
+
+// Uint64 interprets the key as a big-endian unsigned integer. False is
+// returned if the key isn't 8 bytes long.
+func (k Key) Uint64() (uint64, bool) {
+	return decodeUint64(k.b)
+}
+
+// Uint64 interprets the value as a big-endian unsigned integer. False is
+// returned if the value isn't 8 bytes long.
+func (v Value) Uint64() (uint64, bool) {
+	return decodeUint64(v.b)
+}
+
+func decodeUint64(b []byte) (uint64, bool) {
+	if len(b) != uint64Size {
+		return 0, false
+	}
+	return binary.BigEndian.Uint64(b), true
+}
@@ -0,0 +1,13 @@
+package application
+
+// MaxValueSize is the maximum size of the values written using this program
+// in bytes, zero means that the size is not limited.
+type MaxValueSize int
+
+// Check returns ErrValueTooLarge if the value exceeds the limit.
+func (m MaxValueSize) Check(value Value) error {
+	if m > 0 && len(value.Bytes()) > int(m) {
+		return ErrValueTooLarge
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package application
+
+import (
+	"bytes"
+	"encoding/json"
+	"unicode/utf8"
+)
+
+type ValueType int
+
+const (
+	ValueTypeBinary ValueType = iota
+	ValueTypeText
+	ValueTypeJSON
+)
+
+// DetectValueType checks if the value is a valid JSON document, falls back to
+// checking if it is a valid UTF-8 string and otherwise considers the value to
+// be binary. Empty values are considered to be text.
+func DetectValueType(value []byte) ValueType {
+	if json.Valid(value) {
+		return ValueTypeJSON
+	}
+
+	if utf8.Valid(value) {
+		return ValueTypeText
+	}
+
+	return ValueTypeBinary
+}
+
+// FormatJSON re-indents the value if DetectValueType considers it to be JSON.
+// Object keys are sorted to make the output stable. Values which aren't JSON
+// are returned unchanged together with false.
+func FormatJSON(value []byte) ([]byte, bool) {
+	if DetectValueType(value) != ValueTypeJSON {
+		return value, false
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(value))
+	decoder.UseNumber()
+
+	var v interface{}
+	if err := decoder.Decode(&v); err != nil {
+		return value, false
+	}
+
+	buf := &bytes.Buffer{}
+
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(v); err != nil {
+		return value, false
+	}
+
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), true
+}
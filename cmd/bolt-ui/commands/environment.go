@@ -0,0 +1,112 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/boreq/guinea"
+)
+
+// EnvironmentPrefix is prepended to the names of the environment variables
+// corresponding to the command options.
+const EnvironmentPrefix = "BOLTUI_"
+
+// EnvironmentDatabases is used to specify the databases if no arguments are
+// given. Multiple databases are separated using commas.
+const EnvironmentDatabases = EnvironmentPrefix + "DATABASES"
+
+// ApplyEnvironment replaces the defaults of the options with the values of
+// the corresponding environment variables e.g. the default of the option
+// open-timeout is read from BOLTUI_OPEN_TIMEOUT. This means that the environment
+// variables take precedence over the defaults but explicitly provided flags
+// take precedence over the environment variables. The defaults listed in the
+// descriptions of the options are updated so that the help shows the values
+// which will be used.
+func ApplyEnvironment(cmd *guinea.Command) error {
+	for i := range cmd.Options {
+		option := &cmd.Options[i]
+
+		variable := EnvironmentVariable(option.Name)
+		value, ok := os.LookupEnv(variable)
+		if !ok {
+			continue
+		}
+
+		switch option.Type {
+		case guinea.String:
+			option.Default = value
+		case guinea.Bool:
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("invalid environment variable %s: '%s' is not a boolean", variable, value)
+			}
+			option.Default = b
+		case guinea.Int:
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("invalid environment variable %s: '%s' is not an integer", variable, value)
+			}
+			option.Default = n
+		}
+
+		option.Description = describeEnvironmentDefault(option.Description, variable, value)
+	}
+
+	for _, subCmd := range cmd.Subcommands {
+		if err := ApplyEnvironment(subCmd); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// describeEnvironmentDefault replaces the default at the end of the
+// description with the value of the environment variable.
+func describeEnvironmentDefault(description, variable, value string) string {
+	const defaultPrefix = "Default: "
+
+	if i := strings.LastIndex(description, defaultPrefix); i >= 0 {
+		description = strings.TrimRight(description[:i], " ")
+	} else if description != "" {
+		description += "."
+	}
+
+	if description != "" {
+		description += " "
+	}
+
+	return fmt.Sprintf("%s%s%s (set by %s)", description, defaultPrefix, value, variable)
+}
+
+// EnvironmentVariable returns the name of the environment variable
+// corresponding to the option.
+func EnvironmentVariable(optionName string) string {
+	return EnvironmentPrefix + strings.ToUpper(strings.ReplaceAll(optionName, "-", "_"))
+}
+
+// optionSource describes where the value of a string option came from so
+// that the user knows what to fix.
+func optionSource(c guinea.Context, optionName string) string {
+	variable := EnvironmentVariable(optionName)
+	if value, ok := os.LookupEnv(variable); ok && value == c.Options[optionName].Str() {
+		return fmt.Sprintf("environment variable %s", variable)
+	}
+	return fmt.Sprintf("option %s", optionName)
+}
+
+func databaseArguments(c guinea.Context) []string {
+	if len(c.Arguments) > 0 {
+		return c.Arguments
+	}
+
+	var arguments []string
+	for _, argument := range strings.Split(os.Getenv(EnvironmentDatabases), ",") {
+		if argument = strings.TrimSpace(argument); argument != "" {
+			arguments = append(arguments, argument)
+		}
+	}
+	return arguments
+}
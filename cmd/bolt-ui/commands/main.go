@@ -8,25 +8,74 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"crypto/x509/pkix"
-	"encoding/hex"
 	"fmt"
 	"math/big"
 	"net"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/NYTimes/gziphandler"
 	"github.com/boreq/guinea"
+	"github.com/contentforward/bolt-ui/adapters"
 	"github.com/contentforward/bolt-ui/internal/config"
+	"github.com/contentforward/bolt-ui/internal/service"
 	"github.com/contentforward/bolt-ui/internal/wire"
 	"github.com/contentforward/bolt-ui/logging"
+	httpPort "github.com/contentforward/bolt-ui/ports/http"
 	"github.com/pkg/errors"
 )
 
 const (
 	nameAddress       = "address"
 	nameInsecureCORS  = "insecure-cors"
+	nameCORSOrigins   = "cors-origins"
 	nameInsecureToken = "insecure-token"
+	nameBasicAuth     = "basic-auth"
 	nameInsecureTLS   = "insecure-tls"
+	nameReadOnly      = "read-only"
+	nameOpenMode      = "open-mode"
+	nameOpenTimeout   = "open-timeout"
+	nameTLSCert       = "tls-cert"
+	nameTLSKey        = "tls-key"
+	nameTLSMinVersion = "tls-min-version"
+
+	nameBasePath = "base-path"
+
+	nameCreateIfMissing = "create-if-missing"
+
+	nameRedactPaths = "redact-paths"
+
+	nameDisableCompression = "disable-compression"
+	nameCompressionMinSize = "compression-min-size"
+
+	nameMetrics         = "metrics"
+	nameInsecureMetrics = "insecure-metrics"
+	nameMetricsBuckets  = "metrics-buckets"
+
+	nameAccessLog       = "access-log"
+	nameAccessLogBodies = "access-log-bodies"
+
+	nameRateLimit          = "rate-limit"
+	nameRateLimitBurst     = "rate-limit-burst"
+	nameTrustedProxyHeader = "trusted-proxy-header"
+
+	nameAuthBackoff    = "auth-backoff"
+	nameAuthBackoffMax = "auth-backoff-max"
+
+	nameRequestTimeout = "request-timeout"
+
+	nameMaxValueSize  = "max-value-size"
+	nameMaxImportSize = "max-import-size"
+
+	nameValueCompressionThreshold = "value-compression-threshold"
+
+	nameBucketTreeMaxDepth = "bucket-tree-max-depth"
+
+	nameCacheTTL     = "cache-ttl"
+	nameHexDumpLimit = "hex-dump-limit"
+	nameAuditLog     = "audit-log"
 )
 
 var MainCmd = guinea.Command{
@@ -34,17 +83,17 @@ var MainCmd = guinea.Command{
 	Arguments: []guinea.Argument{
 		{
 			Name:        "database",
-			Optional:    false,
-			Multiple:    false,
-			Description: "Path to the database file",
+			Optional:    true,
+			Multiple:    true,
+			Description: "Path to the database file optionally prefixed with a name followed by an equals sign e.g. name=path/to/file. If no databases are given they are read from the comma separated BOLTUI_DATABASES environment variable",
 		},
 	},
 	Options: []guinea.Option{
 		{
 			Name:        nameAddress,
 			Type:        guinea.String,
-			Default:     ":8118",
-			Description: `Specifies listening address. Default: :8118`,
+			Default:     "127.0.0.1:8118",
+			Description: `Specifies listening address in the host:port form, use :8118 to listen on all interfaces or port 0 to pick a free port. Default: 127.0.0.1:8118`,
 		},
 		{
 			Name:        nameInsecureCORS,
@@ -52,27 +101,220 @@ var MainCmd = guinea.Command{
 			Default:     false,
 			Description: "Disables CORS",
 		},
+		{
+			Name:        nameCORSOrigins,
+			Type:        guinea.String,
+			Default:     "",
+			Description: "Comma separated list of origins which are allowed to make cross-origin requests e.g. http://localhost:3000",
+		},
 		{
 			Name:        nameInsecureToken,
 			Type:        guinea.Bool,
 			Default:     false,
 			Description: "Disables token validation",
 		},
+		{
+			Name:        nameBasicAuth,
+			Type:        guinea.Bool,
+			Default:     false,
+			Description: "Accepts the token passed as the basic auth password e.g. curl -u user:token, the user name is ignored",
+		},
 		{
 			Name:        nameInsecureTLS,
 			Type:        guinea.Bool,
 			Default:     false,
 			Description: "Disables serving using TLS",
 		},
+		{
+			Name:        nameTLSCert,
+			Type:        guinea.String,
+			Default:     "",
+			Description: "Path to a PEM encoded TLS certificate file, requires tls-key. By default a self-signed certificate is generated",
+		},
+		{
+			Name:        nameTLSKey,
+			Type:        guinea.String,
+			Default:     "",
+			Description: "Path to a PEM encoded TLS private key file, requires tls-cert",
+		},
+		{
+			Name:        nameTLSMinVersion,
+			Type:        guinea.String,
+			Default:     "1.2",
+			Description: `Minimum accepted TLS version, one of: 1.0, 1.1, 1.2 or 1.3. Default: 1.2`,
+		},
+		{
+			Name:        nameDisableCompression,
+			Type:        guinea.Bool,
+			Default:     false,
+			Description: "Disables compression of responses",
+		},
+		{
+			Name:        nameCompressionMinSize,
+			Type:        guinea.Int,
+			Default:     gziphandler.DefaultMinSize,
+			Description: fmt.Sprintf("Responses smaller than this number of bytes are not compressed. Default: %d", gziphandler.DefaultMinSize),
+		},
+		{
+			Name:        nameMetrics,
+			Type:        guinea.Bool,
+			Default:     false,
+			Description: "Enables the Prometheus metrics endpoint under /metrics",
+		},
+		{
+			Name:        nameInsecureMetrics,
+			Type:        guinea.Bool,
+			Default:     false,
+			Description: "Disables token validation for the metrics endpoint",
+		},
+		{
+			Name:        nameMetricsBuckets,
+			Type:        guinea.String,
+			Default:     "",
+			Description: "Comma separated list of top-level buckets for which the number of keys is reported in the metrics",
+		},
+		{
+			Name:        nameAccessLog,
+			Type:        guinea.String,
+			Default:     "info",
+			Description: "Level at which requests are logged, one of: none, debug, info, warn, error or crit. Failed requests are always logged as warnings or errors. Default: info",
+		},
+		{
+			Name:        nameAccessLogBodies,
+			Type:        guinea.Bool,
+			Default:     false,
+			Description: "Includes request bodies in the access log",
+		},
+		{
+			Name:        nameRateLimit,
+			Type:        guinea.String,
+			Default:     "0",
+			Description: "Average number of requests per second a single client can make, 0 disables rate limiting. Default: 0",
+		},
+		{
+			Name:        nameRateLimitBurst,
+			Type:        guinea.Int,
+			Default:     20,
+			Description: "Maximum number of requests a single client can make at once when rate limiting is enabled. Default: 20",
+		},
+		{
+			Name:        nameTrustedProxyHeader,
+			Type:        guinea.String,
+			Default:     "",
+			Description: "Header set by a trusted proxy used to determine the client address e.g. X-Forwarded-For",
+		},
+		{
+			Name:        nameAuthBackoff,
+			Type:        guinea.String,
+			Default:     "0",
+			Description: "Time for which a client has to wait after failing to authenticate, doubled after each consecutive failure, 0 disables it. Clients are identified by their address so clients behind the same NAT or proxy share the backoff. Default: 0",
+		},
+		{
+			Name:        nameAuthBackoffMax,
+			Type:        guinea.String,
+			Default:     "1m",
+			Description: "Maximum time for which a client has to wait after failing to authenticate. Default: 1m",
+		},
+		{
+			Name:        nameRequestTimeout,
+			Type:        guinea.String,
+			Default:     "1m",
+			Description: "Maximum duration of requests such as exports and searches, 0 disables the limit. Backups and compactions are not limited. Default: 1m",
+		},
+		{
+			Name:        nameMaxValueSize,
+			Type:        guinea.Int,
+			Default:     0,
+			Description: "Maximum size of the values written using this program in bytes, 0 disables the limit. Default: 0",
+		},
+		{
+			Name:        nameMaxImportSize,
+			Type:        guinea.Int,
+			Default:     67108864,
+			Description: "Maximum size of the imported files in bytes, 0 disables the limit. JSON and CSV files are read into memory, Bolt files are written to a temporary file. Default: 67108864",
+		},
+		{
+			Name:        nameValueCompressionThreshold,
+			Type:        guinea.Int,
+			Default:     0,
+			Description: "Values written using this program which are at least this number of bytes long are stored compressed with gzip, 0 disables compression. Compressed values are only decompressed when compression is enabled. Default: 0",
+		},
+		{
+			Name:        nameBucketTreeMaxDepth,
+			Type:        guinea.Int,
+			Default:     32,
+			Description: "Maximum number of levels of nested buckets included in a bucket tree, 0 disables the limit. Default: 32",
+		},
+		{
+			Name:        nameCacheTTL,
+			Type:        guinea.String,
+			Default:     "1s",
+			Description: "Specifies for how long bucket listings and statistics are cached, 0 disables caching. Default: 1s",
+		},
+		{
+			Name:        nameHexDumpLimit,
+			Type:        guinea.Int,
+			Default:     defaultHexDumpLimit,
+			Description: "Default number of bytes of a value shown in a hex dump, 0 shows entire values. Default: 65536",
+		},
+		{
+			Name:        nameAuditLog,
+			Type:        guinea.String,
+			Default:     "",
+			Description: "Path to a file in which all modifications are recorded, created if it doesn't exist. The audit log is disabled by default",
+		},
+		{
+			Name:        nameReadOnly,
+			Type:        guinea.Bool,
+			Default:     false,
+			Description: "Disables all operations which modify the database",
+		},
+		{
+			Name:        nameOpenMode,
+			Type:        guinea.String,
+			Default:     string(config.OpenModeReadWrite),
+			Description: `One of: rw or ro. In the ro mode the database is opened with a shared lock which makes it possible to browse a database used by another process opening it in read-only mode. Default: rw`,
+		},
+		{
+			Name:        nameBasePath,
+			Type:        guinea.String,
+			Default:     "",
+			Description: "Path under which the program is served e.g. /dbadmin when running behind a reverse proxy which forwards the requests without removing the path. By default the program is served at the root",
+		},
+		{
+			Name:        nameCreateIfMissing,
+			Type:        guinea.Bool,
+			Default:     false,
+			Description: "Creates the database files which don't exist, by default the program refuses to open them to avoid creating an empty database because of a mistyped path",
+		},
+		{
+			Name:        nameRedactPaths,
+			Type:        guinea.Bool,
+			Default:     false,
+			Description: "Reports only the base names of the database files instead of their full paths",
+		},
+		{
+			Name:        nameOpenTimeout,
+			Type:        guinea.String,
+			Default:     adapters.DefaultOpenTimeout.String(),
+			Description: `Specifies how long to wait for the database file lock before giving up e.g. 500ms or 10s. Default: 5s`,
+		},
 	},
 	ShortDescription: "a web user interface for the Bolt database",
 	Description: `
-Thanks to bolt-ui you are able to explore a Bolt database using a web
+Thanks to bolt-ui you are able to explore Bolt databases using a web
 interface. To access the web interface access the address printed out by the
 program. Make sure that the address includes the token query parameter.
+
+Each option can also be set using an environment variable named after the
+option e.g. BOLTUI_OPEN_TIMEOUT for open-timeout. Options given on the command
+line take precedence over the environment variables.
 `,
 }
 
+// defaultHexDumpLimit keeps the hex dumps of large values readable.
+const defaultHexDumpLimit = 64 * 1024
+
 var log = logging.New("main")
 
 func run(c guinea.Context) error {
@@ -93,27 +335,146 @@ func run(c guinea.Context) error {
 		log.Warn("insecure-tls option enabled")
 	}
 
+	if conf.Metrics && conf.InsecureMetrics {
+		log.Warn("insecure-metrics option enabled")
+	}
+
+	if conf.ReadOnly {
+		log.Info("read-only option enabled")
+	}
+
+	if conf.OpenMode == config.OpenModeReadOnly {
+		log.Info("opening the database in read-only mode")
+	}
+
 	service, err := wire.BuildService(conf)
 	if err != nil {
 		return errors.Wrap(err, "could not create a service")
 	}
 
-	printInfo(conf)
+	if err := service.HTTPServer.Listen(); err != nil {
+		return errors.Wrap(err, "could not listen")
+	}
+
+	printInfo(conf, service.HTTPServer.Addr(), service.Databases)
 
-	return service.HTTPServer.Serve()
+	return service.Run()
 }
 
 func newConfig(c guinea.Context) (*config.Config, error) {
+	openMode, err := config.NewOpenMode(c.Options[nameOpenMode].Str())
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid %s", optionSource(c, nameOpenMode))
+	}
+
+	openTimeout, err := time.ParseDuration(c.Options[nameOpenTimeout].Str())
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid %s", optionSource(c, nameOpenTimeout))
+	}
+
+	tlsMinVersion, err := config.NewTLSVersion(c.Options[nameTLSMinVersion].Str())
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid %s", optionSource(c, nameTLSMinVersion))
+	}
+
+	corsOrigins, err := newCORSOrigins(c.Options[nameCORSOrigins].Str())
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid %s", optionSource(c, nameCORSOrigins))
+	}
+
+	accessLog := c.Options[nameAccessLog].Str() != "none"
+
+	var accessLogLevel logging.Level
+	if accessLog {
+		accessLogLevel, err = logging.LevelFromString(c.Options[nameAccessLog].Str())
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid %s", optionSource(c, nameAccessLog))
+		}
+	}
+
+	rateLimit, err := strconv.ParseFloat(c.Options[nameRateLimit].Str(), 64)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid %s", optionSource(c, nameRateLimit))
+	}
+
+	authBackoff, err := time.ParseDuration(c.Options[nameAuthBackoff].Str())
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid %s", optionSource(c, nameAuthBackoff))
+	}
+
+	authBackoffMax, err := time.ParseDuration(c.Options[nameAuthBackoffMax].Str())
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid %s", optionSource(c, nameAuthBackoffMax))
+	}
+
+	requestTimeout, err := time.ParseDuration(c.Options[nameRequestTimeout].Str())
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid %s", optionSource(c, nameRequestTimeout))
+	}
+
+	cacheTTL, err := time.ParseDuration(c.Options[nameCacheTTL].Str())
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid %s", optionSource(c, nameCacheTTL))
+	}
+
+	databases, err := newDatabases(databaseArguments(c))
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid databases")
+	}
+
 	conf := &config.Config{
 		ServeAddress:  c.Options[nameAddress].Str(),
-		DatabaseFile:  c.Arguments[0],
+		Databases:     databases,
 		InsecureCORS:  c.Options[nameInsecureCORS].Bool(),
+		CORSOrigins:   corsOrigins,
 		InsecureToken: c.Options[nameInsecureToken].Bool(),
+		BasicAuth:     c.Options[nameBasicAuth].Bool(),
 		InsecureTLS:   c.Options[nameInsecureTLS].Bool(),
+		ReadOnly:      c.Options[nameReadOnly].Bool(),
+		OpenMode:      openMode,
+		OpenTimeout:   openTimeout,
+		TLSMinVersion: tlsMinVersion,
+
+		BasePath: newBasePath(c.Options[nameBasePath].Str()),
+
+		CreateIfMissing: c.Options[nameCreateIfMissing].Bool(),
+
+		RedactPaths: c.Options[nameRedactPaths].Bool(),
+
+		Compression:        !c.Options[nameDisableCompression].Bool(),
+		CompressionMinSize: c.Options[nameCompressionMinSize].Int(),
+
+		Metrics:         c.Options[nameMetrics].Bool(),
+		InsecureMetrics: c.Options[nameInsecureMetrics].Bool(),
+		MetricsBuckets:  newMetricsBuckets(c.Options[nameMetricsBuckets].Str()),
+
+		AccessLog:       accessLog,
+		AccessLogLevel:  accessLogLevel,
+		AccessLogBodies: c.Options[nameAccessLogBodies].Bool(),
+
+		RateLimit:          rateLimit,
+		RateLimitBurst:     c.Options[nameRateLimitBurst].Int(),
+		TrustedProxyHeader: c.Options[nameTrustedProxyHeader].Str(),
+
+		AuthBackoff:    authBackoff,
+		AuthBackoffMax: authBackoffMax,
+
+		RequestTimeout: requestTimeout,
+
+		MaxValueSize:  c.Options[nameMaxValueSize].Int(),
+		MaxImportSize: c.Options[nameMaxImportSize].Int(),
+
+		ValueCompressionThreshold: c.Options[nameValueCompressionThreshold].Int(),
+
+		BucketTreeMaxDepth: c.Options[nameBucketTreeMaxDepth].Int(),
+
+		CacheTTL:     cacheTTL,
+		HexDumpLimit: c.Options[nameHexDumpLimit].Int(),
+		AuditLog:     c.Options[nameAuditLog].Str(),
 	}
 
 	if !conf.InsecureToken {
-		token, err := generateSecureToken()
+		token, err := httpPort.GenerateToken()
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to generate secure token")
 		}
@@ -121,16 +482,113 @@ func newConfig(c guinea.Context) (*config.Config, error) {
 	}
 
 	if !conf.InsecureTLS {
-		cert, err := generateCertificate()
+		cert, err := newCertificate(c.Options[nameTLSCert].Str(), c.Options[nameTLSKey].Str())
 		if err != nil {
-			return nil, errors.Wrap(err, "failed to generate certificate")
+			return nil, errors.Wrap(err, "failed to create certificate")
 		}
 		conf.Certificate = cert
+	} else if c.Options[nameTLSCert].Str() != "" || c.Options[nameTLSKey].Str() != "" {
+		return nil, errors.New("tls-cert and tls-key can't be used together with insecure-tls")
 	}
 
 	return conf, nil
 }
 
+// newBasePath normalizes the base path so that both "dbadmin" and
+// "/dbadmin/" result in "/dbadmin". The root results in an empty path.
+func newBasePath(s string) string {
+	s = strings.Trim(strings.TrimSpace(s), "/")
+	if s == "" {
+		return ""
+	}
+	return "/" + s
+}
+
+func newCORSOrigins(s string) ([]string, error) {
+	var origins []string
+
+	for _, origin := range strings.Split(s, ",") {
+		origin = strings.TrimSpace(origin)
+		if origin == "" {
+			continue
+		}
+
+		// Credentials are allowed which means that according to the
+		// specification the wildcard origin can't be used.
+		if strings.Contains(origin, "*") {
+			return nil, fmt.Errorf("wildcard origin '%s' is not allowed, use insecure-cors instead", origin)
+		}
+
+		origins = append(origins, origin)
+	}
+
+	return origins, nil
+}
+
+func newMetricsBuckets(s string) []string {
+	var buckets []string
+
+	for _, bucket := range strings.Split(s, ",") {
+		if bucket = strings.TrimSpace(bucket); bucket != "" {
+			buckets = append(buckets, bucket)
+		}
+	}
+
+	return buckets
+}
+
+func newDatabases(arguments []string) ([]config.Database, error) {
+	var databases []config.Database
+
+	names := make(map[string]bool)
+
+	for _, argument := range arguments {
+		database := config.Database{
+			Name: filepath.Base(argument),
+			File: argument,
+		}
+
+		if i := strings.Index(argument, "="); i >= 0 {
+			database.Name = argument[:i]
+			database.File = argument[i+1:]
+		}
+
+		if database.Name == "" || database.File == "" {
+			return nil, fmt.Errorf("invalid database '%s'", argument)
+		}
+
+		if names[database.Name] {
+			return nil, fmt.Errorf("duplicate database name '%s'", database.Name)
+		}
+		names[database.Name] = true
+
+		databases = append(databases, database)
+	}
+
+	if len(databases) == 0 {
+		return nil, errors.New("no databases specified")
+	}
+
+	return databases, nil
+}
+
+func newCertificate(certFile, keyFile string) (tls.Certificate, error) {
+	if certFile == "" && keyFile == "" {
+		return generateCertificate()
+	}
+
+	if certFile == "" || keyFile == "" {
+		return tls.Certificate{}, errors.New("both tls-cert and tls-key must be specified")
+	}
+
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return tls.Certificate{}, errors.Wrap(err, "failed to load the certificate")
+	}
+
+	return cert, nil
+}
+
 func generateCertificate() (tls.Certificate, error) {
 	hosts := []string{
 		"localhost",
@@ -185,10 +643,12 @@ func generateCertificate() (tls.Certificate, error) {
 	}, nil
 }
 
-func printInfo(conf *config.Config) {
-	addr := conf.ServeAddress
-	if strings.HasPrefix(addr, ":") {
-		addr = "localhost" + addr
+func printInfo(conf *config.Config, listenAddr net.Addr, databases *service.Databases) {
+	addr := listenAddr.String()
+	if host, port, err := net.SplitHostPort(addr); err == nil {
+		if ip := net.ParseIP(host); ip != nil && ip.IsUnspecified() {
+			addr = net.JoinHostPort("localhost", port)
+		}
 	}
 
 	if conf.InsecureTLS {
@@ -197,11 +657,19 @@ func printInfo(conf *config.Config) {
 		addr = "https://" + addr
 	}
 
+	addr += conf.BasePath
+
 	if !conf.InsecureToken {
 		addr = fmt.Sprintf("%s/?token=%s", addr, conf.Token)
 	}
 
-	fmt.Printf("You can view database '%s' by clicking on this link:\n", conf.DatabaseFile)
+	for _, database := range conf.Databases {
+		if _, ok := databases.Get(database.Name); ok {
+			fmt.Printf("Serving database '%s' from file '%s'.\n", database.Name, database.File)
+		}
+	}
+	fmt.Println()
+	fmt.Println("You can view the databases by clicking on this link:")
 	fmt.Println(addr)
 	if !conf.InsecureTLS {
 		fmt.Println()
@@ -226,13 +694,3 @@ func certFingerprint(cert tls.Certificate) string {
 
 	return builder.String()
 }
-
-const tokenLength = 32
-
-func generateSecureToken() (string, error) {
-	b := make([]byte, tokenLength)
-	if _, err := rand.Read(b); err != nil {
-		return "", errors.Wrap(err, "failed to read random bytes")
-	}
-	return hex.EncodeToString(b), nil
-}
@@ -12,6 +12,10 @@ import (
 
 func main() {
 	injectGlobalBehaviour(&commands.MainCmd)
+	if err := commands.ApplyEnvironment(&commands.MainCmd); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
 	if err := guinea.Run(&commands.MainCmd); err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
@@ -1,14 +1,161 @@
 // Package config holds the configuration struct.
 package config
 
-import "crypto/tls"
+import (
+	"crypto/tls"
+	"fmt"
+	"time"
+
+	"github.com/contentforward/bolt-ui/logging"
+)
 
 type Config struct {
 	ServeAddress  string
-	DatabaseFile  string
+	Databases     []Database
 	Token         string
+	BasicAuth     bool
 	Certificate   tls.Certificate
+	TLSMinVersion uint16
 	InsecureCORS  bool
+	CORSOrigins   []string
 	InsecureToken bool
 	InsecureTLS   bool
+	ReadOnly      bool
+	OpenMode      OpenMode
+	OpenTimeout   time.Duration
+
+	// BasePath is the path under which all routes are served e.g. /dbadmin
+	// when running behind a reverse proxy, empty serves them at the root.
+	// It starts with a slash and doesn't end with one.
+	BasePath string
+
+	// CreateIfMissing creates the database files which don't exist instead
+	// of refusing to open them.
+	CreateIfMissing bool
+
+	// RedactPaths hides the directories of the database files when
+	// reporting their paths.
+	RedactPaths bool
+
+	// Compression enables gzip compression of responses larger than
+	// CompressionMinSize bytes.
+	Compression        bool
+	CompressionMinSize int
+
+	// Metrics enables the metrics endpoint. If InsecureMetrics is set the
+	// endpoint doesn't require a token. MetricsBuckets lists the top-level
+	// buckets for which the number of keys is reported.
+	Metrics         bool
+	InsecureMetrics bool
+	MetricsBuckets  []string
+
+	// AccessLog enables logging every request at AccessLogLevel. If
+	// AccessLogBodies is set request bodies are logged as well.
+	AccessLog       bool
+	AccessLogLevel  logging.Level
+	AccessLogBodies bool
+
+	// RateLimit specifies the number of requests per second each client can
+	// make on average, zero disables rate limiting. RateLimitBurst is the
+	// maximum number of requests a client can make at once. If
+	// TrustedProxyHeader is set the client address is read from that
+	// header e.g. X-Forwarded-For.
+	RateLimit          float64
+	RateLimitBurst     int
+	TrustedProxyHeader string
+
+	// AuthBackoff is the time for which a client has to wait after failing
+	// to authenticate before its credentials are checked again, zero
+	// disables it. The time doubles after each consecutive failure up to
+	// AuthBackoffMax and is reset once the client authenticates. Clients
+	// are identified only by their address so it is disabled by default,
+	// otherwise a single client could lock out everyone else behind the same
+	// NAT or proxy.
+	AuthBackoff    time.Duration
+	AuthBackoffMax time.Duration
+
+	// RequestTimeout limits the duration of the requests which iterate over
+	// the buckets such as exports and searches, zero disables the limit.
+	// Backups, compactions and change notifications are not limited.
+	RequestTimeout time.Duration
+
+	// CacheTTL specifies for how long the bucket listings and statistics
+	// are cached, zero disables caching. The cache is invalidated early if
+	// the related buckets are modified using this program.
+	CacheTTL time.Duration
+
+	// MaxValueSize is the maximum size of the values written using this
+	// program in bytes, zero means no limit.
+	MaxValueSize int
+
+	// MaxImportSize is the maximum size of the imported files in bytes,
+	// zero means no limit. JSON and CSV files are read into memory and
+	// written in a single transaction, Bolt files are written to a temporary
+	// file and copied in batches.
+	MaxImportSize int
+
+	// ValueCompressionThreshold enables gzip compression of the values
+	// written using this program which are at least this number of bytes
+	// long, zero disables it. Compressed values are transparently
+	// decompressed when read only if compression is enabled.
+	ValueCompressionThreshold int
+
+	// BucketTreeMaxDepth is the maximum number of levels of nested buckets
+	// included in a bucket tree, zero means no limit.
+	BucketTreeMaxDepth int
+
+	// HexDumpLimit is the default number of bytes of a value included in a
+	// hex dump, zero means no limit.
+	HexDumpLimit int
+
+	// AuditLog is a path to the file in which the modifications made using
+	// this program are recorded, empty disables the audit log.
+	AuditLog string
+}
+
+type Database struct {
+	// Name is used to refer to the database in the API.
+	Name string
+
+	// File is a path to the database file.
+	File string
+}
+
+// OpenMode specifies how the database file is opened.
+type OpenMode string
+
+const (
+	// OpenModeReadWrite opens the database file with an exclusive lock
+	// which prevents other processes from opening it at the same time.
+	OpenModeReadWrite OpenMode = "rw"
+
+	// OpenModeReadOnly opens the database file with a shared lock which
+	// makes it possible to browse a database opened by other processes
+	// which also use a shared lock. Mutations are disabled in this mode.
+	OpenModeReadOnly OpenMode = "ro"
+)
+
+// NewTLSVersion parses a TLS version such as "1.2".
+func NewTLSVersion(s string) (uint16, error) {
+	switch s {
+	case "1.0":
+		return tls.VersionTLS10, nil
+	case "1.1":
+		return tls.VersionTLS11, nil
+	case "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("invalid TLS version '%s'", s)
+	}
+}
+
+func NewOpenMode(s string) (OpenMode, error) {
+	switch OpenMode(s) {
+	case OpenModeReadWrite, OpenModeReadOnly:
+		return OpenMode(s), nil
+	default:
+		return "", fmt.Errorf("invalid open mode '%s'", s)
+	}
 }
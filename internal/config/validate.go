@@ -0,0 +1,196 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/boreq/errors"
+)
+
+// Validate checks the global settings and returns an error listing all found
+// problems. The database files are checked separately using ValidateDatabase
+// so that a single broken database doesn't prevent the others from being
+// browsed.
+func (c *Config) Validate() error {
+	var problems []string
+
+	if err := validateServeAddress(c.ServeAddress); err != nil {
+		problems = append(problems, err.Error())
+	}
+
+	if c.BasePath != "" && (!strings.HasPrefix(c.BasePath, "/") || strings.HasSuffix(c.BasePath, "/")) {
+		problems = append(problems, fmt.Sprintf("base path '%s' must start with a slash and can't end with one", c.BasePath))
+	}
+
+	if len(c.Databases) == 0 {
+		problems = append(problems, "no databases specified")
+	}
+
+	if c.CreateIfMissing && c.OpenMode == OpenModeReadOnly {
+		problems = append(problems, "databases can't be created in the read-only open mode")
+	}
+
+	if !c.InsecureToken && c.Token == "" {
+		problems = append(problems, "token is not set")
+	}
+
+	if !c.InsecureTLS && len(c.Certificate.Certificate) == 0 {
+		problems = append(problems, "TLS certificate is not set")
+	}
+
+	if c.OpenTimeout <= 0 {
+		problems = append(problems, "open timeout must be positive")
+	}
+
+	if c.CompressionMinSize < 0 {
+		problems = append(problems, "compression min size can't be negative")
+	}
+
+	if c.RateLimit < 0 {
+		problems = append(problems, "rate limit can't be negative")
+	}
+
+	if c.RateLimit > 0 && c.RateLimitBurst < 1 {
+		problems = append(problems, "rate limit burst must be positive")
+	}
+
+	if c.AuthBackoff < 0 {
+		problems = append(problems, "auth backoff can't be negative")
+	}
+
+	if c.AuthBackoff > 0 && c.AuthBackoffMax < c.AuthBackoff {
+		problems = append(problems, "auth backoff max can't be shorter than auth backoff")
+	}
+
+	if c.RequestTimeout < 0 {
+		problems = append(problems, "request timeout can't be negative")
+	}
+
+	if c.CacheTTL < 0 {
+		problems = append(problems, "cache TTL can't be negative")
+	}
+
+	if c.MaxValueSize < 0 {
+		problems = append(problems, "max value size can't be negative")
+	}
+
+	if c.MaxImportSize < 0 {
+		problems = append(problems, "max import size can't be negative")
+	}
+
+	if c.ValueCompressionThreshold < 0 {
+		problems = append(problems, "value compression threshold can't be negative")
+	}
+
+	if c.BucketTreeMaxDepth < 0 {
+		problems = append(problems, "bucket tree max depth can't be negative")
+	}
+
+	if c.HexDumpLimit < 0 {
+		problems = append(problems, "hex dump limit can't be negative")
+	}
+
+	for _, database := range c.Databases {
+		if c.AuditLog != "" && c.AuditLog == database.File {
+			problems = append(problems, fmt.Sprintf("database '%s': file can't be used as the audit log", database.Name))
+		}
+	}
+
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
+	}
+
+	return nil
+}
+
+// ValidateDatabase checks if the database file can be opened. If the missing
+// databases are created it is enough for the directory to be writable.
+func (c *Config) ValidateDatabase(database Database) error {
+	if c.CreateIfMissing && !fileExists(database.File) {
+		return validateDirectoryWritable(filepath.Dir(database.File))
+	}
+
+	return validateDatabaseFile(database.File, c.OpenMode == OpenModeReadOnly)
+}
+
+func validateServeAddress(address string) error {
+	if address == "" {
+		return errors.New("serve address is empty")
+	}
+
+	_, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("serve address '%s' is invalid: %s", address, err)
+	}
+
+	if n, err := strconv.Atoi(port); err != nil || n < 0 || n > 65535 {
+		return fmt.Errorf("serve address '%s' has an invalid port", address)
+	}
+
+	return nil
+}
+
+func validateDatabaseFile(file string, readOnly bool) error {
+	info, err := os.Stat(file)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("file '%s' does not exist", file)
+		}
+		return fmt.Errorf("file '%s' can't be accessed: %s", file, err)
+	}
+
+	if info.IsDir() {
+		return fmt.Errorf("'%s' is a directory", file)
+	}
+
+	flag := os.O_RDWR
+	if readOnly {
+		flag = os.O_RDONLY
+	}
+
+	f, err := os.OpenFile(file, flag, 0)
+	if err != nil {
+		if readOnly {
+			return fmt.Errorf("file '%s' is not readable: %s", file, err)
+		}
+		return fmt.Errorf("file '%s' is not readable and writable: %s", file, err)
+	}
+
+	return f.Close()
+}
+
+func validateDirectoryWritable(dir string) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("directory '%s' does not exist", dir)
+		}
+		return fmt.Errorf("directory '%s' can't be accessed: %s", dir, err)
+	}
+
+	if !info.IsDir() {
+		return fmt.Errorf("'%s' is not a directory", dir)
+	}
+
+	f, err := ioutil.TempFile(dir, ".bolt-ui-")
+	if err != nil {
+		return fmt.Errorf("directory '%s' is not writable: %s", dir, err)
+	}
+
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return fmt.Errorf("directory '%s' is not writable: %s", dir, err)
+	}
+
+	return os.Remove(f.Name())
+}
+
+func fileExists(file string) bool {
+	_, err := os.Stat(file)
+	return !errors.Is(err, os.ErrNotExist)
+}
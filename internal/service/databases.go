@@ -0,0 +1,63 @@
+package service
+
+import (
+	"github.com/boreq/errors"
+	"github.com/contentforward/bolt-ui/adapters"
+	"github.com/contentforward/bolt-ui/application"
+	bolt "go.etcd.io/bbolt"
+)
+
+type Database struct {
+	Name        string
+	DB          *adapters.DatabaseFile
+	Application *application.Application
+}
+
+// Databases is a registry of all successfully opened databases.
+type Databases struct {
+	databases []Database
+	auditLog  *bolt.DB
+}
+
+// NewDatabases creates a registry of the databases. The audit log database is
+// closed together with them and can be nil.
+func NewDatabases(databases []Database, auditLog *bolt.DB) *Databases {
+	return &Databases{
+		databases: databases,
+		auditLog:  auditLog,
+	}
+}
+
+func (d *Databases) Names() []string {
+	var names []string
+	for _, database := range d.databases {
+		names = append(names, database.Name)
+	}
+	return names
+}
+
+func (d *Databases) Get(name string) (*application.Application, bool) {
+	for _, database := range d.databases {
+		if database.Name == name {
+			return database.Application, true
+		}
+	}
+	return nil, false
+}
+
+// Close closes all databases. It must not be called while the databases are
+// still in use.
+func (d *Databases) Close() error {
+	var result error
+	for _, database := range d.databases {
+		if err := database.DB.Close(); err != nil && result == nil {
+			result = errors.Wrapf(err, "could not close database '%s'", database.Name)
+		}
+	}
+	if d.auditLog != nil {
+		if err := d.auditLog.Close(); err != nil && result == nil {
+			result = errors.Wrap(err, "could not close the audit log")
+		}
+	}
+	return result
+}
@@ -1,15 +1,69 @@
 package service
 
 import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/boreq/errors"
+	"github.com/contentforward/bolt-ui/logging"
 	httpPort "github.com/contentforward/bolt-ui/ports/http"
 )
 
+// ShutdownTimeout is the amount of time in-flight requests have to complete
+// after a shutdown signal is received.
+const ShutdownTimeout = 10 * time.Second
+
 type Service struct {
 	HTTPServer *httpPort.Server
+	Databases  *Databases
+	log        logging.Logger
 }
 
-func NewService(httpServer *httpPort.Server) *Service {
+func NewService(httpServer *httpPort.Server, databases *Databases) *Service {
 	return &Service{
 		HTTPServer: httpServer,
+		Databases:  databases,
+		log:        logging.New("service"),
+	}
+}
+
+// Run serves the HTTP API until SIGINT or SIGTERM is received. The databases
+// are closed only after the HTTP server stopped handling requests.
+func (s *Service) Run() error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- s.HTTPServer.Serve()
+	}()
+
+	var err error
+
+	select {
+	case err = <-serveErr:
+	case <-ctx.Done():
+		s.log.Info("shutting down")
+		err = s.shutdown(serveErr)
 	}
+
+	if closeErr := s.Databases.Close(); closeErr != nil && err == nil {
+		err = errors.Wrap(closeErr, "could not close the databases")
+	}
+
+	return err
+}
+
+func (s *Service) shutdown(serveErr <-chan error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), ShutdownTimeout)
+	defer cancel()
+
+	if err := s.HTTPServer.Shutdown(ctx); err != nil {
+		return errors.Wrap(err, "could not shut down the http server")
+	}
+
+	return <-serveErr
 }
@@ -1,6 +1,7 @@
 package tests
 
 import (
+	"context"
 	"math/rand"
 	"sort"
 	"testing"
@@ -284,6 +285,694 @@ func TestBrowseNilValues(t *testing.T) {
 		tree.Entries)
 }
 
+func TestListBuckets(t *testing.T) {
+	testApp := NewTracker(t)
+
+	expectedEntries := bucketEntries(30)
+
+	err := testApp.DB.Update(func(tx *bbolt.Tx) error {
+		for _, entry := range expectedEntries {
+			_, err := tx.CreateBucketIfNotExists(entry.Key.Bytes())
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	buckets, err := testApp.Application.ListBuckets.Execute(application.ListBuckets{})
+	require.NoError(t, err)
+
+	var expectedBuckets []application.Key
+	for _, entry := range expectedEntries {
+		expectedBuckets = append(expectedBuckets, entry.Key)
+	}
+
+	require.Equal(t, expectedBuckets, buckets)
+}
+
+func TestListBucketsNested(t *testing.T) {
+	testApp := NewTracker(t)
+
+	bucketName := []byte("bucket")
+	childA := []byte("a")
+	childB := []byte("b")
+	valueKey := []byte("c")
+
+	err := testApp.DB.Update(func(tx *bbolt.Tx) error {
+		bucket, err := tx.CreateBucket(bucketName)
+		if err != nil {
+			return err
+		}
+
+		if _, err := bucket.CreateBucket(childA); err != nil {
+			return err
+		}
+
+		if _, err := bucket.CreateBucket(childB); err != nil {
+			return err
+		}
+
+		return bucket.Put(valueKey, []byte("value"))
+	})
+	require.NoError(t, err)
+
+	buckets, err := testApp.Application.ListBuckets.Execute(
+		application.ListBuckets{
+			Path: []application.Key{
+				application.MustNewKey(bucketName),
+			},
+		},
+	)
+	require.NoError(t, err)
+	require.Equal(t,
+		[]application.Key{
+			application.MustNewKey(childA),
+			application.MustNewKey(childB),
+		},
+		buckets,
+	)
+
+	buckets, err = testApp.Application.ListBuckets.Execute(
+		application.ListBuckets{
+			Path: []application.Key{
+				application.MustNewKey(bucketName),
+				application.MustNewKey(childA),
+			},
+		},
+	)
+	require.NoError(t, err)
+	require.Empty(t, buckets)
+
+	_, err = testApp.Application.ListBuckets.Execute(
+		application.ListBuckets{
+			Path: []application.Key{
+				application.MustNewKey(bucketName),
+				application.MustNewKey([]byte("missing")),
+			},
+		},
+	)
+	require.ErrorIs(t, err, application.ErrBucketNotFound)
+
+	_, err = testApp.Application.ListBuckets.Execute(
+		application.ListBuckets{
+			Path: []application.Key{
+				application.MustNewKey(bucketName),
+				application.MustNewKey(valueKey),
+			},
+		},
+	)
+	require.ErrorIs(t, err, application.ErrNotABucket)
+}
+
+func TestResolvePath(t *testing.T) {
+	testApp := NewTracker(t)
+
+	err := testApp.DB.Update(func(tx *bbolt.Tx) error {
+		bucket, err := tx.CreateBucket([]byte("bucket"))
+		if err != nil {
+			return err
+		}
+
+		if _, err := bucket.CreateBucket([]byte("child")); err != nil {
+			return err
+		}
+
+		return bucket.Put([]byte("key"), []byte("value"))
+	})
+	require.NoError(t, err)
+
+	bucket := application.MustNewKey([]byte("bucket"))
+	child := application.MustNewKey([]byte("child"))
+	key := application.MustNewKey([]byte("key"))
+	missing := application.MustNewKey([]byte("missing"))
+
+	testCases := []struct {
+		Name             string
+		Path             []application.Key
+		ExpectedSegments []application.PathSegment
+		ExpectedResolved bool
+	}{
+		{
+			Name:             "root",
+			Path:             nil,
+			ExpectedSegments: nil,
+			ExpectedResolved: true,
+		},
+		{
+			Name: "nested_bucket",
+			Path: []application.Key{bucket, child},
+			ExpectedSegments: []application.PathSegment{
+				{Key: bucket, Exists: true, Bucket: true},
+				{Key: child, Exists: true, Bucket: true},
+			},
+			ExpectedResolved: true,
+		},
+		{
+			Name: "missing_root_bucket",
+			Path: []application.Key{missing, child},
+			ExpectedSegments: []application.PathSegment{
+				{Key: missing, Exists: false, Bucket: false},
+			},
+			ExpectedResolved: false,
+		},
+		{
+			Name: "missing_nested_bucket",
+			Path: []application.Key{bucket, missing, child},
+			ExpectedSegments: []application.PathSegment{
+				{Key: bucket, Exists: true, Bucket: true},
+				{Key: missing, Exists: false, Bucket: false},
+			},
+			ExpectedResolved: false,
+		},
+		{
+			Name: "value",
+			Path: []application.Key{bucket, key, child},
+			ExpectedSegments: []application.PathSegment{
+				{Key: bucket, Exists: true, Bucket: true},
+				{Key: key, Exists: true, Bucket: false},
+			},
+			ExpectedResolved: false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			info, err := testApp.Application.ResolvePath.Execute(
+				application.ResolvePath{
+					Path: testCase.Path,
+				},
+			)
+			require.NoError(t, err)
+			require.Equal(t, testCase.ExpectedSegments, info.Segments)
+			require.Equal(t, testCase.ExpectedResolved, info.Resolved())
+		})
+	}
+}
+
+func TestListKeys(t *testing.T) {
+	testApp := NewTracker(t)
+
+	bucketName := "bucket"
+	expectedEntries := mixedBucketEntries(25)
+
+	err := testApp.DB.Update(func(tx *bbolt.Tx) error {
+		bucket, err := tx.CreateBucket([]byte(bucketName))
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range expectedEntries {
+			if entry.Value.IsEmpty() {
+				_, err := bucket.CreateBucket(entry.Key.Bytes())
+				if err != nil {
+					return err
+				}
+			} else {
+				if err = bucket.Put(entry.Key.Bytes(), entry.Value.Bytes()); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	path := []application.Key{
+		application.MustNewKey([]byte(bucketName)),
+	}
+
+	var keys []application.KeyInfo
+	var after *application.Key
+
+	for i := 0; i < 3; i++ {
+		page, err := testApp.Application.ListKeys.Execute(
+			application.ListKeys{
+				Context: context.Background(),
+				Path:    path,
+				After:   after,
+				Limit:   10,
+			},
+		)
+		require.NoError(t, err)
+
+		keys = append(keys, page.Keys...)
+		after = page.Next
+
+		if i < 2 {
+			require.Len(t, page.Keys, 10)
+			require.NotNil(t, page.Next)
+		} else {
+			require.Len(t, page.Keys, 5)
+			require.Nil(t, page.Next)
+		}
+	}
+
+	var expectedKeys []application.KeyInfo
+	for _, entry := range expectedEntries {
+		expectedKeys = append(expectedKeys, application.KeyInfo{
+			Bucket: entry.Value.IsEmpty(),
+			Key:    entry.Key,
+		})
+	}
+
+	require.Equal(t, expectedKeys, keys)
+}
+
+func TestListKeysWithSizes(t *testing.T) {
+	testApp := NewTracker(t)
+
+	bucketName := []byte("bucket")
+
+	err := testApp.DB.Update(func(tx *bbolt.Tx) error {
+		bucket, err := tx.CreateBucket(bucketName)
+		if err != nil {
+			return err
+		}
+
+		if err := bucket.Put([]byte("a"), []byte("value")); err != nil {
+			return err
+		}
+
+		_, err = bucket.CreateBucket([]byte("b"))
+		return err
+	})
+	require.NoError(t, err)
+
+	path := []application.Key{
+		application.MustNewKey(bucketName),
+	}
+
+	page, err := testApp.Application.ListKeys.Execute(
+		application.ListKeys{
+			Context: context.Background(),
+			Path:    path,
+			Limit:   10,
+		},
+	)
+	require.NoError(t, err)
+	require.Len(t, page.Keys, 2)
+	require.Nil(t, page.Keys[0].Size)
+	require.Nil(t, page.Keys[1].Size)
+
+	page, err = testApp.Application.ListKeys.Execute(
+		application.ListKeys{
+			Context: context.Background(),
+			Path:    path,
+			Limit:   10,
+			Sizes:   true,
+		},
+	)
+	require.NoError(t, err)
+	require.Len(t, page.Keys, 2)
+
+	require.False(t, page.Keys[0].Bucket)
+	require.NotNil(t, page.Keys[0].Size)
+	require.Equal(t, 5, *page.Keys[0].Size)
+
+	require.True(t, page.Keys[1].Bucket)
+	require.Nil(t, page.Keys[1].Size, "buckets have no size")
+}
+
+func TestListKeysWithCount(t *testing.T) {
+	testApp := NewTracker(t)
+
+	bucketName := []byte("bucket")
+
+	err := testApp.DB.Update(func(tx *bbolt.Tx) error {
+		bucket, err := tx.CreateBucket(bucketName)
+		if err != nil {
+			return err
+		}
+
+		for _, key := range []string{"a", "b", "c"} {
+			if err := bucket.Put([]byte(key), []byte("value")); err != nil {
+				return err
+			}
+		}
+
+		child, err := bucket.CreateBucket([]byte("child"))
+		if err != nil {
+			return err
+		}
+
+		return child.Put([]byte("nested"), []byte("value"))
+	})
+	require.NoError(t, err)
+
+	path := []application.Key{
+		application.MustNewKey(bucketName),
+	}
+
+	page, err := testApp.Application.ListKeys.Execute(
+		application.ListKeys{
+			Context: context.Background(),
+			Path:    path,
+			Limit:   2,
+		},
+	)
+	require.NoError(t, err)
+	require.Nil(t, page.Total)
+
+	page, err = testApp.Application.ListKeys.Execute(
+		application.ListKeys{
+			Context: context.Background(),
+			Path:    path,
+			Limit:   2,
+			Count:   true,
+		},
+	)
+	require.NoError(t, err)
+	require.Len(t, page.Keys, 2)
+	require.NotNil(t, page.Total)
+	require.Equal(t, 4, *page.Total, "nested keys should not be counted")
+}
+
+func TestListKeysDescending(t *testing.T) {
+	testApp := NewTracker(t)
+
+	bucketName := "bucket"
+	expectedEntries := mixedBucketEntries(25)
+
+	err := testApp.DB.Update(func(tx *bbolt.Tx) error {
+		bucket, err := tx.CreateBucket([]byte(bucketName))
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range expectedEntries {
+			if entry.Value.IsEmpty() {
+				_, err := bucket.CreateBucket(entry.Key.Bytes())
+				if err != nil {
+					return err
+				}
+			} else {
+				if err = bucket.Put(entry.Key.Bytes(), entry.Value.Bytes()); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	path := []application.Key{
+		application.MustNewKey([]byte(bucketName)),
+	}
+
+	var keys []application.KeyInfo
+	var after *application.Key
+
+	for i := 0; i < 3; i++ {
+		page, err := testApp.Application.ListKeys.Execute(
+			application.ListKeys{
+				Context: context.Background(),
+				Path:    path,
+				After:   after,
+				Limit:   10,
+				Order:   application.ListKeysOrderKeyDescending,
+			},
+		)
+		require.NoError(t, err)
+
+		keys = append(keys, page.Keys...)
+		after = page.Next
+
+		if i < 2 {
+			require.Len(t, page.Keys, 10)
+			require.NotNil(t, page.Next)
+		} else {
+			require.Len(t, page.Keys, 5)
+			require.Nil(t, page.Next)
+		}
+	}
+
+	var expectedKeys []application.KeyInfo
+	for i := len(expectedEntries) - 1; i >= 0; i-- {
+		expectedKeys = append(expectedKeys, application.KeyInfo{
+			Bucket: expectedEntries[i].Value.IsEmpty(),
+			Key:    expectedEntries[i].Key,
+		})
+	}
+
+	require.Equal(t, expectedKeys, keys)
+}
+
+func TestListKeysSortedBySize(t *testing.T) {
+	testApp := NewTracker(t)
+
+	bucketName := []byte("bucket")
+
+	err := testApp.DB.Update(func(tx *bbolt.Tx) error {
+		bucket, err := tx.CreateBucket(bucketName)
+		if err != nil {
+			return err
+		}
+
+		for key, value := range map[string]string{"a": "long value", "b": "v", "c": "value", "d": "v"} {
+			if err := bucket.Put([]byte(key), []byte(value)); err != nil {
+				return err
+			}
+		}
+
+		_, err = bucket.CreateBucket([]byte("e"))
+		return err
+	})
+	require.NoError(t, err)
+
+	path := []application.Key{
+		application.MustNewKey(bucketName),
+	}
+
+	listKeys := func(order application.ListKeysOrder, limit int) []string {
+		page, err := testApp.Application.ListKeys.Execute(
+			application.ListKeys{
+				Context: context.Background(),
+				Path:    path,
+				Limit:   limit,
+				Sizes:   true,
+				Order:   order,
+			},
+		)
+		require.NoError(t, err)
+
+		var keys []string
+		for _, keyInfo := range page.Keys {
+			keys = append(keys, string(keyInfo.Key.Bytes()))
+		}
+		return keys
+	}
+
+	require.Equal(t, []string{"e", "b", "d", "c", "a"}, listKeys(application.ListKeysOrderSizeAscending, 10))
+	require.Equal(t, []string{"a", "c", "b", "d", "e"}, listKeys(application.ListKeysOrderSizeDescending, 10))
+	require.Equal(t, []string{"b", "c", "a"}, listKeys(application.ListKeysOrderSizeAscending, 3), "only the keys within the page are sorted")
+
+	_, err = testApp.Application.ListKeys.Execute(
+		application.ListKeys{
+			Context: context.Background(),
+			Path:    path,
+			Limit:   10,
+			Order:   application.ListKeysOrderSizeAscending,
+		},
+	)
+	require.Error(t, err, "sorting by size requires the sizes")
+}
+
+func TestSearchKeys(t *testing.T) {
+	testApp := NewTracker(t)
+
+	bucketName := []byte("bucket")
+
+	err := testApp.DB.Update(func(tx *bbolt.Tx) error {
+		bucket, err := tx.CreateBucket(bucketName)
+		if err != nil {
+			return err
+		}
+
+		for _, key := range []string{"a", "user:1", "user:2", "user:3", "users"} {
+			if err := bucket.Put([]byte(key), []byte("value")); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	path := []application.Key{
+		application.MustNewKey(bucketName),
+	}
+
+	testCases := []struct {
+		Name         string
+		Prefix       string
+		Limit        int
+		ExpectedKeys []string
+	}{
+		{
+			Name:         "empty_prefix",
+			Prefix:       "",
+			Limit:        2,
+			ExpectedKeys: []string{"a", "user:1"},
+		},
+		{
+			Name:         "prefix",
+			Prefix:       "user:",
+			Limit:        10,
+			ExpectedKeys: []string{"user:1", "user:2", "user:3"},
+		},
+		{
+			Name:         "prefix_with_limit",
+			Prefix:       "user:",
+			Limit:        2,
+			ExpectedKeys: []string{"user:1", "user:2"},
+		},
+		{
+			Name:         "no_matches",
+			Prefix:       "z",
+			Limit:        10,
+			ExpectedKeys: []string{},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			keys, err := testApp.Application.SearchKeys.Execute(
+				application.SearchKeys{
+					Path:   path,
+					Prefix: []byte(testCase.Prefix),
+					Limit:  testCase.Limit,
+				},
+			)
+			require.NoError(t, err)
+
+			expectedKeys := make([]application.KeyInfo, 0)
+			for _, key := range testCase.ExpectedKeys {
+				expectedKeys = append(expectedKeys, application.KeyInfo{
+					Bucket: false,
+					Key:    application.MustNewKey([]byte(key)),
+				})
+			}
+
+			require.Equal(t, expectedKeys, keys)
+		})
+	}
+}
+
+func TestCountKeysByPrefix(t *testing.T) {
+	testApp := NewTracker(t)
+
+	bucketName := []byte("bucket")
+
+	err := testApp.DB.Update(func(tx *bbolt.Tx) error {
+		bucket, err := tx.CreateBucket(bucketName)
+		if err != nil {
+			return err
+		}
+
+		if _, err := bucket.CreateBucket([]byte("user:0")); err != nil {
+			return err
+		}
+
+		for _, key := range []string{"a", "user:1", "user:2", "user:3", "users"} {
+			if err := bucket.Put([]byte(key), []byte("value")); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	path := []application.Key{
+		application.MustNewKey(bucketName),
+	}
+
+	testCases := []struct {
+		Name          string
+		Prefix        string
+		ExpectedCount int
+	}{
+		{
+			Name:          "empty_prefix",
+			Prefix:        "",
+			ExpectedCount: 6,
+		},
+		{
+			Name:          "prefix",
+			Prefix:        "user:",
+			ExpectedCount: 4,
+		},
+		{
+			Name:          "no_matches",
+			Prefix:        "z",
+			ExpectedCount: 0,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			n, err := testApp.Application.CountKeysByPrefix.Execute(
+				application.CountKeysByPrefix{
+					Context: context.Background(),
+					Path:    path,
+					Prefix:  []byte(testCase.Prefix),
+				},
+			)
+			require.NoError(t, err)
+			require.Equal(t, testCase.ExpectedCount, n)
+		})
+	}
+
+	t.Run("missing_bucket", func(t *testing.T) {
+		_, err := testApp.Application.CountKeysByPrefix.Execute(
+			application.CountKeysByPrefix{
+				Context: context.Background(),
+				Path: []application.Key{
+					application.MustNewKey([]byte("missing")),
+				},
+			},
+		)
+		require.ErrorIs(t, err, application.ErrBucketNotFound)
+	})
+
+	t.Run("context", func(t *testing.T) {
+		largeBucketName := []byte("large")
+
+		err := testApp.DB.Update(func(tx *bbolt.Tx) error {
+			bucket, err := tx.CreateBucket(largeBucketName)
+			if err != nil {
+				return err
+			}
+
+			for i := 0; i < 1000; i++ {
+				if err := bucket.Put([]byte{byte(i >> 8), byte(i)}, []byte("value")); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		})
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err = testApp.Application.CountKeysByPrefix.Execute(
+			application.CountKeysByPrefix{
+				Context: ctx,
+				Path: []application.Key{
+					application.MustNewKey(largeBucketName),
+				},
+			},
+		)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
+
 func NewTracker(t *testing.T) wire.TestApplication {
 	db, cleanup := fixture.Bolt(t)
 	t.Cleanup(cleanup)
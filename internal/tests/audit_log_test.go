@@ -0,0 +1,104 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/contentforward/bolt-ui/adapters"
+	"github.com/contentforward/bolt-ui/application"
+	"github.com/contentforward/bolt-ui/internal/fixture"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditLog(t *testing.T) {
+	file, cleanup := fixture.File(t)
+	defer cleanup()
+
+	db, err := adapters.NewBolt(file, false, adapters.DefaultOpenTimeout)
+	require.NoError(t, err)
+	defer db.Close()
+
+	auditLog := adapters.NewAuditLog(db, "database")
+	otherAuditLog := adapters.NewAuditLog(db, "other")
+
+	bucket := application.MustNewKey([]byte("bucket"))
+	key := application.MustNewKey([]byte("key"))
+
+	entries, err := auditLog.ListAuditEntries(time.Time{}, 0, 0)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+
+	auditLog.Publish(application.Change{
+		Path:      nil,
+		Key:       &bucket,
+		Operation: application.ChangeOperationCreateBucket,
+	})
+
+	time.Sleep(10 * time.Millisecond)
+	since := time.Now()
+
+	auditLog.Publish(application.Change{
+		Path:      []application.Key{bucket},
+		Key:       &key,
+		Operation: application.ChangeOperationPutValue,
+	})
+	auditLog.Publish(application.Change{
+		Path:      []application.Key{bucket},
+		Operation: application.ChangeOperationImport,
+	})
+
+	entries, err = auditLog.ListAuditEntries(time.Time{}, 0, 0)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+
+	require.Equal(t, application.ChangeOperationImport, entries[0].Operation)
+	require.Equal(t, []application.Key{bucket}, entries[0].Path)
+	require.Nil(t, entries[0].Key)
+
+	require.Equal(t, application.ChangeOperationPutValue, entries[1].Operation)
+	require.Equal(t, []application.Key{bucket}, entries[1].Path)
+	require.Equal(t, &key, entries[1].Key)
+
+	require.Equal(t, application.ChangeOperationCreateBucket, entries[2].Operation)
+	require.Empty(t, entries[2].Path)
+	require.Equal(t, &bucket, entries[2].Key)
+
+	require.Greater(t, entries[0].ID, entries[1].ID)
+	require.Greater(t, entries[1].ID, entries[2].ID)
+
+	entries, err = auditLog.ListAuditEntries(time.Time{}, 0, 2)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, application.ChangeOperationImport, entries[0].Operation)
+	require.Equal(t, application.ChangeOperationPutValue, entries[1].Operation)
+
+	entries, err = auditLog.ListAuditEntries(time.Time{}, entries[1].ID, 2)
+	require.NoError(t, err)
+	require.Len(t, entries, 1, "older entries are returned when paging")
+	require.Equal(t, application.ChangeOperationCreateBucket, entries[0].Operation)
+
+	entries, err = auditLog.ListAuditEntries(time.Time{}, entries[0].ID, 0)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+
+	entries, err = auditLog.ListAuditEntries(time.Time{}, 1000, 0)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+
+	entries, err = auditLog.ListAuditEntries(since, 0, 0)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	entries, err = otherAuditLog.ListAuditEntries(time.Time{}, 0, 0)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
+
+func TestAuditLogDisabled(t *testing.T) {
+	testApp := NewTracker(t)
+
+	_, err := testApp.Application.ListAuditEntries.Execute(application.ListAuditEntries{
+		Limit: 10,
+	})
+	require.ErrorIs(t, err, application.ErrAuditLogDisabled)
+}
@@ -0,0 +1,59 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/contentforward/bolt-ui/internal/config"
+	httpPort "github.com/contentforward/bolt-ui/ports/http"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthBackoff(t *testing.T) {
+	testApp := NewTracker(t)
+
+	conf := &config.Config{
+		Token:          "token",
+		AuthBackoff:    50 * time.Millisecond,
+		AuthBackoffMax: time.Second,
+	}
+
+	handler, err := httpPort.NewHandler(testDatabases{testApp.Application}, httpPort.NewTokenAuthProvider(conf), conf)
+	require.NoError(t, err)
+
+	request := func(remoteAddr, token string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/api/keys/", nil)
+		r.RemoteAddr = remoteAddr
+		r.Header.Set("Access-Token", token)
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, r)
+		return recorder
+	}
+
+	recorder := request("192.0.2.1:1234", "invalid")
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+	require.Equal(t, "1", recorder.Header().Get("Retry-After"))
+
+	recorder = request("192.0.2.1:1234", "token")
+	require.Equal(t, http.StatusTooManyRequests, recorder.Code, "credentials are not checked while the client waits")
+	require.Equal(t, "1", recorder.Header().Get("Retry-After"))
+
+	recorder = request("192.0.2.2:1234", "token")
+	require.Equal(t, http.StatusOK, recorder.Code, "other clients are not affected")
+
+	time.Sleep(60 * time.Millisecond)
+
+	recorder = request("192.0.2.1:4321", "token")
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	recorder = request("192.0.2.1:1234", "invalid")
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+
+	time.Sleep(60 * time.Millisecond)
+
+	recorder = request("192.0.2.1:1234", "token")
+	require.Equal(t, http.StatusOK, recorder.Code, "successful authentication resets the backoff")
+}
@@ -0,0 +1,58 @@
+package tests
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/contentforward/bolt-ui/application"
+	"github.com/contentforward/bolt-ui/internal/fixture"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/bbolt"
+)
+
+func TestBackup(t *testing.T) {
+	testApp := NewTracker(t)
+
+	bucketName := []byte("bucket")
+	key := []byte("key")
+	value := []byte("value")
+
+	err := testApp.DB.Update(func(tx *bbolt.Tx) error {
+		bucket, err := tx.CreateBucket(bucketName)
+		if err != nil {
+			return err
+		}
+
+		return bucket.Put(key, value)
+	})
+	require.NoError(t, err)
+
+	file, cleanup := fixture.File(t)
+	defer cleanup()
+
+	f, err := os.Create(file)
+	require.NoError(t, err)
+
+	n, err := testApp.Application.Backup.Execute(
+		application.Backup{
+			Writer: f,
+		},
+	)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	info, err := os.Stat(file)
+	require.NoError(t, err)
+	require.Equal(t, info.Size(), n)
+
+	db, err := bbolt.Open(file, 0600, &bbolt.Options{Timeout: 5 * time.Second})
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.View(func(tx *bbolt.Tx) error {
+		require.Equal(t, value, tx.Bucket(bucketName).Get(key))
+		return nil
+	})
+	require.NoError(t, err)
+}
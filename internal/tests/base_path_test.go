@@ -0,0 +1,104 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/contentforward/bolt-ui/internal/config"
+	httpPort "github.com/contentforward/bolt-ui/ports/http"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/bbolt"
+)
+
+func TestBasePath(t *testing.T) {
+	testApp := NewTracker(t)
+
+	err := testApp.DB.Update(func(tx *bbolt.Tx) error {
+		_, err := tx.CreateBucket([]byte("bucket"))
+		return err
+	})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		Name     string
+		BasePath string
+		Path     string
+		Status   int
+		Location string
+	}{
+		{
+			Name:   "root_without_base_path",
+			Path:   "/api/keys/6275636b6574",
+			Status: http.StatusOK,
+		},
+		{
+			Name:     "with_prefix",
+			BasePath: "/dbadmin",
+			Path:     "/dbadmin/api/keys/6275636b6574",
+			Status:   http.StatusOK,
+		},
+		{
+			Name:     "without_prefix",
+			BasePath: "/dbadmin",
+			Path:     "/api/keys/6275636b6574",
+			Status:   http.StatusNotFound,
+		},
+		{
+			Name:     "bare_root",
+			BasePath: "/dbadmin",
+			Path:     "/?token=secret",
+			Status:   http.StatusFound,
+			Location: "/dbadmin/?token=secret",
+		},
+		{
+			Name:     "base_path_without_slash",
+			BasePath: "/dbadmin",
+			Path:     "/dbadmin",
+			Status:   http.StatusFound,
+			Location: "/dbadmin/",
+		},
+		{
+			Name:     "similar_prefix",
+			BasePath: "/dbadmin",
+			Path:     "/dbadmin2/api/keys/6275636b6574",
+			Status:   http.StatusNotFound,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			conf := &config.Config{
+				InsecureToken: true,
+				BasePath:      testCase.BasePath,
+			}
+
+			handler, err := httpPort.NewHandler(testDatabases{testApp.Application}, httpPort.NewTokenAuthProvider(conf), conf)
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, testCase.Path, nil))
+
+			require.Equal(t, testCase.Status, recorder.Code)
+			require.Equal(t, testCase.Location, recorder.Header().Get("Location"))
+		})
+	}
+}
+
+func TestBasePathFrontend(t *testing.T) {
+	testApp := NewTracker(t)
+
+	conf := &config.Config{
+		InsecureToken: true,
+		BasePath:      "/dbadmin",
+	}
+
+	handler, err := httpPort.NewHandler(testDatabases{testApp.Application}, httpPort.NewTokenAuthProvider(conf), conf)
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/dbadmin/", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Contains(t, recorder.Body.String(), `src="/dbadmin/js/`)
+	require.NotContains(t, recorder.Body.String(), `src="/js/`)
+}
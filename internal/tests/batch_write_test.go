@@ -0,0 +1,157 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/contentforward/bolt-ui/application"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/bbolt"
+)
+
+func TestBatchWrite(t *testing.T) {
+	bucketName := []byte("bucket")
+
+	testCases := []struct {
+		Name           string
+		Operations     []application.KeyOperation
+		ExpectedErr    error
+		ExpectedValues map[string]string
+	}{
+		{
+			Name: "put_and_delete",
+			Operations: []application.KeyOperation{
+				{
+					Type:  application.KeyOperationTypePut,
+					Key:   application.MustNewKey([]byte("new")),
+					Value: application.MustNewValue([]byte("new value")),
+				},
+				{
+					Type:  application.KeyOperationTypePut,
+					Key:   application.MustNewKey([]byte("existing")),
+					Value: application.MustNewValue([]byte("updated value")),
+				},
+				{
+					Type: application.KeyOperationTypeDelete,
+					Key:  application.MustNewKey([]byte("deleted")),
+				},
+			},
+			ExpectedValues: map[string]string{
+				"new":      "new value",
+				"existing": "updated value",
+			},
+		},
+		{
+			Name: "failed_operation_rolls_back_the_batch",
+			Operations: []application.KeyOperation{
+				{
+					Type:  application.KeyOperationTypePut,
+					Key:   application.MustNewKey([]byte("new")),
+					Value: application.MustNewValue([]byte("new value")),
+				},
+				{
+					Type: application.KeyOperationTypeDelete,
+					Key:  application.MustNewKey([]byte("missing")),
+				},
+			},
+			ExpectedErr: application.ErrKeyNotFound,
+			ExpectedValues: map[string]string{
+				"existing": "value",
+				"deleted":  "value",
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			testApp := NewTracker(t)
+
+			err := testApp.DB.Update(func(tx *bbolt.Tx) error {
+				bucket, err := tx.CreateBucket(bucketName)
+				if err != nil {
+					return err
+				}
+
+				if err := bucket.Put([]byte("existing"), []byte("value")); err != nil {
+					return err
+				}
+
+				return bucket.Put([]byte("deleted"), []byte("value"))
+			})
+			require.NoError(t, err)
+
+			err = testApp.Application.BatchWrite.Execute(
+				application.BatchWrite{
+					Path:       []application.Key{application.MustNewKey(bucketName)},
+					Operations: testCase.Operations,
+				},
+			)
+			if testCase.ExpectedErr != nil {
+				require.ErrorIs(t, err, testCase.ExpectedErr)
+			} else {
+				require.NoError(t, err)
+			}
+
+			values := make(map[string]string)
+			err = testApp.DB.View(func(tx *bbolt.Tx) error {
+				return tx.Bucket(bucketName).ForEach(func(k, v []byte) error {
+					values[string(k)] = string(v)
+					return nil
+				})
+			})
+			require.NoError(t, err)
+			require.Equal(t, testCase.ExpectedValues, values)
+		})
+	}
+}
+
+func TestBatchWriteValidatesOperations(t *testing.T) {
+	testApp := NewTracker(t)
+
+	path := []application.Key{application.MustNewKey([]byte("bucket"))}
+
+	testCases := []struct {
+		Name       string
+		Path       []application.Key
+		Operations []application.KeyOperation
+	}{
+		{
+			Name: "root",
+			Path: nil,
+			Operations: []application.KeyOperation{
+				{Type: application.KeyOperationTypeDelete, Key: application.MustNewKey([]byte("key"))},
+			},
+		},
+		{
+			Name:       "no_operations",
+			Path:       path,
+			Operations: nil,
+		},
+		{
+			Name: "empty_key",
+			Path: path,
+			Operations: []application.KeyOperation{
+				{Type: application.KeyOperationTypeDelete},
+			},
+		},
+		{
+			Name: "invalid_type",
+			Path: path,
+			Operations: []application.KeyOperation{
+				{Type: "invalid", Key: application.MustNewKey([]byte("key"))},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			err := testApp.Application.BatchWrite.Execute(
+				application.BatchWrite{
+					Path:       testCase.Path,
+					Operations: testCase.Operations,
+				},
+			)
+			require.Error(t, err)
+			require.NotErrorIs(t, err, application.ErrBucketNotFound, "validation should happen before the transaction")
+		})
+	}
+}
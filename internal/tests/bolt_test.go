@@ -0,0 +1,65 @@
+package tests
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/contentforward/bolt-ui/adapters"
+	"github.com/contentforward/bolt-ui/internal/fixture"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/bbolt"
+)
+
+func TestNewBoltTimesOutIfDatabaseIsLocked(t *testing.T) {
+	file, cleanup := fixture.File(t)
+	defer cleanup()
+
+	db, err := adapters.NewBolt(file, false, adapters.DefaultOpenTimeout)
+	require.NoError(t, err)
+	defer db.Close()
+
+	timeout := 100 * time.Millisecond
+
+	result := make(chan error)
+
+	go func() {
+		otherDB, err := adapters.NewBolt(file, false, timeout)
+		if err == nil {
+			otherDB.Close()
+		}
+		result <- err
+	}()
+
+	select {
+	case err := <-result:
+		require.ErrorIs(t, err, bbolt.ErrTimeout)
+		require.Contains(t, err.Error(), "database is locked")
+	case <-time.After(10 * timeout):
+		t.Fatal("opening a locked database should time out")
+	}
+}
+
+func TestNewBoltDoesNotCreateMissingFiles(t *testing.T) {
+	file, cleanup := fixture.File(t)
+	cleanup()
+
+	_, err := adapters.NewBolt(file, false, adapters.DefaultOpenTimeout)
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	_, err = os.Stat(file)
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestCreateBoltCreatesMissingFiles(t *testing.T) {
+	file, cleanup := fixture.File(t)
+	cleanup()
+
+	db, err := adapters.CreateBolt(file, adapters.DefaultOpenTimeout)
+	require.NoError(t, err)
+	defer os.Remove(file)
+	defer db.Close()
+
+	_, err = os.Stat(file)
+	require.NoError(t, err)
+}
@@ -0,0 +1,661 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+
+	"github.com/contentforward/bolt-ui/application"
+	"github.com/contentforward/bolt-ui/internal/wire"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/bbolt"
+)
+
+func TestCreateBucket(t *testing.T) {
+	testApp := NewTracker(t)
+
+	bucketName := []byte("bucket")
+	childBucketName := []byte("child")
+	valueKey := []byte("key")
+
+	err := testApp.Application.CreateBucket.Execute(
+		application.CreateBucket{
+			Path: nil,
+			Name: application.MustNewKey(bucketName),
+		},
+	)
+	require.NoError(t, err)
+
+	path := []application.Key{
+		application.MustNewKey(bucketName),
+	}
+
+	err = testApp.Application.CreateBucket.Execute(
+		application.CreateBucket{
+			Path: path,
+			Name: application.MustNewKey(childBucketName),
+		},
+	)
+	require.NoError(t, err)
+
+	err = testApp.DB.View(func(tx *bbolt.Tx) error {
+		require.NotNil(t, tx.Bucket(bucketName).Bucket(childBucketName))
+		return nil
+	})
+	require.NoError(t, err)
+
+	err = testApp.Application.CreateBucket.Execute(
+		application.CreateBucket{
+			Path: path,
+			Name: application.MustNewKey(childBucketName),
+		},
+	)
+	require.ErrorIs(t, err, application.ErrBucketExists)
+
+	err = testApp.Application.PutValue.Execute(
+		application.PutValue{
+			Path:  path,
+			Key:   application.MustNewKey(valueKey),
+			Value: application.MustNewValue([]byte("value")),
+		},
+	)
+	require.NoError(t, err)
+
+	err = testApp.Application.CreateBucket.Execute(
+		application.CreateBucket{
+			Path: path,
+			Name: application.MustNewKey(valueKey),
+		},
+	)
+	require.ErrorIs(t, err, application.ErrValueExists)
+
+	err = testApp.Application.CreateBucket.Execute(
+		application.CreateBucket{
+			Path: []application.Key{
+				application.MustNewKey([]byte("missing")),
+			},
+			Name: application.MustNewKey(childBucketName),
+		},
+	)
+	require.ErrorIs(t, err, application.ErrBucketNotFound)
+}
+
+func TestDeleteBucket(t *testing.T) {
+	testApp := NewTracker(t)
+
+	bucketName := []byte("bucket")
+	childBucketName := []byte("child")
+	valueKey := []byte("key")
+
+	err := testApp.DB.Update(func(tx *bbolt.Tx) error {
+		bucket, err := tx.CreateBucket(bucketName)
+		if err != nil {
+			return err
+		}
+
+		if err := bucket.Put(valueKey, []byte("value")); err != nil {
+			return err
+		}
+
+		child, err := bucket.CreateBucket(childBucketName)
+		if err != nil {
+			return err
+		}
+
+		if _, err := child.CreateBucket(childBucketName); err != nil {
+			return err
+		}
+
+		if err := child.Put(valueKey, []byte("value")); err != nil {
+			return err
+		}
+
+		return child.Put(bucketName, nil)
+	})
+	require.NoError(t, err)
+
+	path := []application.Key{
+		application.MustNewKey(bucketName),
+	}
+
+	contents, err := testApp.Application.CountBucketContents.Execute(
+		application.CountBucketContents{
+			Context: context.Background(),
+			Path:    path,
+		},
+	)
+	require.NoError(t, err)
+	require.Equal(t,
+		application.BucketContents{
+			Values:  3,
+			Buckets: 2,
+		},
+		contents,
+	)
+
+	err = testApp.Application.DeleteBucket.Execute(
+		application.DeleteBucket{
+			Path: append(path, application.MustNewKey(valueKey)),
+		},
+	)
+	require.ErrorIs(t, err, application.ErrNotABucket)
+
+	err = testApp.Application.DeleteBucket.Execute(
+		application.DeleteBucket{
+			Path: append(path, application.MustNewKey(childBucketName)),
+		},
+	)
+	require.NoError(t, err)
+
+	contents, err = testApp.Application.CountBucketContents.Execute(
+		application.CountBucketContents{
+			Context: context.Background(),
+			Path:    path,
+		},
+	)
+	require.NoError(t, err)
+	require.Equal(t,
+		application.BucketContents{
+			Values:  1,
+			Buckets: 0,
+		},
+		contents,
+	)
+
+	err = testApp.Application.DeleteBucket.Execute(
+		application.DeleteBucket{
+			Path: path,
+		},
+	)
+	require.NoError(t, err)
+
+	err = testApp.Application.DeleteBucket.Execute(
+		application.DeleteBucket{
+			Path: path,
+		},
+	)
+	require.ErrorIs(t, err, application.ErrBucketNotFound)
+}
+
+func TestPreviewDeleteBucket(t *testing.T) {
+	testApp := NewTracker(t)
+
+	bucketName := []byte("bucket")
+	childBucketName := []byte("child")
+	valueKey := []byte("key")
+
+	err := testApp.DB.Update(func(tx *bbolt.Tx) error {
+		bucket, err := tx.CreateBucket(bucketName)
+		if err != nil {
+			return err
+		}
+
+		child, err := bucket.CreateBucket(childBucketName)
+		if err != nil {
+			return err
+		}
+
+		if err := child.Put(valueKey, []byte("value")); err != nil {
+			return err
+		}
+
+		return bucket.Put(valueKey, []byte("value"))
+	})
+	require.NoError(t, err)
+
+	path := []application.Key{
+		application.MustNewKey(bucketName),
+	}
+
+	preview, err := testApp.Application.PreviewDeleteBucket.Execute(
+		application.PreviewDeleteBucket{
+			Context: context.Background(),
+			Path:    path,
+		},
+	)
+	require.NoError(t, err)
+	require.Equal(t,
+		application.BucketContents{
+			Values:  2,
+			Buckets: 1,
+		},
+		preview.Contents,
+	)
+	require.Equal(t,
+		[]application.KeyInfo{
+			{Bucket: true, Key: application.MustNewKey(childBucketName)},
+			{Bucket: false, Key: application.MustNewKey(valueKey)},
+		},
+		preview.Sample,
+	)
+	require.NotEmpty(t, preview.Confirmation)
+
+	err = testApp.Application.DeleteBucket.Execute(
+		application.DeleteBucket{
+			Path:         path,
+			Confirmation: "invalid",
+		},
+	)
+	require.ErrorIs(t, err, application.ErrConfirmationMismatch)
+
+	err = testApp.Application.PutValue.Execute(
+		application.PutValue{
+			Path:  path,
+			Key:   application.MustNewKey([]byte("other")),
+			Value: application.MustNewValue([]byte("value")),
+		},
+	)
+	require.NoError(t, err)
+
+	err = testApp.Application.DeleteBucket.Execute(
+		application.DeleteBucket{
+			Path:         path,
+			Confirmation: preview.Confirmation,
+		},
+	)
+	require.ErrorIs(t, err, application.ErrConfirmationMismatch, "the bucket was modified after the preview")
+
+	preview, err = testApp.Application.PreviewDeleteBucket.Execute(
+		application.PreviewDeleteBucket{
+			Context: context.Background(),
+			Path:    path,
+		},
+	)
+	require.NoError(t, err)
+
+	err = testApp.Application.DeleteBucket.Execute(
+		application.DeleteBucket{
+			Path:         path,
+			Confirmation: preview.Confirmation,
+		},
+	)
+	require.NoError(t, err)
+
+	_, err = testApp.Application.PreviewDeleteBucket.Execute(
+		application.PreviewDeleteBucket{
+			Context: context.Background(),
+			Path:    path,
+		},
+	)
+	require.ErrorIs(t, err, application.ErrBucketNotFound)
+}
+
+func TestCountBucketContentsHonorsContext(t *testing.T) {
+	testApp := NewTracker(t)
+
+	err := testApp.DB.Update(func(tx *bbolt.Tx) error {
+		bucket, err := tx.CreateBucket([]byte("bucket"))
+		if err != nil {
+			return err
+		}
+
+		for i := 0; i < 1000; i++ {
+			if err := bucket.Put([]byte{byte(i >> 8), byte(i)}, []byte("value")); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = testApp.Application.CountBucketContents.Execute(
+		application.CountBucketContents{
+			Context: ctx,
+			Path: []application.Key{
+				application.MustNewKey([]byte("bucket")),
+			},
+		},
+	)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestGetBucketStats(t *testing.T) {
+	testApp := NewTracker(t)
+
+	bucketName := []byte("bucket")
+
+	err := testApp.DB.Update(func(tx *bbolt.Tx) error {
+		bucket, err := tx.CreateBucket(bucketName)
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range mixedBucketEntries(10) {
+			if err := bucket.Put(entry.Key.Bytes(), []byte("value")); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	stats, err := testApp.Application.GetBucketStats.Execute(
+		application.GetBucketStats{
+			Path: []application.Key{
+				application.MustNewKey(bucketName),
+			},
+		},
+	)
+	require.NoError(t, err)
+	require.Equal(t, 10, stats.KeyN)
+	require.Equal(t, 1, stats.BucketN)
+
+	_, err = testApp.Application.GetBucketStats.Execute(
+		application.GetBucketStats{
+			Path: []application.Key{
+				application.MustNewKey([]byte("missing")),
+			},
+		},
+	)
+	require.ErrorIs(t, err, application.ErrBucketNotFound)
+}
+
+func TestGetBucketTree(t *testing.T) {
+	testApp := NewTracker(t)
+
+	err := testApp.DB.Update(func(tx *bbolt.Tx) error {
+		bucket, err := tx.CreateBucket([]byte("a"))
+		if err != nil {
+			return err
+		}
+
+		if err := bucket.Put([]byte("key"), []byte("value")); err != nil {
+			return err
+		}
+
+		for _, name := range []string{"b", "c", "d"} {
+			bucket, err = bucket.CreateBucket([]byte(name))
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := bucket.Put([]byte("key"), []byte("value")); err != nil {
+			return err
+		}
+
+		_, err = tx.CreateBucket([]byte("e"))
+		return err
+	})
+	require.NoError(t, err)
+
+	tree, err := testApp.Application.GetBucketTree.Execute(
+		application.GetBucketTree{
+			Context: context.Background(),
+		},
+	)
+	require.NoError(t, err)
+	require.Equal(t, 3, wire.TestBucketTreeMaxDepth, "the expected tree depends on the maximum depth")
+	require.Equal(t,
+		[]application.BucketTreeNode{
+			{
+				Name: application.MustNewKey([]byte("a")),
+				KeyN: 5,
+				Buckets: []application.BucketTreeNode{
+					{
+						Name: application.MustNewKey([]byte("b")),
+						KeyN: 3,
+						Buckets: []application.BucketTreeNode{
+							{
+								Name:      application.MustNewKey([]byte("c")),
+								KeyN:      2,
+								Truncated: true,
+							},
+						},
+					},
+				},
+			},
+			{
+				Name:    application.MustNewKey([]byte("e")),
+				KeyN:    0,
+				Buckets: []application.BucketTreeNode{},
+			},
+		},
+		tree,
+	)
+
+	tree, err = testApp.Application.GetBucketTree.Execute(
+		application.GetBucketTree{
+			Context: context.Background(),
+			Path: []application.Key{
+				application.MustNewKey([]byte("a")),
+				application.MustNewKey([]byte("b")),
+			},
+		},
+	)
+	require.NoError(t, err)
+	require.Equal(t,
+		[]application.BucketTreeNode{
+			{
+				Name: application.MustNewKey([]byte("c")),
+				KeyN: 2,
+				Buckets: []application.BucketTreeNode{
+					{
+						Name:    application.MustNewKey([]byte("d")),
+						KeyN:    1,
+						Buckets: []application.BucketTreeNode{},
+					},
+				},
+			},
+		},
+		tree,
+	)
+
+	_, err = testApp.Application.GetBucketTree.Execute(
+		application.GetBucketTree{
+			Context: context.Background(),
+			Path: []application.Key{
+				application.MustNewKey([]byte("missing")),
+			},
+		},
+	)
+	require.ErrorIs(t, err, application.ErrBucketNotFound)
+}
+
+func TestGetDatabaseStats(t *testing.T) {
+	testApp := NewTracker(t)
+
+	err := testApp.DB.Update(func(tx *bbolt.Tx) error {
+		_, err := tx.CreateBucket([]byte("bucket"))
+		return err
+	})
+	require.NoError(t, err)
+
+	stats, err := testApp.Application.GetDatabaseStats.Execute(application.GetDatabaseStats{})
+	require.NoError(t, err)
+	require.Positive(t, stats.Size)
+	require.Positive(t, stats.TxN)
+	require.Equal(t, 1, stats.OpenTxN, "the transaction used to get the stats should be open")
+}
+
+func TestGetFileInfo(t *testing.T) {
+	testApp := NewTracker(t)
+
+	info, err := testApp.Application.GetFileInfo.Execute(application.GetFileInfo{})
+	require.NoError(t, err)
+
+	stat, err := os.Stat(testApp.DB.Path())
+	require.NoError(t, err)
+
+	require.Equal(t, testApp.DB.Path(), info.Path)
+	require.Equal(t, stat.Size(), info.Size)
+	require.Equal(t, stat.ModTime(), info.Modified)
+	require.False(t, info.ReadOnly)
+}
+
+func TestMoveBucket(t *testing.T) {
+	a := application.MustNewKey([]byte("a"))
+	b := application.MustNewKey([]byte("b"))
+	child := application.MustNewKey([]byte("child"))
+	value := application.MustNewKey([]byte("value"))
+
+	testCases := []struct {
+		Name           string
+		Cmd            application.MoveBucket
+		ExpectedErr    error
+		ExpectedExport string
+	}{
+		{
+			Name: "rename",
+			Cmd: application.MoveBucket{
+				Path:              []application.Key{a, child},
+				DestinationParent: []application.Key{a},
+				Name:              application.MustNewKey([]byte("renamed")),
+			},
+			ExpectedExport: `[{"key":"a","keyEncoding":"utf8","bucket":[{"key":"renamed","keyEncoding":"utf8","bucket":[{"key":"k","keyEncoding":"utf8","value":"dg=="},{"key":"nested","keyEncoding":"utf8","bucket":[{"key":"k","keyEncoding":"utf8","value":"dg=="}]}]},{"key":"value","keyEncoding":"utf8","value":"dg=="}]},{"key":"b","keyEncoding":"utf8","bucket":[]}]`,
+		},
+		{
+			Name: "move_to_another_parent",
+			Cmd: application.MoveBucket{
+				Path:              []application.Key{a, child},
+				DestinationParent: []application.Key{b},
+				Name:              child,
+			},
+			ExpectedExport: `[{"key":"a","keyEncoding":"utf8","bucket":[{"key":"value","keyEncoding":"utf8","value":"dg=="}]},{"key":"b","keyEncoding":"utf8","bucket":[{"key":"child","keyEncoding":"utf8","bucket":[{"key":"k","keyEncoding":"utf8","value":"dg=="},{"key":"nested","keyEncoding":"utf8","bucket":[{"key":"k","keyEncoding":"utf8","value":"dg=="}]}]}]}]`,
+		},
+		{
+			Name: "move_to_root",
+			Cmd: application.MoveBucket{
+				Path:              []application.Key{a, child},
+				DestinationParent: nil,
+				Name:              child,
+			},
+			ExpectedExport: `[{"key":"a","keyEncoding":"utf8","bucket":[{"key":"value","keyEncoding":"utf8","value":"dg=="}]},{"key":"b","keyEncoding":"utf8","bucket":[]},{"key":"child","keyEncoding":"utf8","bucket":[{"key":"k","keyEncoding":"utf8","value":"dg=="},{"key":"nested","keyEncoding":"utf8","bucket":[{"key":"k","keyEncoding":"utf8","value":"dg=="}]}]}]`,
+		},
+		{
+			Name: "move_into_itself",
+			Cmd: application.MoveBucket{
+				Path:              []application.Key{a},
+				DestinationParent: []application.Key{a},
+				Name:              b,
+			},
+			ExpectedErr: application.ErrMoveIntoDescendant,
+		},
+		{
+			Name: "move_into_descendant",
+			Cmd: application.MoveBucket{
+				Path:              []application.Key{a},
+				DestinationParent: []application.Key{a, child},
+				Name:              a,
+			},
+			ExpectedErr: application.ErrMoveIntoDescendant,
+		},
+		{
+			Name: "destination_bucket_exists",
+			Cmd: application.MoveBucket{
+				Path:              []application.Key{a},
+				DestinationParent: nil,
+				Name:              b,
+			},
+			ExpectedErr: application.ErrBucketExists,
+		},
+		{
+			Name: "destination_value_exists",
+			Cmd: application.MoveBucket{
+				Path:              []application.Key{a, child},
+				DestinationParent: []application.Key{a},
+				Name:              value,
+			},
+			ExpectedErr: application.ErrValueExists,
+		},
+		{
+			Name: "same_path",
+			Cmd: application.MoveBucket{
+				Path:              []application.Key{a, child},
+				DestinationParent: []application.Key{a},
+				Name:              child,
+			},
+			ExpectedExport: `[{"key":"a","keyEncoding":"utf8","bucket":[{"key":"child","keyEncoding":"utf8","bucket":[{"key":"k","keyEncoding":"utf8","value":"dg=="},{"key":"nested","keyEncoding":"utf8","bucket":[{"key":"k","keyEncoding":"utf8","value":"dg=="}]}]},{"key":"value","keyEncoding":"utf8","value":"dg=="}]},{"key":"b","keyEncoding":"utf8","bucket":[]}]`,
+		},
+		{
+			Name: "same_path_does_not_exist",
+			Cmd: application.MoveBucket{
+				Path:              []application.Key{a, application.MustNewKey([]byte("missing"))},
+				DestinationParent: []application.Key{a},
+				Name:              application.MustNewKey([]byte("missing")),
+			},
+			ExpectedErr: application.ErrBucketNotFound,
+		},
+		{
+			Name: "same_path_is_a_value",
+			Cmd: application.MoveBucket{
+				Path:              []application.Key{a, value},
+				DestinationParent: []application.Key{a},
+				Name:              value,
+			},
+			ExpectedErr: application.ErrNotABucket,
+		},
+		{
+			Name: "destination_parent_does_not_exist",
+			Cmd: application.MoveBucket{
+				Path:              []application.Key{a, child},
+				DestinationParent: []application.Key{application.MustNewKey([]byte("missing"))},
+				Name:              child,
+			},
+			ExpectedErr: application.ErrBucketNotFound,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			testApp := NewTracker(t)
+
+			err := testApp.DB.Update(func(tx *bbolt.Tx) error {
+				bucketA, err := tx.CreateBucket(a.Bytes())
+				if err != nil {
+					return err
+				}
+
+				if err := bucketA.Put(value.Bytes(), []byte("v")); err != nil {
+					return err
+				}
+
+				childBucket, err := bucketA.CreateBucket(child.Bytes())
+				if err != nil {
+					return err
+				}
+
+				if err := childBucket.Put([]byte("k"), []byte("v")); err != nil {
+					return err
+				}
+
+				nested, err := childBucket.CreateBucket([]byte("nested"))
+				if err != nil {
+					return err
+				}
+
+				if err := nested.Put([]byte("k"), []byte("v")); err != nil {
+					return err
+				}
+
+				_, err = tx.CreateBucket(b.Bytes())
+				return err
+			})
+			require.NoError(t, err)
+
+			before := &bytes.Buffer{}
+			err = testApp.Application.ExportBucket.Execute(application.ExportBucket{Context: context.Background(), Writer: before})
+			require.NoError(t, err)
+
+			err = testApp.Application.MoveBucket.Execute(testCase.Cmd)
+
+			after := &bytes.Buffer{}
+			exportErr := testApp.Application.ExportBucket.Execute(application.ExportBucket{Context: context.Background(), Writer: after})
+			require.NoError(t, exportErr)
+
+			if testCase.ExpectedErr != nil {
+				require.ErrorIs(t, err, testCase.ExpectedErr)
+				require.JSONEq(t, before.String(), after.String())
+			} else {
+				require.NoError(t, err)
+				require.JSONEq(t, testCase.ExpectedExport, after.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/contentforward/bolt-ui/adapters"
+	"github.com/contentforward/bolt-ui/application"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache(t *testing.T) {
+	bucket := []application.Key{application.MustNewKey([]byte("bucket"))}
+	child := append(bucket, application.MustNewKey([]byte("child")))
+	other := []application.Key{application.MustNewKey([]byte("other"))}
+
+	testCases := []struct {
+		Name          string
+		TTL           time.Duration
+		Change        *application.Change
+		ExpectedLoads int
+	}{
+		{
+			Name:          "results_are_cached",
+			TTL:           time.Hour,
+			ExpectedLoads: 1,
+		},
+		{
+			Name:          "zero_ttl_disables_the_cache",
+			TTL:           0,
+			ExpectedLoads: 2,
+		},
+		{
+			Name:          "change_in_the_bucket_invalidates_the_cache",
+			TTL:           time.Hour,
+			Change:        &application.Change{Path: bucket},
+			ExpectedLoads: 2,
+		},
+		{
+			Name:          "change_in_a_child_bucket_invalidates_the_cache",
+			TTL:           time.Hour,
+			Change:        &application.Change{Path: child},
+			ExpectedLoads: 2,
+		},
+		{
+			Name:          "change_in_the_root_invalidates_the_cache",
+			TTL:           time.Hour,
+			Change:        &application.Change{},
+			ExpectedLoads: 2,
+		},
+		{
+			Name:          "change_in_an_unrelated_bucket_does_not_invalidate_the_cache",
+			TTL:           time.Hour,
+			Change:        &application.Change{Path: other},
+			ExpectedLoads: 1,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			cache := adapters.NewCache(testCase.TTL)
+
+			loads := 0
+			load := func() (interface{}, error) {
+				loads++
+				return loads, nil
+			}
+
+			v, err := cache.GetOrLoad("query", bucket, load)
+			require.NoError(t, err)
+			require.Equal(t, 1, v)
+
+			if testCase.Change != nil {
+				cache.Publish(*testCase.Change)
+			}
+
+			v, err = cache.GetOrLoad("query", bucket, load)
+			require.NoError(t, err)
+			require.Equal(t, testCase.ExpectedLoads, v)
+			require.Equal(t, testCase.ExpectedLoads, loads)
+		})
+	}
+}
+
+func TestCacheDoesNotCacheErrors(t *testing.T) {
+	cache := adapters.NewCache(time.Hour)
+	path := []application.Key{application.MustNewKey([]byte("bucket"))}
+
+	_, err := cache.GetOrLoad("query", path, func() (interface{}, error) {
+		return nil, errors.New("load failed")
+	})
+	require.EqualError(t, err, "load failed")
+
+	v, err := cache.GetOrLoad("query", path, func() (interface{}, error) {
+		return "value", nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, "value", v)
+}
+
+func TestCacheSeparatesQueries(t *testing.T) {
+	cache := adapters.NewCache(time.Hour)
+	path := []application.Key{application.MustNewKey([]byte("bucket"))}
+
+	a, err := cache.GetOrLoad("a", path, func() (interface{}, error) { return "a", nil })
+	require.NoError(t, err)
+	b, err := cache.GetOrLoad("b", path, func() (interface{}, error) { return "b", nil })
+	require.NoError(t, err)
+
+	require.Equal(t, "a", a)
+	require.Equal(t, "b", b)
+}
@@ -0,0 +1,86 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/contentforward/bolt-ui/internal/config"
+	httpPort "github.com/contentforward/bolt-ui/ports/http"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/bbolt"
+)
+
+func TestCanceledRequests(t *testing.T) {
+	testApp := NewTracker(t)
+
+	err := testApp.DB.Update(func(tx *bbolt.Tx) error {
+		bucket, err := tx.CreateBucket([]byte("bucket"))
+		if err != nil {
+			return err
+		}
+
+		for i := 0; i < 2000; i++ {
+			if err := bucket.Put([]byte{byte(i >> 8), byte(i)}, []byte("value")); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	conf := &config.Config{
+		InsecureToken: true,
+	}
+
+	handler, err := httpPort.NewHandler(testDatabases{testApp.Application}, httpPort.NewTokenAuthProvider(conf), conf)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		Name string
+		Path string
+	}{
+		{
+			Name: "search_all_buckets",
+			Path: "/api/search-all?pattern=value",
+		},
+		{
+			Name: "count_bucket_contents",
+			Path: "/api/contents/6275636b6574",
+		},
+		{
+			Name: "preview_delete_bucket",
+			Path: "/api/delete-preview/6275636b6574",
+		},
+		{
+			Name: "bucket_tree",
+			Path: "/api/tree/",
+		},
+		{
+			Name: "count_keys_by_prefix",
+			Path: "/api/count/6275636b6574?prefix=00",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, testCase.Path, nil).WithContext(ctx))
+			require.Equal(t, 499, recorder.Code)
+
+			var response struct {
+				Error struct {
+					Code string `json:"code"`
+				} `json:"error"`
+			}
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+			require.Equal(t, "canceled", response.Error.Code)
+		})
+	}
+}
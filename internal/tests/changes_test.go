@@ -0,0 +1,150 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/contentforward/bolt-ui/application"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubscribeToChanges(t *testing.T) {
+	bucket := application.MustNewKey([]byte("bucket"))
+	nested := application.MustNewKey([]byte("nested"))
+	other := application.MustNewKey([]byte("other"))
+	key := application.MustNewKey([]byte("key"))
+
+	testCases := []struct {
+		Name          string
+		Path          []application.Key
+		ExpectedPaths [][]application.Key
+	}{
+		{
+			Name: "root",
+			Path: nil,
+			ExpectedPaths: [][]application.Key{
+				nil,
+				nil,
+				{bucket},
+				{bucket, nested},
+				{bucket},
+				{bucket},
+				{bucket},
+			},
+		},
+		{
+			Name: "bucket",
+			Path: []application.Key{bucket},
+			ExpectedPaths: [][]application.Key{
+				nil,
+				nil,
+				{bucket},
+				{bucket, nested},
+				{bucket},
+				{bucket},
+				{bucket},
+			},
+		},
+		{
+			Name: "other",
+			Path: []application.Key{other},
+			ExpectedPaths: [][]application.Key{
+				nil,
+				nil,
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			testApp := NewTracker(t)
+
+			changes, unsubscribe := testApp.Application.SubscribeToChanges.Execute(
+				application.SubscribeToChanges{
+					Path: testCase.Path,
+				},
+			)
+
+			err := testApp.Application.CreateBucket.Execute(application.CreateBucket{Name: bucket})
+			require.NoError(t, err)
+
+			err = testApp.Application.CreateBucket.Execute(application.CreateBucket{Name: other})
+			require.NoError(t, err)
+
+			err = testApp.Application.CreateBucket.Execute(application.CreateBucket{Path: []application.Key{bucket}, Name: nested})
+			require.NoError(t, err)
+
+			err = testApp.Application.PutValue.Execute(application.PutValue{
+				Path:  []application.Key{bucket, nested},
+				Key:   key,
+				Value: application.MustNewValue([]byte("value")),
+			})
+			require.NoError(t, err)
+
+			err = testApp.Application.PutValue.Execute(application.PutValue{
+				Path:  []application.Key{bucket},
+				Key:   key,
+				Value: application.MustNewValue([]byte("value")),
+			})
+			require.NoError(t, err)
+
+			err = testApp.Application.DeleteKey.Execute(application.DeleteKey{
+				Path: []application.Key{bucket},
+				Key:  key,
+			})
+			require.NoError(t, err)
+
+			err = testApp.Application.DeleteBucket.Execute(application.DeleteBucket{
+				Path: []application.Key{bucket, nested},
+			})
+			require.NoError(t, err)
+
+			unsubscribe()
+
+			var paths [][]application.Key
+			for change := range changes {
+				paths = append(paths, change.Path)
+			}
+
+			require.Equal(t, len(testCase.ExpectedPaths), len(paths))
+			for i := range paths {
+				require.Equal(t, toStrings(testCase.ExpectedPaths[i]), toStrings(paths[i]))
+			}
+		})
+	}
+}
+
+func TestSubscribeToChangesDropsChangesIfSubscriberIsSlow(t *testing.T) {
+	testApp := NewTracker(t)
+
+	bucket := application.MustNewKey([]byte("bucket"))
+
+	err := testApp.Application.CreateBucket.Execute(application.CreateBucket{Name: bucket})
+	require.NoError(t, err)
+
+	changes, unsubscribe := testApp.Application.SubscribeToChanges.Execute(application.SubscribeToChanges{})
+
+	for i := 0; i < 1000; i++ {
+		err := testApp.Application.PutValue.Execute(application.PutValue{
+			Path:  []application.Key{bucket},
+			Key:   application.MustNewKey([]byte("key")),
+			Value: application.MustNewValue([]byte("value")),
+		})
+		require.NoError(t, err, "writes should not block")
+	}
+
+	unsubscribe()
+
+	n := 0
+	for range changes {
+		n++
+	}
+	require.Less(t, n, 1000)
+}
+
+func toStrings(path []application.Key) []string {
+	var result []string
+	for _, key := range path {
+		result = append(result, string(key.Bytes()))
+	}
+	return result
+}
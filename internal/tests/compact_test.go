@@ -0,0 +1,105 @@
+package tests
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/contentforward/bolt-ui/adapters"
+	"github.com/contentforward/bolt-ui/application"
+	"github.com/contentforward/bolt-ui/internal/fixture"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/bbolt"
+)
+
+func TestCompact(t *testing.T) {
+	file, cleanup := fixture.File(t)
+	defer cleanup()
+
+	db, err := adapters.NewBolt(file, false, adapters.DefaultOpenTimeout)
+	require.NoError(t, err)
+
+	databaseFile := adapters.NewDatabaseFile(db)
+	defer databaseFile.Close()
+
+	value := make([]byte, 1024)
+
+	err = databaseFile.Update(func(tx *bbolt.Tx) error {
+		bucket, err := tx.CreateBucket([]byte("bucket"))
+		if err != nil {
+			return err
+		}
+
+		if err := bucket.SetSequence(10); err != nil {
+			return err
+		}
+
+		child, err := bucket.CreateBucket([]byte("child"))
+		if err != nil {
+			return err
+		}
+
+		if err := child.Put([]byte("key"), []byte("value")); err != nil {
+			return err
+		}
+
+		removed, err := tx.CreateBucket([]byte("removed"))
+		if err != nil {
+			return err
+		}
+
+		for i := 0; i < 1000; i++ {
+			if err := removed.Put([]byte(fmt.Sprintf("key%d", i)), value); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	err = databaseFile.Update(func(tx *bbolt.Tx) error {
+		return tx.DeleteBucket([]byte("removed"))
+	})
+	require.NoError(t, err)
+
+	result, err := databaseFile.Compact()
+	require.NoError(t, err)
+	require.Less(t, result.SizeAfter, result.SizeBefore)
+
+	err = databaseFile.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte("bucket"))
+		require.NotNil(t, bucket)
+		require.Equal(t, uint64(10), bucket.Sequence())
+
+		child := bucket.Bucket([]byte("child"))
+		require.NotNil(t, child)
+		require.Equal(t, []byte("value"), child.Get([]byte("key")))
+
+		require.Nil(t, tx.Bucket([]byte("removed")))
+		return nil
+	})
+	require.NoError(t, err)
+
+	err = databaseFile.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket([]byte("bucket")).Put([]byte("key"), []byte("value"))
+	})
+	require.NoError(t, err)
+}
+
+func TestCompactReadOnly(t *testing.T) {
+	file, cleanup := fixture.File(t)
+	defer cleanup()
+
+	db, err := adapters.NewBolt(file, false, adapters.DefaultOpenTimeout)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	db, err = adapters.NewBolt(file, true, adapters.DefaultOpenTimeout)
+	require.NoError(t, err)
+
+	databaseFile := adapters.NewDatabaseFile(db)
+	defer databaseFile.Close()
+
+	_, err = databaseFile.Compact()
+	require.ErrorIs(t, err, application.ErrReadOnly)
+}
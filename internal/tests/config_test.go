@@ -0,0 +1,166 @@
+package tests
+
+import (
+	"crypto/tls"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/contentforward/bolt-ui/internal/config"
+	"github.com/contentforward/bolt-ui/internal/fixture"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigValidate(t *testing.T) {
+	file, cleanup := fixture.File(t)
+	defer cleanup()
+
+	require.NoError(t, os.WriteFile(file, nil, 0600))
+
+	missingFile := file + ".missing"
+
+	testCases := []struct {
+		Name        string
+		Modify      func(conf *config.Config)
+		ExpectedErr string
+	}{
+		{
+			Name:   "valid",
+			Modify: func(conf *config.Config) {},
+		},
+		{
+			Name: "empty_address",
+			Modify: func(conf *config.Config) {
+				conf.ServeAddress = ""
+			},
+			ExpectedErr: "serve address is empty",
+		},
+		{
+			Name: "invalid_port",
+			Modify: func(conf *config.Config) {
+				conf.ServeAddress = "localhost:port"
+			},
+			ExpectedErr: "serve address 'localhost:port' has an invalid port",
+		},
+		{
+			Name: "missing_token",
+			Modify: func(conf *config.Config) {
+				conf.InsecureToken = false
+			},
+			ExpectedErr: "token is not set",
+		},
+		{
+			Name: "missing_certificate",
+			Modify: func(conf *config.Config) {
+				conf.InsecureTLS = false
+				conf.Certificate = tls.Certificate{}
+			},
+			ExpectedErr: "TLS certificate is not set",
+		},
+		{
+			Name: "auth_backoff_max_shorter_than_auth_backoff",
+			Modify: func(conf *config.Config) {
+				conf.AuthBackoff = time.Minute
+				conf.AuthBackoffMax = time.Second
+			},
+			ExpectedErr: "auth backoff max can't be shorter than auth backoff",
+		},
+		{
+			Name: "missing_database_files_are_checked_separately",
+			Modify: func(conf *config.Config) {
+				conf.Databases = append(conf.Databases, config.Database{Name: "missing", File: missingFile})
+			},
+		},
+		{
+			Name: "all_problems_are_reported",
+			Modify: func(conf *config.Config) {
+				conf.ServeAddress = ""
+				conf.OpenTimeout = 0
+				conf.RateLimit = -1
+			},
+			ExpectedErr: "serve address is empty; open timeout must be positive; rate limit can't be negative",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			conf := &config.Config{
+				ServeAddress: ":8118",
+				Databases: []config.Database{
+					{
+						Name: "database",
+						File: file,
+					},
+				},
+				InsecureToken: true,
+				InsecureTLS:   true,
+				OpenMode:      config.OpenModeReadWrite,
+				OpenTimeout:   time.Second,
+			}
+
+			testCase.Modify(conf)
+
+			err := conf.Validate()
+			if testCase.ExpectedErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, testCase.ExpectedErr)
+			}
+		})
+	}
+}
+
+func TestConfigValidateDatabase(t *testing.T) {
+	file, cleanup := fixture.File(t)
+	defer cleanup()
+
+	require.NoError(t, os.WriteFile(file, nil, 0600))
+
+	missingFile := file + ".missing"
+	missingDirectory := filepath.Join(file+".missing", "file")
+
+	testCases := []struct {
+		Name            string
+		File            string
+		CreateIfMissing bool
+		ExpectedErr     string
+	}{
+		{
+			Name: "existing",
+			File: file,
+		},
+		{
+			Name:        "missing",
+			File:        missingFile,
+			ExpectedErr: "file '" + missingFile + "' does not exist",
+		},
+		{
+			Name:            "missing_created",
+			File:            missingFile,
+			CreateIfMissing: true,
+		},
+		{
+			Name:            "missing_directory",
+			File:            missingDirectory,
+			CreateIfMissing: true,
+			ExpectedErr:     "directory '" + filepath.Dir(missingDirectory) + "' does not exist",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			conf := &config.Config{
+				OpenMode:        config.OpenModeReadWrite,
+				CreateIfMissing: testCase.CreateIfMissing,
+			}
+
+			err := conf.ValidateDatabase(config.Database{Name: "database", File: testCase.File})
+			if testCase.ExpectedErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, testCase.ExpectedErr)
+			}
+		})
+	}
+}
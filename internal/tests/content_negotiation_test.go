@@ -0,0 +1,103 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/contentforward/bolt-ui/internal/config"
+	httpPort "github.com/contentforward/bolt-ui/ports/http"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/bbolt"
+)
+
+func TestRawValueContentNegotiation(t *testing.T) {
+	testApp := NewTracker(t)
+
+	err := testApp.DB.Update(func(tx *bbolt.Tx) error {
+		bucket, err := tx.CreateBucket([]byte("bucket"))
+		if err != nil {
+			return err
+		}
+
+		if err := bucket.Put([]byte("json"), []byte(`{"a": 1}`)); err != nil {
+			return err
+		}
+
+		return bucket.Put([]byte("text"), []byte("text"))
+	})
+	require.NoError(t, err)
+
+	conf := &config.Config{
+		InsecureToken: true,
+	}
+
+	handler, err := httpPort.NewHandler(testDatabases{testApp.Application}, httpPort.NewTokenAuthProvider(conf), conf)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		Name                string
+		Key                 string
+		Accept              string
+		ExpectedContentType string
+		ExpectedBody        string
+	}{
+		{
+			Name:                "json_requested_as_json",
+			Key:                 "6a736f6e",
+			Accept:              "application/json",
+			ExpectedContentType: "application/json",
+			ExpectedBody:        `{"a": 1}`,
+		},
+		{
+			Name:                "json_requested_as_raw",
+			Key:                 "6a736f6e",
+			Accept:              "application/octet-stream",
+			ExpectedContentType: "application/octet-stream",
+			ExpectedBody:        `{"a": 1}`,
+		},
+		{
+			Name:                "json_requested_without_accept",
+			Key:                 "6a736f6e",
+			ExpectedContentType: "application/octet-stream",
+			ExpectedBody:        `{"a": 1}`,
+		},
+		{
+			Name:                "json_requested_with_wildcard",
+			Key:                 "6a736f6e",
+			Accept:              "*/*",
+			ExpectedContentType: "application/octet-stream",
+			ExpectedBody:        `{"a": 1}`,
+		},
+		{
+			Name:                "json_rejected",
+			Key:                 "6a736f6e",
+			Accept:              "application/json;q=0, */*",
+			ExpectedContentType: "application/octet-stream",
+			ExpectedBody:        `{"a": 1}`,
+		},
+		{
+			Name:                "text_requested_as_json",
+			Key:                 "74657874",
+			Accept:              "text/html, application/json;q=0.9",
+			ExpectedContentType: "application/octet-stream",
+			ExpectedBody:        "text",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/raw/6275636b6574/"+testCase.Key, nil)
+			if testCase.Accept != "" {
+				r.Header.Set("Accept", testCase.Accept)
+			}
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, r)
+
+			require.Equal(t, http.StatusOK, recorder.Code)
+			require.Equal(t, testCase.ExpectedContentType, recorder.Header().Get("Content-Type"))
+			require.Equal(t, testCase.ExpectedBody, recorder.Body.String())
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/contentforward/bolt-ui/adapters"
+	"github.com/contentforward/bolt-ui/internal/config"
+	"github.com/contentforward/bolt-ui/internal/fixture"
+	"github.com/contentforward/bolt-ui/internal/wire"
+	httpPort "github.com/contentforward/bolt-ui/ports/http"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/bbolt"
+)
+
+func TestDatabases(t *testing.T) {
+	first, cleanup := fixture.File(t)
+	defer cleanup()
+
+	second, cleanup := fixture.File(t)
+	defer cleanup()
+
+	createTestBucket(t, first, "a")
+	createTestBucket(t, second, "b")
+
+	conf := newTestServiceConfig(
+		config.Database{Name: "first", File: first},
+		config.Database{Name: "second", File: second},
+	)
+
+	s, err := wire.BuildService(conf)
+	require.NoError(t, err)
+	defer s.Databases.Close()
+
+	require.Equal(t, []string{"first", "second"}, s.Databases.Names())
+
+	handler, err := httpPort.NewHandler(s.Databases, httpPort.NewTokenAuthProvider(conf), conf)
+	require.NoError(t, err)
+
+	request := func(method, target string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(method, target, nil))
+		return recorder
+	}
+
+	listBuckets := func(target string) []string {
+		recorder := request(http.MethodGet, target)
+		require.Equal(t, http.StatusOK, recorder.Code)
+
+		var keys []httpPort.Key
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &keys))
+
+		var names []string
+		for _, key := range keys {
+			names = append(names, key.Str)
+		}
+		return names
+	}
+
+	t.Run("list", func(t *testing.T) {
+		recorder := request(http.MethodGet, "/api/databases")
+		require.Equal(t, http.StatusOK, recorder.Code)
+		require.JSONEq(t, `["first", "second"]`, recorder.Body.String())
+	})
+
+	t.Run("default", func(t *testing.T) {
+		require.Equal(t, []string{"a"}, listBuckets("/api/buckets/"))
+	})
+
+	t.Run("named", func(t *testing.T) {
+		require.Equal(t, []string{"a"}, listBuckets("/api/databases/first/buckets/"))
+		require.Equal(t, []string{"b"}, listBuckets("/api/databases/second/buckets/"))
+	})
+
+	t.Run("write", func(t *testing.T) {
+		recorder := request(http.MethodPost, "/api/databases/second/buckets/63")
+		require.Equal(t, http.StatusOK, recorder.Code)
+
+		require.Equal(t, []string{"a"}, listBuckets("/api/databases/first/buckets/"))
+		require.Equal(t, []string{"b", "c"}, listBuckets("/api/databases/second/buckets/"))
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		recorder := request(http.MethodGet, "/api/databases/unknown/buckets/")
+		require.Equal(t, http.StatusNotFound, recorder.Code)
+		require.JSONEq(t, `{"statusCode":404,"message":"Database not found.","error":{"code":"not_found","message":"Database not found."}}`, recorder.Body.String())
+	})
+}
+
+func TestDatabaseWhichCanNotBeOpenedIsSkipped(t *testing.T) {
+	invalid, cleanup := fixture.File(t)
+	defer cleanup()
+
+	require.NoError(t, os.WriteFile(invalid, []byte("not a database"), 0600))
+
+	valid, cleanup := fixture.File(t)
+	defer cleanup()
+
+	conf := newTestServiceConfig(
+		config.Database{Name: "invalid", File: invalid},
+		config.Database{Name: "valid", File: valid},
+	)
+
+	s, err := wire.BuildService(conf)
+	require.NoError(t, err)
+	defer s.Databases.Close()
+
+	require.Equal(t, []string{"valid"}, s.Databases.Names())
+}
+
+func TestMissingDatabaseIsSkipped(t *testing.T) {
+	file, cleanup := fixture.File(t)
+	defer cleanup()
+
+	conf := newTestServiceConfig(
+		config.Database{Name: "missing", File: file + ".missing"},
+		config.Database{Name: "existing", File: file},
+	)
+
+	s, err := wire.BuildService(conf)
+	require.NoError(t, err)
+	defer s.Databases.Close()
+
+	require.Equal(t, []string{"existing"}, s.Databases.Names())
+
+	_, ok := s.Databases.Get("missing")
+	require.False(t, ok)
+
+	_, ok = s.Databases.Get("existing")
+	require.True(t, ok)
+}
+
+func TestAllDatabasesMissing(t *testing.T) {
+	file, cleanup := fixture.File(t)
+	defer cleanup()
+
+	conf := newTestServiceConfig(
+		config.Database{Name: "missing", File: file + ".missing"},
+	)
+
+	_, err := wire.BuildService(conf)
+	require.EqualError(t, err, "none of the databases could be opened")
+}
+
+func createTestBucket(t *testing.T, file, name string) {
+	db, err := adapters.CreateBolt(file, time.Second)
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.Update(func(tx *bbolt.Tx) error {
+		_, err := tx.CreateBucket([]byte(name))
+		return err
+	})
+	require.NoError(t, err)
+}
+
+func newTestServiceConfig(databases ...config.Database) *config.Config {
+	return &config.Config{
+		ServeAddress:  "127.0.0.1:0",
+		Databases:     databases,
+		InsecureToken: true,
+		InsecureTLS:   true,
+		OpenMode:      config.OpenModeReadWrite,
+		OpenTimeout:   time.Second,
+	}
+}
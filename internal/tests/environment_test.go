@@ -0,0 +1,121 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/boreq/guinea"
+	"github.com/contentforward/bolt-ui/cmd/bolt-ui/commands"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyEnvironment(t *testing.T) {
+	testCases := []struct {
+		Name        string
+		Environment map[string]string
+		Args        []string
+
+		ExpectedStr  string
+		ExpectedBool bool
+		ExpectedInt  int
+		ExpectedErr  string
+	}{
+		{
+			Name:         "defaults",
+			ExpectedStr:  "default",
+			ExpectedBool: false,
+			ExpectedInt:  1,
+		},
+		{
+			Name: "environment_overrides_defaults",
+			Environment: map[string]string{
+				"BOLTUI_SOME_STR":  "env",
+				"BOLTUI_SOME_BOOL": "true",
+				"BOLTUI_SOME_INT":  "2",
+			},
+			ExpectedStr:  "env",
+			ExpectedBool: true,
+			ExpectedInt:  2,
+		},
+		{
+			Name: "flags_override_environment",
+			Environment: map[string]string{
+				"BOLTUI_SOME_STR":  "env",
+				"BOLTUI_SOME_BOOL": "true",
+				"BOLTUI_SOME_INT":  "2",
+			},
+			Args:         []string{"--some-str", "flag", "--some-bool=false", "--some-int", "3"},
+			ExpectedStr:  "flag",
+			ExpectedBool: false,
+			ExpectedInt:  3,
+		},
+		{
+			Name: "invalid_bool",
+			Environment: map[string]string{
+				"BOLTUI_SOME_BOOL": "maybe",
+			},
+			ExpectedErr: "invalid environment variable BOLTUI_SOME_BOOL: 'maybe' is not a boolean",
+		},
+		{
+			Name: "invalid_int",
+			Environment: map[string]string{
+				"BOLTUI_SOME_INT": "many",
+			},
+			ExpectedErr: "invalid environment variable BOLTUI_SOME_INT: 'many' is not an integer",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			for key, value := range testCase.Environment {
+				t.Setenv(key, value)
+			}
+
+			var context guinea.Context
+
+			cmd := guinea.Command{
+				Run: func(c guinea.Context) error {
+					context = c
+					return nil
+				},
+				Options: []guinea.Option{
+					{Name: "some-str", Type: guinea.String, Default: "default"},
+					{Name: "some-bool", Type: guinea.Bool, Default: false},
+					{Name: "some-int", Type: guinea.Int, Default: 1},
+				},
+			}
+
+			err := commands.ApplyEnvironment(&cmd)
+			if testCase.ExpectedErr != "" {
+				require.EqualError(t, err, testCase.ExpectedErr)
+				return
+			}
+			require.NoError(t, err)
+
+			err = cmd.Execute("test", testCase.Args)
+			require.NoError(t, err)
+
+			require.Equal(t, testCase.ExpectedStr, context.Options["some-str"].Str())
+			require.Equal(t, testCase.ExpectedBool, context.Options["some-bool"].Bool())
+			require.Equal(t, testCase.ExpectedInt, context.Options["some-int"].Int())
+		})
+	}
+}
+
+func TestApplyEnvironmentUpdatesDescriptions(t *testing.T) {
+	t.Setenv("BOLTUI_SOME_STR", "env")
+	t.Setenv("BOLTUI_SOME_INT", "2")
+
+	cmd := guinea.Command{
+		Options: []guinea.Option{
+			{Name: "some-str", Type: guinea.String, Default: "default", Description: "Some string. Default: default"},
+			{Name: "some-int", Type: guinea.Int, Default: 1, Description: "Some integer"},
+			{Name: "some-bool", Type: guinea.Bool, Default: false, Description: "Some boolean"},
+		},
+	}
+
+	require.NoError(t, commands.ApplyEnvironment(&cmd))
+
+	require.Equal(t, "Some string. Default: env (set by BOLTUI_SOME_STR)", cmd.Options[0].Description)
+	require.Equal(t, "Some integer. Default: 2 (set by BOLTUI_SOME_INT)", cmd.Options[1].Description)
+	require.Equal(t, "Some boolean", cmd.Options[2].Description)
+}
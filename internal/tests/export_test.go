@@ -0,0 +1,234 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/contentforward/bolt-ui/application"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/bbolt"
+)
+
+func TestExportBucket(t *testing.T) {
+	testApp := NewTracker(t)
+
+	err := testApp.DB.Update(func(tx *bbolt.Tx) error {
+		bucket, err := tx.CreateBucket([]byte("bucket"))
+		if err != nil {
+			return err
+		}
+
+		if err := bucket.Put([]byte("key"), []byte("value")); err != nil {
+			return err
+		}
+
+		child, err := bucket.CreateBucket([]byte("child"))
+		if err != nil {
+			return err
+		}
+
+		return child.Put([]byte{0xff, 0x00}, []byte{0x01})
+	})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		Name           string
+		Path           []application.Key
+		ExpectedOutput string
+	}{
+		{
+			Name:           "root",
+			Path:           nil,
+			ExpectedOutput: `[{"key":"bucket","keyEncoding":"utf8","bucket":[{"key":"child","keyEncoding":"utf8","bucket":[{"key":"/wA=","keyEncoding":"base64","value":"AQ=="}]},{"key":"key","keyEncoding":"utf8","value":"dmFsdWU="}]}]`,
+		},
+		{
+			Name: "bucket",
+			Path: []application.Key{
+				application.MustNewKey([]byte("bucket")),
+				application.MustNewKey([]byte("child")),
+			},
+			ExpectedOutput: `[{"key":"/wA=","keyEncoding":"base64","value":"AQ=="}]`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+
+			err := testApp.Application.ExportBucket.Execute(
+				application.ExportBucket{
+					Context: context.Background(),
+					Path:    testCase.Path,
+					Writer:  buf,
+				},
+			)
+			require.NoError(t, err)
+			require.JSONEq(t, testCase.ExpectedOutput, buf.String())
+		})
+	}
+
+	buf := &bytes.Buffer{}
+
+	err = testApp.Application.ExportBucket.Execute(
+		application.ExportBucket{
+			Context: context.Background(),
+			Path: []application.Key{
+				application.MustNewKey([]byte("missing")),
+			},
+			Writer: buf,
+		},
+	)
+	require.ErrorIs(t, err, application.ErrBucketNotFound)
+	require.Empty(t, buf.Bytes())
+}
+
+func TestExportBucketCSV(t *testing.T) {
+	testApp := NewTracker(t)
+
+	err := testApp.DB.Update(func(tx *bbolt.Tx) error {
+		text, err := tx.CreateBucket([]byte("text"))
+		if err != nil {
+			return err
+		}
+
+		if err := text.Put([]byte("a"), []byte("first, \"quoted\"")); err != nil {
+			return err
+		}
+
+		if err := text.Put([]byte("b"), []byte("second")); err != nil {
+			return err
+		}
+
+		binary, err := tx.CreateBucket([]byte("binary"))
+		if err != nil {
+			return err
+		}
+
+		if err := binary.Put([]byte("a"), []byte{0xff, 0x00}); err != nil {
+			return err
+		}
+
+		if err := binary.Put([]byte("b"), []byte("value")); err != nil {
+			return err
+		}
+
+		nested, err := tx.CreateBucket([]byte("nested"))
+		if err != nil {
+			return err
+		}
+
+		if err := nested.Put([]byte("a"), []byte("value")); err != nil {
+			return err
+		}
+
+		_, err = nested.CreateBucket([]byte("child"))
+		return err
+	})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		Name           string
+		Path           []application.Key
+		ExpectedOutput string
+		ExpectedErr    error
+	}{
+		{
+			Name: "text",
+			Path: []application.Key{
+				application.MustNewKey([]byte("text")),
+			},
+			ExpectedOutput: "key,value\na,\"first, \"\"quoted\"\"\"\nb,second\n",
+		},
+		{
+			Name: "values_which_are_not_utf8_are_base64_encoded",
+			Path: []application.Key{
+				application.MustNewKey([]byte("binary")),
+			},
+			ExpectedOutput: "key,value_base64\na,/wA=\nb,dmFsdWU=\n",
+		},
+		{
+			Name: "nested_buckets",
+			Path: []application.Key{
+				application.MustNewKey([]byte("nested")),
+			},
+			ExpectedErr: application.ErrContainsBuckets,
+		},
+		{
+			Name:        "root",
+			Path:        nil,
+			ExpectedErr: application.ErrContainsBuckets,
+		},
+		{
+			Name: "missing",
+			Path: []application.Key{
+				application.MustNewKey([]byte("missing")),
+			},
+			ExpectedErr: application.ErrBucketNotFound,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+
+			err := testApp.Application.ExportBucketCSV.Execute(
+				application.ExportBucketCSV{
+					Context: context.Background(),
+					Path:    testCase.Path,
+					Writer:  buf,
+				},
+			)
+
+			if testCase.ExpectedErr != nil {
+				require.ErrorIs(t, err, testCase.ExpectedErr)
+				require.Empty(t, buf.Bytes())
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, testCase.ExpectedOutput, buf.String())
+			}
+		})
+	}
+}
+
+func TestExportBucketHonorsContext(t *testing.T) {
+	testApp := NewTracker(t)
+
+	err := testApp.DB.Update(func(tx *bbolt.Tx) error {
+		bucket, err := tx.CreateBucket([]byte("bucket"))
+		if err != nil {
+			return err
+		}
+
+		for i := 0; i < 1000; i++ {
+			if err := bucket.Put([]byte{byte(i >> 8), byte(i)}, []byte("value")); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err = testApp.Application.ExportBucket.Execute(
+		application.ExportBucket{
+			Context: ctx,
+			Writer:  &bytes.Buffer{},
+		},
+	)
+	require.ErrorIs(t, err, context.Canceled)
+
+	err = testApp.Application.ExportBucketCSV.Execute(
+		application.ExportBucketCSV{
+			Context: ctx,
+			Path: []application.Key{
+				application.MustNewKey([]byte("bucket")),
+			},
+			Writer: &bytes.Buffer{},
+		},
+	)
+	require.ErrorIs(t, err, context.Canceled)
+}
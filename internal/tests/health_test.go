@@ -0,0 +1,44 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/contentforward/bolt-ui/application"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/bbolt"
+)
+
+func TestCheckHealth(t *testing.T) {
+	testApp := NewTracker(t)
+
+	err := testApp.Application.CheckHealth.Execute(application.CheckHealth{})
+	require.NoError(t, err)
+
+	require.NoError(t, testApp.DB.Close())
+
+	err = testApp.Application.CheckHealth.Execute(application.CheckHealth{})
+	require.Error(t, err)
+}
+
+func TestCheckConsistency(t *testing.T) {
+	testApp := NewTracker(t)
+
+	err := testApp.DB.Update(func(tx *bbolt.Tx) error {
+		bucket, err := tx.CreateBucket([]byte("bucket"))
+		if err != nil {
+			return err
+		}
+
+		child, err := bucket.CreateBucket([]byte("child"))
+		if err != nil {
+			return err
+		}
+
+		return child.Put([]byte("key"), []byte("value"))
+	})
+	require.NoError(t, err)
+
+	problems, err := testApp.Application.CheckConsistency.Execute(application.CheckConsistency{})
+	require.NoError(t, err)
+	require.Empty(t, problems)
+}
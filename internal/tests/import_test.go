@@ -0,0 +1,755 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/contentforward/bolt-ui/adapters"
+	"github.com/contentforward/bolt-ui/application"
+	"github.com/contentforward/bolt-ui/internal/config"
+	"github.com/contentforward/bolt-ui/internal/fixture"
+	httpPort "github.com/contentforward/bolt-ui/ports/http"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/bbolt"
+)
+
+func TestImportBucketRoundTrip(t *testing.T) {
+	source := NewTracker(t)
+	destination := NewTracker(t)
+
+	err := source.DB.Update(func(tx *bbolt.Tx) error {
+		bucket, err := tx.CreateBucket([]byte("bucket"))
+		if err != nil {
+			return err
+		}
+
+		if err := bucket.Put([]byte("key"), []byte("value")); err != nil {
+			return err
+		}
+
+		child, err := bucket.CreateBucket([]byte("child"))
+		if err != nil {
+			return err
+		}
+
+		if _, err := child.CreateBucket([]byte("empty")); err != nil {
+			return err
+		}
+
+		return child.Put([]byte{0xff, 0x00}, []byte{0x01})
+	})
+	require.NoError(t, err)
+
+	exported := &bytes.Buffer{}
+
+	err = source.Application.ExportBucket.Execute(
+		application.ExportBucket{
+			Context: context.Background(),
+			Writer:  exported,
+		},
+	)
+	require.NoError(t, err)
+
+	_, err = destination.Application.ImportBucket.Execute(
+		application.ImportBucket{
+			Reader: bytes.NewReader(exported.Bytes()),
+			Mode:   application.ImportModeMerge,
+		},
+	)
+	require.NoError(t, err)
+
+	reexported := &bytes.Buffer{}
+
+	err = destination.Application.ExportBucket.Execute(
+		application.ExportBucket{
+			Context: context.Background(),
+			Writer:  reexported,
+		},
+	)
+	require.NoError(t, err)
+
+	require.JSONEq(t, exported.String(), reexported.String())
+}
+
+func TestImportBucketModes(t *testing.T) {
+	bucketName := []byte("bucket")
+
+	path := []application.Key{
+		application.MustNewKey(bucketName),
+	}
+
+	input := `[{"key":"new","keyEncoding":"utf8","value":"bmV3"},{"key":"existing","keyEncoding":"utf8","value":"bmV3"}]`
+
+	testCases := []struct {
+		Name           string
+		Mode           application.ImportMode
+		ExpectedOutput string
+	}{
+		{
+			Name:           "merge",
+			Mode:           application.ImportModeMerge,
+			ExpectedOutput: `[{"key":"existing","keyEncoding":"utf8","value":"bmV3"},{"key":"new","keyEncoding":"utf8","value":"bmV3"},{"key":"old","keyEncoding":"utf8","value":"b2xk"}]`,
+		},
+		{
+			Name:           "replace",
+			Mode:           application.ImportModeReplace,
+			ExpectedOutput: `[{"key":"existing","keyEncoding":"utf8","value":"bmV3"},{"key":"new","keyEncoding":"utf8","value":"bmV3"}]`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			testApp := NewTracker(t)
+
+			err := testApp.DB.Update(func(tx *bbolt.Tx) error {
+				bucket, err := tx.CreateBucket(bucketName)
+				if err != nil {
+					return err
+				}
+
+				if err := bucket.Put([]byte("existing"), []byte("old")); err != nil {
+					return err
+				}
+
+				return bucket.Put([]byte("old"), []byte("old"))
+			})
+			require.NoError(t, err)
+
+			_, err = testApp.Application.ImportBucket.Execute(
+				application.ImportBucket{
+					Path:   path,
+					Reader: strings.NewReader(input),
+					Mode:   testCase.Mode,
+				},
+			)
+			require.NoError(t, err)
+
+			buf := &bytes.Buffer{}
+
+			err = testApp.Application.ExportBucket.Execute(
+				application.ExportBucket{
+					Context: context.Background(),
+					Path:    path,
+					Writer:  buf,
+				},
+			)
+			require.NoError(t, err)
+			require.JSONEq(t, testCase.ExpectedOutput, buf.String())
+		})
+	}
+}
+
+func TestImportBucketRejectsInvalidInput(t *testing.T) {
+	bucketName := []byte("bucket")
+
+	testCases := []struct {
+		Name  string
+		Input string
+	}{
+		{
+			Name:  "malformed_json",
+			Input: `[{"key":"a","keyEncoding":"utf8","value":"YQ=="}`,
+		},
+		{
+			Name:  "invalid_base64",
+			Input: `[{"key":"a","keyEncoding":"utf8","value":"YQ=="},{"key":"b","keyEncoding":"utf8","value":"!"}]`,
+		},
+		{
+			Name:  "unknown_key_encoding",
+			Input: `[{"key":"a","keyEncoding":"utf8","value":"YQ=="},{"key":"b","keyEncoding":"hex","value":"YQ=="}]`,
+		},
+		{
+			Name:  "value_and_bucket",
+			Input: `[{"key":"a","keyEncoding":"utf8","value":"YQ==","bucket":[]}]`,
+		},
+		{
+			Name:  "empty_key",
+			Input: `[{"key":"","keyEncoding":"utf8","value":"YQ=="}]`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			testApp := NewTracker(t)
+
+			err := testApp.DB.Update(func(tx *bbolt.Tx) error {
+				_, err := tx.CreateBucket(bucketName)
+				return err
+			})
+			require.NoError(t, err)
+
+			_, err = testApp.Application.ImportBucket.Execute(
+				application.ImportBucket{
+					Path: []application.Key{
+						application.MustNewKey(bucketName),
+					},
+					Reader: strings.NewReader(testCase.Input),
+					Mode:   application.ImportModeReplace,
+				},
+			)
+			require.ErrorIs(t, err, application.ErrInvalidImport)
+
+			err = testApp.DB.View(func(tx *bbolt.Tx) error {
+				require.NotNil(t, tx.Bucket(bucketName))
+				require.Equal(t, 0, tx.Bucket(bucketName).Stats().KeyN)
+				return nil
+			})
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestImportBucketDryRun(t *testing.T) {
+	bucketName := []byte("bucket")
+
+	path := []application.Key{
+		application.MustNewKey(bucketName),
+	}
+
+	testCases := []struct {
+		Name            string
+		Path            []application.Key
+		Input           string
+		Format          application.ImportFormat
+		Mode            application.ImportMode
+		ExpectedSummary application.ImportSummary
+	}{
+		{
+			Name:   "merge",
+			Path:   path,
+			Input:  `[{"key":"new","keyEncoding":"utf8","value":"bmV3"},{"key":"existing","keyEncoding":"utf8","value":"bmV3"},{"key":"child","keyEncoding":"utf8","bucket":[{"key":"a","keyEncoding":"utf8","value":"YQ=="}]}]`,
+			Format: application.ImportFormatJSON,
+			Mode:   application.ImportModeMerge,
+			ExpectedSummary: application.ImportSummary{
+				Created:     2,
+				Overwritten: 1,
+			},
+		},
+		{
+			Name:   "replace",
+			Path:   path,
+			Input:  `[{"key":"new","keyEncoding":"utf8","value":"bmV3"},{"key":"existing","keyEncoding":"utf8","value":"bmV3"}]`,
+			Format: application.ImportFormatJSON,
+			Mode:   application.ImportModeReplace,
+			ExpectedSummary: application.ImportSummary{
+				Created: 2,
+			},
+		},
+		{
+			Name:   "csv",
+			Path:   path,
+			Input:  "key,value\nnew,new\nexisting,new\n",
+			Format: application.ImportFormatCSV,
+			Mode:   application.ImportModeMerge,
+			ExpectedSummary: application.ImportSummary{
+				Created:     1,
+				Overwritten: 1,
+			},
+		},
+		{
+			Name:   "conflicts",
+			Path:   path,
+			Input:  `[{"key":"child","keyEncoding":"utf8","value":"bmV3"},{"key":"existing","keyEncoding":"utf8","bucket":[]}]`,
+			Format: application.ImportFormatJSON,
+			Mode:   application.ImportModeMerge,
+			ExpectedSummary: application.ImportSummary{
+				Errors: []string{
+					"value 'child' would overwrite a bucket: err not a value",
+					"bucket 'existing' would overwrite a value: err value already exists",
+				},
+			},
+		},
+		{
+			Name:   "values_in_root",
+			Path:   nil,
+			Input:  `[{"key":"value","keyEncoding":"utf8","value":"bmV3"},{"key":"bucket","keyEncoding":"utf8","bucket":[{"key":"new","keyEncoding":"utf8","value":"bmV3"}]}]`,
+			Format: application.ImportFormatJSON,
+			Mode:   application.ImportModeMerge,
+			ExpectedSummary: application.ImportSummary{
+				Created: 1,
+				Errors: []string{
+					"value 'value' can not be stored in the root of the database",
+				},
+			},
+		},
+		{
+			Name:   "malformed_input",
+			Path:   path,
+			Input:  "key,value\na\n",
+			Format: application.ImportFormatCSV,
+			Mode:   application.ImportModeMerge,
+			ExpectedSummary: application.ImportSummary{
+				Errors: []string{
+					"could not read a record: record on line 2: wrong number of fields",
+				},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			testApp := NewTracker(t)
+
+			err := testApp.DB.Update(func(tx *bbolt.Tx) error {
+				bucket, err := tx.CreateBucket(bucketName)
+				if err != nil {
+					return err
+				}
+
+				if _, err := bucket.CreateBucket([]byte("child")); err != nil {
+					return err
+				}
+
+				if err := bucket.Put([]byte("child-value"), []byte("old")); err != nil {
+					return err
+				}
+
+				return bucket.Put([]byte("existing"), []byte("old"))
+			})
+			require.NoError(t, err)
+
+			before := &bytes.Buffer{}
+			err = testApp.Application.ExportBucket.Execute(application.ExportBucket{Context: context.Background(), Writer: before})
+			require.NoError(t, err)
+
+			summary, err := testApp.Application.ImportBucket.Execute(
+				application.ImportBucket{
+					Path:   testCase.Path,
+					Reader: strings.NewReader(testCase.Input),
+					Mode:   testCase.Mode,
+					Format: testCase.Format,
+					DryRun: true,
+				},
+			)
+			require.NoError(t, err)
+			require.Equal(t, testCase.ExpectedSummary, summary)
+
+			after := &bytes.Buffer{}
+			err = testApp.Application.ExportBucket.Execute(application.ExportBucket{Context: context.Background(), Writer: after})
+			require.NoError(t, err)
+
+			require.JSONEq(t, before.String(), after.String())
+
+			summary, err = testApp.Application.ImportBucket.Execute(
+				application.ImportBucket{
+					Path:   testCase.Path,
+					Reader: strings.NewReader(testCase.Input),
+					Mode:   testCase.Mode,
+					Format: testCase.Format,
+				},
+			)
+			if len(testCase.ExpectedSummary.Errors) > 0 {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, testCase.ExpectedSummary, summary)
+			}
+		})
+	}
+}
+
+func TestImportBucketCSVRoundTrip(t *testing.T) {
+	testApp := NewTracker(t)
+
+	err := testApp.DB.Update(func(tx *bbolt.Tx) error {
+		bucket, err := tx.CreateBucket([]byte("bucket"))
+		if err != nil {
+			return err
+		}
+
+		if err := bucket.Put([]byte("a"), []byte{0xff, 0x00}); err != nil {
+			return err
+		}
+
+		if err := bucket.Put([]byte("b"), []byte("line\nbreak, \"quoted\"")); err != nil {
+			return err
+		}
+
+		_, err = tx.CreateBucket([]byte("copy"))
+		return err
+	})
+	require.NoError(t, err)
+
+	exported := &bytes.Buffer{}
+
+	err = testApp.Application.ExportBucketCSV.Execute(
+		application.ExportBucketCSV{
+			Context: context.Background(),
+			Path:    []application.Key{application.MustNewKey([]byte("bucket"))},
+			Writer:  exported,
+		},
+	)
+	require.NoError(t, err)
+
+	_, err = testApp.Application.ImportBucket.Execute(
+		application.ImportBucket{
+			Path:   []application.Key{application.MustNewKey([]byte("copy"))},
+			Reader: bytes.NewReader(exported.Bytes()),
+			Format: application.ImportFormatCSV,
+		},
+	)
+	require.NoError(t, err)
+
+	reexported := &bytes.Buffer{}
+
+	err = testApp.Application.ExportBucketCSV.Execute(
+		application.ExportBucketCSV{
+			Context: context.Background(),
+			Path:    []application.Key{application.MustNewKey([]byte("copy"))},
+			Writer:  reexported,
+		},
+	)
+	require.NoError(t, err)
+
+	require.Equal(t, exported.String(), reexported.String())
+}
+
+func TestImportBoltFile(t *testing.T) {
+	source := createTestBoltFile(t, func(tx *bbolt.Tx) error {
+		bucket, err := tx.CreateBucket([]byte("bucket"))
+		if err != nil {
+			return err
+		}
+
+		if err := bucket.Put([]byte("existing"), []byte("new")); err != nil {
+			return err
+		}
+
+		child, err := bucket.CreateBucket([]byte("child"))
+		if err != nil {
+			return err
+		}
+
+		if err := child.SetSequence(10); err != nil {
+			return err
+		}
+
+		if _, err := child.CreateBucket([]byte("empty")); err != nil {
+			return err
+		}
+
+		return child.Put([]byte("key"), []byte("value"))
+	})
+
+	testCases := []struct {
+		Name           string
+		Path           []application.Key
+		Mode           application.ImportMode
+		ExpectedOutput string
+	}{
+		{
+			Name:           "merge",
+			Mode:           application.ImportModeMerge,
+			ExpectedOutput: `[{"key":"bucket","keyEncoding":"utf8","bucket":[{"key":"child","keyEncoding":"utf8","bucket":[{"key":"empty","keyEncoding":"utf8","bucket":[]},{"key":"key","keyEncoding":"utf8","value":"dmFsdWU="}]},{"key":"existing","keyEncoding":"utf8","value":"bmV3"},{"key":"old","keyEncoding":"utf8","value":"b2xk"}]},{"key":"other","keyEncoding":"utf8","bucket":[]}]`,
+		},
+		{
+			Name:           "replace",
+			Mode:           application.ImportModeReplace,
+			ExpectedOutput: `[{"key":"bucket","keyEncoding":"utf8","bucket":[{"key":"child","keyEncoding":"utf8","bucket":[{"key":"empty","keyEncoding":"utf8","bucket":[]},{"key":"key","keyEncoding":"utf8","value":"dmFsdWU="}]},{"key":"existing","keyEncoding":"utf8","value":"bmV3"}]}]`,
+		},
+		{
+			Name: "nested",
+			Path: []application.Key{
+				application.MustNewKey([]byte("bucket")),
+			},
+			Mode:           application.ImportModeMerge,
+			ExpectedOutput: `[{"key":"bucket","keyEncoding":"utf8","bucket":[{"key":"bucket","keyEncoding":"utf8","bucket":[{"key":"child","keyEncoding":"utf8","bucket":[{"key":"empty","keyEncoding":"utf8","bucket":[]},{"key":"key","keyEncoding":"utf8","value":"dmFsdWU="}]},{"key":"existing","keyEncoding":"utf8","value":"bmV3"}]},{"key":"existing","keyEncoding":"utf8","value":"b2xk"},{"key":"old","keyEncoding":"utf8","value":"b2xk"}]},{"key":"other","keyEncoding":"utf8","bucket":[]}]`,
+		},
+		{
+			Name: "nested_replace",
+			Path: []application.Key{
+				application.MustNewKey([]byte("bucket")),
+			},
+			Mode:           application.ImportModeReplace,
+			ExpectedOutput: `[{"key":"bucket","keyEncoding":"utf8","bucket":[{"key":"bucket","keyEncoding":"utf8","bucket":[{"key":"child","keyEncoding":"utf8","bucket":[{"key":"empty","keyEncoding":"utf8","bucket":[]},{"key":"key","keyEncoding":"utf8","value":"dmFsdWU="}]},{"key":"existing","keyEncoding":"utf8","value":"bmV3"}]}]},{"key":"other","keyEncoding":"utf8","bucket":[]}]`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			destination := NewTracker(t)
+
+			err := destination.DB.Update(func(tx *bbolt.Tx) error {
+				bucket, err := tx.CreateBucket([]byte("bucket"))
+				if err != nil {
+					return err
+				}
+
+				if err := bucket.Put([]byte("existing"), []byte("old")); err != nil {
+					return err
+				}
+
+				if err := bucket.Put([]byte("old"), []byte("old")); err != nil {
+					return err
+				}
+
+				_, err = tx.CreateBucket([]byte("other"))
+				return err
+			})
+			require.NoError(t, err)
+
+			err = destination.Application.ImportBoltFile.Execute(
+				application.ImportBoltFile{
+					SourcePath: source,
+					Path:       testCase.Path,
+					Mode:       testCase.Mode,
+				},
+			)
+			require.NoError(t, err)
+
+			buf := &bytes.Buffer{}
+
+			err = destination.Application.ExportBucket.Execute(
+				application.ExportBucket{
+					Context: context.Background(),
+					Writer:  buf,
+				},
+			)
+			require.NoError(t, err)
+			require.JSONEq(t, testCase.ExpectedOutput, buf.String())
+
+			err = destination.DB.View(func(tx *bbolt.Tx) error {
+				bucket := tx.Bucket([]byte("bucket"))
+				for range testCase.Path {
+					bucket = bucket.Bucket([]byte("bucket"))
+				}
+				require.Equal(t, uint64(10), bucket.Bucket([]byte("child")).Sequence())
+				return nil
+			})
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestImportBoltFileChecksConflictsBeforeWriting(t *testing.T) {
+	source := createTestBoltFile(t, func(tx *bbolt.Tx) error {
+		if _, err := tx.CreateBucket([]byte("new")); err != nil {
+			return err
+		}
+
+		bucket, err := tx.CreateBucket([]byte("bucket"))
+		if err != nil {
+			return err
+		}
+
+		_, err = bucket.CreateBucket([]byte("key"))
+		return err
+	})
+
+	destination := NewTracker(t)
+
+	err := destination.DB.Update(func(tx *bbolt.Tx) error {
+		bucket, err := tx.CreateBucket([]byte("bucket"))
+		if err != nil {
+			return err
+		}
+
+		return bucket.Put([]byte("key"), []byte("value"))
+	})
+	require.NoError(t, err)
+
+	err = destination.Application.ImportBoltFile.Execute(
+		application.ImportBoltFile{
+			SourcePath: source,
+			Mode:       application.ImportModeMerge,
+		},
+	)
+	require.ErrorIs(t, err, application.ErrValueExists)
+
+	err = destination.DB.View(func(tx *bbolt.Tx) error {
+		require.Nil(t, tx.Bucket([]byte("new")))
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestImportBoltFileRejectsInvalidFile(t *testing.T) {
+	testApp := NewTracker(t)
+
+	file, cleanup := fixture.File(t)
+	defer cleanup()
+
+	require.NoError(t, ioutil.WriteFile(file, []byte("not a database"), 0600))
+
+	err := testApp.Application.ImportBoltFile.Execute(
+		application.ImportBoltFile{
+			SourcePath: file,
+			Mode:       application.ImportModeMerge,
+		},
+	)
+	require.ErrorIs(t, err, application.ErrInvalidImport)
+}
+
+func TestImportBoltFileUpload(t *testing.T) {
+	testApp := NewTracker(t)
+
+	source := createTestBoltFile(t, func(tx *bbolt.Tx) error {
+		bucket, err := tx.CreateBucket([]byte("bucket"))
+		if err != nil {
+			return err
+		}
+
+		return bucket.Put([]byte("key"), []byte("value"))
+	})
+
+	upload, err := ioutil.ReadFile(source)
+	require.NoError(t, err)
+
+	conf := &config.Config{
+		InsecureToken: true,
+	}
+
+	handler, err := httpPort.NewHandler(testDatabases{testApp.Application}, httpPort.NewTokenAuthProvider(conf), conf)
+	require.NoError(t, err)
+
+	t.Run("dry_run", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/api/import/?format=bolt&dryRun=true", bytes.NewReader(upload)))
+		require.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/api/import/?format=bolt", strings.NewReader("not a database")))
+		require.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+
+	t.Run("import", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/api/import/?format=bolt", bytes.NewReader(upload)))
+		require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+		err := testApp.DB.View(func(tx *bbolt.Tx) error {
+			require.Equal(t, []byte("value"), tx.Bucket([]byte("bucket")).Get([]byte("key")))
+			return nil
+		})
+		require.NoError(t, err)
+	})
+}
+
+// createTestBoltFile returns the path to a closed database file containing the
+// data written by the function.
+func createTestBoltFile(t *testing.T, fn func(tx *bbolt.Tx) error) string {
+	file, cleanup := fixture.File(t)
+	t.Cleanup(cleanup)
+
+	db, err := adapters.CreateBolt(file, adapters.DefaultOpenTimeout)
+	require.NoError(t, err)
+
+	require.NoError(t, db.Update(fn))
+	require.NoError(t, db.Close())
+
+	return file
+}
+
+func TestImportBucketTooLarge(t *testing.T) {
+	testApp := NewTracker(t)
+
+	conf := &config.Config{
+		InsecureToken: true,
+		MaxImportSize: 64,
+	}
+
+	handler, err := httpPort.NewHandler(testDatabases{testApp.Application}, httpPort.NewTokenAuthProvider(conf), conf)
+	require.NoError(t, err)
+
+	allowed := `[{"key":"bucket","keyEncoding":"utf8","bucket":[]}]`
+	tooLarge := `[{"key":"bucket","keyEncoding":"utf8","bucket":[{"key":"key","keyEncoding":"utf8","value":"dmFsdWU="}]}]`
+
+	testCases := []struct {
+		Name               string
+		Body               string
+		UnknownLength      bool
+		ExpectedStatusCode int
+	}{
+		{
+			Name:               "allowed",
+			Body:               allowed,
+			ExpectedStatusCode: http.StatusOK,
+		},
+		{
+			Name:               "too_large",
+			Body:               tooLarge,
+			ExpectedStatusCode: http.StatusRequestEntityTooLarge,
+		},
+		{
+			Name:               "invalid",
+			Body:               `[{"key":"key","keyEncoding":"utf8","value":"dmFsdWU="}]`,
+			ExpectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			Name:               "too_large_without_content_length",
+			Body:               tooLarge,
+			UnknownLength:      true,
+			ExpectedStatusCode: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/api/import/?mode=merge", strings.NewReader(testCase.Body))
+			if testCase.UnknownLength {
+				r.ContentLength = -1
+			}
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, r)
+			require.Equal(t, testCase.ExpectedStatusCode, recorder.Code, recorder.Body.String())
+		})
+	}
+}
+
+func TestImportBucketDecodesInputBeforeTransaction(t *testing.T) {
+	testApp := NewTracker(t)
+
+	input := `[{"key":"bucket","keyEncoding":"utf8","bucket":[]}]`
+
+	// Reading the input writes to the database which would block forever
+	// if the input was read while the write transaction was open.
+	reader := &writingReader{
+		r: strings.NewReader(input),
+		write: func() error {
+			return testApp.DB.Update(func(tx *bbolt.Tx) error {
+				_, err := tx.CreateBucketIfNotExists([]byte("other"))
+				return err
+			})
+		},
+	}
+
+	done := make(chan error)
+	go func() {
+		_, err := testApp.Application.ImportBucket.Execute(
+			application.ImportBucket{
+				Reader: reader,
+				Mode:   application.ImportModeMerge,
+			},
+		)
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("import is blocked")
+	}
+}
+
+type writingReader struct {
+	r     io.Reader
+	write func() error
+}
+
+func (r *writingReader) Read(p []byte) (int, error) {
+	if err := r.write(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
@@ -0,0 +1,24 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/contentforward/bolt-ui/application"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUint64Key(t *testing.T) {
+	key := application.NewUint64Key(258)
+	require.Equal(t, []byte{0, 0, 0, 0, 0, 0, 1, 2}, key.Bytes())
+
+	n, ok := key.Uint64()
+	require.True(t, ok)
+	require.Equal(t, uint64(258), n)
+
+	_, ok = application.MustNewKey([]byte("short")).Uint64()
+	require.False(t, ok, "keys which aren't 8 bytes long can't be interpreted")
+
+	n, ok = application.MustNewValue([]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}).Uint64()
+	require.True(t, ok)
+	require.Equal(t, uint64(18446744073709551615), n)
+}
@@ -0,0 +1,256 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/contentforward/bolt-ui/application"
+	"github.com/contentforward/bolt-ui/internal/config"
+	httpPort "github.com/contentforward/bolt-ui/ports/http"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/bbolt"
+)
+
+func TestBase64EncodedKeys(t *testing.T) {
+	testApp := NewTracker(t)
+
+	bucketName := []byte{0x00, 0x00, 0x00, 0x01}
+	key := []byte("a/b\x00c/")
+
+	err := testApp.DB.Update(func(tx *bbolt.Tx) error {
+		bucket, err := tx.CreateBucket(bucketName)
+		if err != nil {
+			return err
+		}
+
+		return bucket.Put(key, []byte("value"))
+	})
+	require.NoError(t, err)
+
+	conf := &config.Config{
+		InsecureToken: true,
+	}
+
+	handler, err := httpPort.NewHandler(testDatabases{testApp.Application}, httpPort.NewTokenAuthProvider(conf), conf)
+	require.NoError(t, err)
+
+	encodedBucket := base64.RawURLEncoding.EncodeToString(bucketName)
+	encodedKey := base64.URLEncoding.EncodeToString(key)
+
+	t.Run("value", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/raw/"+encodedBucket+"/"+encodedKey+"?encoding=base64", nil))
+
+		require.Equal(t, http.StatusOK, recorder.Code)
+		require.Equal(t, "value", recorder.Body.String())
+	})
+
+	t.Run("keys", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/keys/"+encodedBucket+"?encoding=base64", nil))
+		require.Equal(t, http.StatusOK, recorder.Code)
+
+		var page httpPort.KeysPage
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &page))
+		require.Len(t, page.Keys, 1)
+
+		decoded, err := base64.RawURLEncoding.DecodeString(page.Keys[0].Key.Base64)
+		require.NoError(t, err)
+		require.Equal(t, key, decoded)
+	})
+
+	t.Run("invalid_encoding", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/keys/"+encodedBucket+"?encoding=base32", nil))
+		require.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+
+	t.Run("hex_by_default", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/keys/"+encodedBucket, nil))
+		require.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+}
+
+func TestUint64Keys(t *testing.T) {
+	testApp := NewTracker(t)
+
+	err := testApp.DB.Update(func(tx *bbolt.Tx) error {
+		bucket, err := tx.CreateBucket([]byte("ids"))
+		if err != nil {
+			return err
+		}
+
+		for _, n := range []uint64{1, 256, 70000} {
+			if err := bucket.Put(application.NewUint64Key(n).Bytes(), application.NewUint64Key(n*2).Bytes()); err != nil {
+				return err
+			}
+		}
+
+		if err := bucket.Put([]byte("meta"), []byte("value")); err != nil {
+			return err
+		}
+
+		numbered, err := tx.CreateBucket(application.NewUint64Key(7).Bytes())
+		if err != nil {
+			return err
+		}
+
+		return numbered.Put(application.NewUint64Key(256).Bytes(), application.NewUint64Key(512).Bytes())
+	})
+	require.NoError(t, err)
+
+	conf := &config.Config{
+		InsecureToken: true,
+	}
+
+	handler, err := httpPort.NewHandler(testDatabases{testApp.Application}, httpPort.NewTokenAuthProvider(conf), conf)
+	require.NoError(t, err)
+
+	t.Run("keys", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/keys/696473?interpretKeys=uint64", nil))
+		require.Equal(t, http.StatusOK, recorder.Code)
+
+		var page httpPort.KeysPage
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &page))
+
+		var numbers []string
+		for _, keyInfo := range page.Keys {
+			numbers = append(numbers, keyInfo.Key.Uint64)
+		}
+		require.Equal(t, []string{"1", "256", "70000", ""}, numbers)
+	})
+
+	t.Run("value", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/value/7/256?encoding=uint64&interpretValues=uint64", nil))
+		require.Equal(t, http.StatusOK, recorder.Code)
+
+		var value httpPort.Value
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &value))
+		require.Equal(t, "512", value.Uint64)
+	})
+
+	t.Run("invalid_interpretation", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/keys/696473?interpretKeys=float", nil))
+		require.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+}
+
+func TestBase64EncodedKeysInRequestBodies(t *testing.T) {
+	testApp := NewTracker(t)
+
+	bucketName := []byte("bucket\x00")
+	key := []byte("a/b\x00c")
+
+	err := testApp.DB.Update(func(tx *bbolt.Tx) error {
+		bucket, err := tx.CreateBucket(bucketName)
+		if err != nil {
+			return err
+		}
+
+		if _, err := bucket.CreateBucket([]byte("nested")); err != nil {
+			return err
+		}
+
+		return bucket.Put(key, []byte("value"))
+	})
+	require.NoError(t, err)
+
+	conf := &config.Config{
+		InsecureToken: true,
+	}
+
+	handler, err := httpPort.NewHandler(testDatabases{testApp.Application}, httpPort.NewTokenAuthProvider(conf), conf)
+	require.NoError(t, err)
+
+	encode := base64.RawURLEncoding.EncodeToString
+	encodedBucket := encode(bucketName)
+
+	post := func(t *testing.T, path string, body interface{}) {
+		b, err := json.Marshal(body)
+		require.NoError(t, err)
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, path+"?encoding=base64", bytes.NewReader(b)))
+		require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	}
+
+	get := func(t *testing.T, path ...[]byte) []byte {
+		var value []byte
+		err := testApp.DB.View(func(tx *bbolt.Tx) error {
+			bucket := tx.Bucket(path[0])
+			for _, name := range path[1 : len(path)-1] {
+				bucket = bucket.Bucket(name)
+			}
+			value = bucket.Get(path[len(path)-1])
+			return nil
+		})
+		require.NoError(t, err)
+		return value
+	}
+
+	t.Run("rename", func(t *testing.T) {
+		post(t, "/api/rename/"+encodedBucket+"/"+encode(key), httpPort.RenameKey{
+			NewKey: encode([]byte("renamed/")),
+		})
+
+		require.Nil(t, get(t, bucketName, key))
+		require.Equal(t, []byte("value"), get(t, bucketName, []byte("renamed/")))
+	})
+
+	t.Run("copy", func(t *testing.T) {
+		post(t, "/api/copy/"+encodedBucket+"/"+encode([]byte("renamed/")), httpPort.CopyKey{
+			Path: []string{encodedBucket, encode([]byte("nested"))},
+			Key:  encode([]byte("copied/")),
+		})
+
+		require.Equal(t, []byte("value"), get(t, bucketName, []byte("nested"), []byte("copied/")))
+	})
+
+	t.Run("move", func(t *testing.T) {
+		post(t, "/api/move/"+encodedBucket+"/"+encode([]byte("nested")), httpPort.MoveBucket{
+			Name: encode([]byte("moved/")),
+		})
+
+		require.Equal(t, []byte("value"), get(t, []byte("moved/"), []byte("copied/")))
+	})
+
+	t.Run("batch", func(t *testing.T) {
+		post(t, "/api/batch/"+encodedBucket, httpPort.BatchWrite{
+			Operations: []httpPort.BatchWriteOperation{
+				{
+					Type:  string(application.KeyOperationTypePut),
+					Key:   encode([]byte("batch/")),
+					Value: hex.EncodeToString([]byte("batch value")),
+				},
+				{
+					Type: string(application.KeyOperationTypeDelete),
+					Key:  encode([]byte("renamed/")),
+				},
+			},
+		})
+
+		require.Equal(t, []byte("batch value"), get(t, bucketName, []byte("batch/")))
+		require.Nil(t, get(t, bucketName, []byte("renamed/")))
+	})
+}
+
+type testDatabases struct {
+	app *application.Application
+}
+
+func (d testDatabases) Names() []string {
+	return []string{"test"}
+}
+
+func (d testDatabases) Get(name string) (*application.Application, bool) {
+	return d.app, name == "test"
+}
@@ -0,0 +1,40 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/contentforward/bolt-ui/internal/config"
+	httpPort "github.com/contentforward/bolt-ui/ports/http"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricsRequestDurationHistogram(t *testing.T) {
+	testApp := NewTracker(t)
+
+	conf := &config.Config{
+		InsecureToken:   true,
+		Metrics:         true,
+		InsecureMetrics: true,
+	}
+
+	handler, err := httpPort.NewHandler(testDatabases{testApp.Application}, httpPort.NewTokenAuthProvider(conf), conf)
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+		require.Equal(t, http.StatusOK, recorder.Code)
+	}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	body := recorder.Body.String()
+	require.Contains(t, body, "# TYPE bolt_ui_http_request_duration_seconds histogram\n")
+	require.Contains(t, body, `bolt_ui_http_request_duration_seconds_bucket{method="GET",route="/healthz",status="200",le="10"} 2`+"\n")
+	require.Contains(t, body, `bolt_ui_http_request_duration_seconds_bucket{method="GET",route="/healthz",status="200",le="+Inf"} 2`+"\n")
+	require.Contains(t, body, `bolt_ui_http_request_duration_seconds_count{method="GET",route="/healthz",status="200"} 2`+"\n")
+}
@@ -0,0 +1,68 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/contentforward/bolt-ui/adapters"
+	"github.com/contentforward/bolt-ui/application"
+	"github.com/contentforward/bolt-ui/internal/fixture"
+	"github.com/contentforward/bolt-ui/internal/wire"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/bbolt"
+)
+
+func TestReadOnlyDatabase(t *testing.T) {
+	file, cleanup := fixture.File(t)
+	defer cleanup()
+
+	bucketName := []byte("bucket")
+	key := []byte("key")
+	value := []byte("value")
+
+	db, err := adapters.NewBolt(file, false, adapters.DefaultOpenTimeout)
+	require.NoError(t, err)
+
+	err = db.Update(func(tx *bbolt.Tx) error {
+		bucket, err := tx.CreateBucket(bucketName)
+		if err != nil {
+			return err
+		}
+
+		return bucket.Put(key, value)
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	db, err = adapters.NewBolt(file, true, adapters.DefaultOpenTimeout)
+	require.NoError(t, err)
+	defer db.Close()
+
+	otherDB, err := adapters.NewBolt(file, true, adapters.DefaultOpenTimeout)
+	require.NoError(t, err, "the file should be opened with a shared lock")
+	defer otherDB.Close()
+
+	testApp, err := wire.BuildApplicationForTest(db)
+	require.NoError(t, err)
+
+	path := []application.Key{
+		application.MustNewKey(bucketName),
+	}
+
+	v, err := testApp.Application.GetValue.Execute(
+		application.GetValue{
+			Path: path,
+			Key:  application.MustNewKey(key),
+		},
+	)
+	require.NoError(t, err)
+	require.Equal(t, value, v.Bytes())
+
+	err = testApp.Application.PutValue.Execute(
+		application.PutValue{
+			Path:  path,
+			Key:   application.MustNewKey(key),
+			Value: application.MustNewValue([]byte("other value")),
+		},
+	)
+	require.ErrorIs(t, err, application.ErrReadOnly)
+}
@@ -0,0 +1,368 @@
+package tests
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/contentforward/bolt-ui/application"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/bbolt"
+)
+
+func TestSearchAllBuckets(t *testing.T) {
+	testApp := NewTracker(t)
+
+	err := testApp.DB.Update(func(tx *bbolt.Tx) error {
+		users, err := tx.CreateBucket([]byte("users"))
+		if err != nil {
+			return err
+		}
+
+		if err := users.Put([]byte("user-1"), []byte("value")); err != nil {
+			return err
+		}
+
+		archive, err := users.CreateBucket([]byte("archive"))
+		if err != nil {
+			return err
+		}
+
+		if err := archive.Put([]byte("user-2"), []byte("value")); err != nil {
+			return err
+		}
+
+		settings, err := tx.CreateBucket([]byte("settings"))
+		if err != nil {
+			return err
+		}
+
+		if err := settings.Put([]byte("theme"), []byte("user")); err != nil {
+			return err
+		}
+
+		_, err = settings.CreateBucket([]byte("per-user"))
+		return err
+	})
+	require.NoError(t, err)
+
+	users := application.MustNewKey([]byte("users"))
+	archive := application.MustNewKey([]byte("archive"))
+	settings := application.MustNewKey([]byte("settings"))
+
+	testCases := []struct {
+		Name         string
+		Pattern      string
+		Limit        int
+		ExpectedHits []application.SearchHit
+	}{
+		{
+			Name:    "substring",
+			Pattern: "user",
+			Limit:   10,
+			ExpectedHits: []application.SearchHit{
+				{
+					Path:   []application.Key{settings},
+					Bucket: true,
+					Key:    application.MustNewKey([]byte("per-user")),
+				},
+				{
+					Path:   nil,
+					Bucket: true,
+					Key:    users,
+				},
+				{
+					Path:   []application.Key{users, archive},
+					Bucket: false,
+					Key:    application.MustNewKey([]byte("user-2")),
+				},
+				{
+					Path:   []application.Key{users},
+					Bucket: false,
+					Key:    application.MustNewKey([]byte("user-1")),
+				},
+			},
+		},
+		{
+			Name:    "glob",
+			Pattern: "user-?",
+			Limit:   10,
+			ExpectedHits: []application.SearchHit{
+				{
+					Path:   []application.Key{users, archive},
+					Bucket: false,
+					Key:    application.MustNewKey([]byte("user-2")),
+				},
+				{
+					Path:   []application.Key{users},
+					Bucket: false,
+					Key:    application.MustNewKey([]byte("user-1")),
+				},
+			},
+		},
+		{
+			Name:    "limit",
+			Pattern: "*user*",
+			Limit:   1,
+			ExpectedHits: []application.SearchHit{
+				{
+					Path:   []application.Key{settings},
+					Bucket: true,
+					Key:    application.MustNewKey([]byte("per-user")),
+				},
+			},
+		},
+		{
+			Name:         "no_hits",
+			Pattern:      "missing",
+			Limit:        10,
+			ExpectedHits: nil,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			hits, err := testApp.Application.SearchAllBuckets.Execute(
+				application.SearchAllBuckets{
+					Context: context.Background(),
+					Pattern: testCase.Pattern,
+					Limit:   testCase.Limit,
+				},
+			)
+			require.NoError(t, err)
+			require.Equal(t, testCase.ExpectedHits, hits)
+		})
+	}
+}
+
+func TestSearchAllBucketsHonorsContext(t *testing.T) {
+	testApp := NewTracker(t)
+
+	err := testApp.DB.Update(func(tx *bbolt.Tx) error {
+		bucket, err := tx.CreateBucket([]byte("bucket"))
+		if err != nil {
+			return err
+		}
+
+		for i := 0; i < 1000; i++ {
+			if err := bucket.Put([]byte{byte(i >> 8), byte(i)}, []byte("value")); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = testApp.Application.SearchAllBuckets.Execute(
+		application.SearchAllBuckets{
+			Context: ctx,
+			Pattern: "missing",
+			Limit:   10,
+		},
+	)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestSearchValues(t *testing.T) {
+	testApp := NewTracker(t)
+
+	err := testApp.DB.Update(func(tx *bbolt.Tx) error {
+		bucket, err := tx.CreateBucket([]byte("bucket"))
+		if err != nil {
+			return err
+		}
+
+		if err := bucket.Put([]byte("json"), []byte(`{"user": {"name": "alice", "tags": ["admin", "staff"]}}`)); err != nil {
+			return err
+		}
+
+		if err := bucket.Put([]byte("text"), []byte("hello alice")); err != nil {
+			return err
+		}
+
+		if err := bucket.Put([]byte("binary"), []byte{0xff, 'a', 'l', 'i', 'c', 'e'}); err != nil {
+			return err
+		}
+
+		child, err := bucket.CreateBucket([]byte("child"))
+		if err != nil {
+			return err
+		}
+
+		if err := child.Put([]byte("long"), []byte(strings.Repeat("x", 50)+"alice"+strings.Repeat("y", 50))); err != nil {
+			return err
+		}
+
+		other, err := tx.CreateBucket([]byte("other"))
+		if err != nil {
+			return err
+		}
+
+		return other.Put([]byte("text"), []byte("alice"))
+	})
+	require.NoError(t, err)
+
+	bucket := application.MustNewKey([]byte("bucket"))
+	child := application.MustNewKey([]byte("child"))
+
+	testCases := []struct {
+		Name         string
+		Path         []application.Key
+		Query        application.ValueQuery
+		Limit        int
+		ExpectedHits []application.SearchHit
+	}{
+		{
+			Name: "substring",
+			Path: []application.Key{bucket},
+			Query: application.ValueQuery{
+				Substring: "alice",
+			},
+			Limit: 10,
+			ExpectedHits: []application.SearchHit{
+				{
+					Path:    []application.Key{bucket, child},
+					Key:     application.MustNewKey([]byte("long")),
+					Snippet: "…" + strings.Repeat("x", 32) + "alice" + strings.Repeat("y", 32) + "…",
+				},
+				{
+					Path:    []application.Key{bucket},
+					Key:     application.MustNewKey([]byte("json")),
+					Snippet: `{"user": {"name": "alice", "tags": ["admin", "staff"]}}`,
+				},
+				{
+					Path:    []application.Key{bucket},
+					Key:     application.MustNewKey([]byte("text")),
+					Snippet: "hello alice",
+				},
+			},
+		},
+		{
+			Name: "include_binary",
+			Path: []application.Key{bucket},
+			Query: application.ValueQuery{
+				Substring:     "alice",
+				IncludeBinary: true,
+			},
+			Limit: 1,
+			ExpectedHits: []application.SearchHit{
+				{
+					Path:    []application.Key{bucket},
+					Key:     application.MustNewKey([]byte("binary")),
+					Snippet: "ff616c696365",
+				},
+			},
+		},
+		{
+			Name: "json_path",
+			Path: []application.Key{bucket},
+			Query: application.ValueQuery{
+				JSONPath: "user.tags",
+			},
+			Limit: 10,
+			ExpectedHits: []application.SearchHit{
+				{
+					Path:    []application.Key{bucket},
+					Key:     application.MustNewKey([]byte("json")),
+					Snippet: `["admin","staff"]`,
+				},
+			},
+		},
+		{
+			Name: "json_path_and_substring",
+			Path: []application.Key{bucket},
+			Query: application.ValueQuery{
+				JSONPath:  "user.tags.1",
+				Substring: "admin",
+			},
+			Limit:        10,
+			ExpectedHits: nil,
+		},
+		{
+			Name: "entire_database",
+			Path: nil,
+			Query: application.ValueQuery{
+				Substring: "alice",
+			},
+			Limit: 10,
+			ExpectedHits: []application.SearchHit{
+				{
+					Path:    []application.Key{bucket, child},
+					Key:     application.MustNewKey([]byte("long")),
+					Snippet: "…" + strings.Repeat("x", 32) + "alice" + strings.Repeat("y", 32) + "…",
+				},
+				{
+					Path:    []application.Key{bucket},
+					Key:     application.MustNewKey([]byte("json")),
+					Snippet: `{"user": {"name": "alice", "tags": ["admin", "staff"]}}`,
+				},
+				{
+					Path:    []application.Key{bucket},
+					Key:     application.MustNewKey([]byte("text")),
+					Snippet: "hello alice",
+				},
+				{
+					Path:    []application.Key{application.MustNewKey([]byte("other"))},
+					Key:     application.MustNewKey([]byte("text")),
+					Snippet: "alice",
+				},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			var hits []application.SearchHit
+
+			err := testApp.Application.SearchValues.Execute(
+				application.SearchValues{
+					Context: context.Background(),
+					Path:    testCase.Path,
+					Query:   testCase.Query,
+					Limit:   testCase.Limit,
+					Writer: func(hit application.SearchHit) error {
+						hits = append(hits, hit)
+						return nil
+					},
+				},
+			)
+			require.NoError(t, err)
+			require.Equal(t, testCase.ExpectedHits, hits)
+		})
+	}
+}
+
+func TestSearchValuesHonorsContext(t *testing.T) {
+	testApp := NewTracker(t)
+
+	err := testApp.DB.Update(func(tx *bbolt.Tx) error {
+		bucket, err := tx.CreateBucket([]byte("bucket"))
+		if err != nil {
+			return err
+		}
+
+		return bucket.Put([]byte("key"), []byte("value"))
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err = testApp.Application.SearchValues.Execute(
+		application.SearchValues{
+			Context: ctx,
+			Query: application.ValueQuery{
+				Substring: "value",
+			},
+			Limit: 10,
+			Writer: func(hit application.SearchHit) error {
+				return nil
+			},
+		},
+	)
+	require.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,137 @@
+package tests
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/contentforward/bolt-ui/internal/config"
+	httpPort "github.com/contentforward/bolt-ui/ports/http"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServerListensOnEphemeralPort(t *testing.T) {
+	conf := &config.Config{
+		ServeAddress: "127.0.0.1:0",
+		InsecureTLS:  true,
+	}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello"))
+	})
+
+	server := httpPort.NewServer(handler, conf)
+	require.Nil(t, server.Addr())
+
+	require.NoError(t, server.Listen())
+	require.NotNil(t, server.Addr())
+
+	serveErr := make(chan error)
+	go func() {
+		serveErr <- server.Serve()
+	}()
+
+	response, err := http.Get("http://" + server.Addr().String())
+	require.NoError(t, err)
+	defer response.Body.Close()
+
+	body, err := ioutil.ReadAll(response.Body)
+	require.NoError(t, err)
+	require.Equal(t, "hello", string(body))
+
+	require.NoError(t, server.Shutdown(context.Background()))
+	require.NoError(t, <-serveErr)
+}
+
+func TestServerRateLimitExemptsHealthzUnderBasePath(t *testing.T) {
+	conf := &config.Config{
+		ServeAddress:   "127.0.0.1:0",
+		InsecureTLS:    true,
+		BasePath:       "/dbadmin",
+		RateLimit:      0.001,
+		RateLimitBurst: 1,
+	}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello"))
+	})
+
+	server := httpPort.NewServer(handler, conf)
+	require.NoError(t, server.Listen())
+
+	serveErr := make(chan error)
+	go func() {
+		serveErr <- server.Serve()
+	}()
+
+	get := func(path string) int {
+		response, err := http.Get("http://" + server.Addr().String() + path)
+		require.NoError(t, err)
+		defer response.Body.Close()
+		return response.StatusCode
+	}
+
+	require.Equal(t, http.StatusOK, get("/dbadmin/api/"))
+	require.Equal(t, http.StatusTooManyRequests, get("/dbadmin/api/"))
+	require.Equal(t, http.StatusTooManyRequests, get("/healthz"))
+
+	for i := 0; i < 3; i++ {
+		require.Equal(t, http.StatusOK, get("/dbadmin/healthz"))
+	}
+
+	require.NoError(t, server.Shutdown(context.Background()))
+	require.NoError(t, <-serveErr)
+}
+
+func TestServerCORS(t *testing.T) {
+	conf := &config.Config{
+		ServeAddress: "127.0.0.1:0",
+		InsecureTLS:  true,
+		CORSOrigins:  []string{"https://example.com"},
+	}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"etag"`)
+	})
+
+	server := httpPort.NewServer(handler, conf)
+	require.NoError(t, server.Listen())
+
+	serveErr := make(chan error)
+	go func() {
+		serveErr <- server.Serve()
+	}()
+
+	url := "http://" + server.Addr().String() + "/api/"
+
+	t.Run("preflight", func(t *testing.T) {
+		request, err := http.NewRequest(http.MethodOptions, url, nil)
+		require.NoError(t, err)
+		request.Header.Set("Origin", "https://example.com")
+		request.Header.Set("Access-Control-Request-Method", http.MethodPut)
+		request.Header.Set("Access-Control-Request-Headers", "Authorization, If-Match")
+
+		response, err := http.DefaultClient.Do(request)
+		require.NoError(t, err)
+		defer response.Body.Close()
+
+		require.Equal(t, "https://example.com", response.Header.Get("Access-Control-Allow-Origin"))
+		require.Equal(t, "Authorization, If-Match", response.Header.Get("Access-Control-Allow-Headers"))
+	})
+
+	t.Run("exposed_headers", func(t *testing.T) {
+		request, err := http.NewRequest(http.MethodGet, url, nil)
+		require.NoError(t, err)
+		request.Header.Set("Origin", "https://example.com")
+
+		response, err := http.DefaultClient.Do(request)
+		require.NoError(t, err)
+		defer response.Body.Close()
+
+		require.Equal(t, "Etag", response.Header.Get("Access-Control-Expose-Headers"))
+	})
+
+	require.NoError(t, server.Shutdown(context.Background()))
+	require.NoError(t, <-serveErr)
+}
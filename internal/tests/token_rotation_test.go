@@ -0,0 +1,64 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/contentforward/bolt-ui/internal/config"
+	httpPort "github.com/contentforward/bolt-ui/ports/http"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotateToken(t *testing.T) {
+	testApp := NewTracker(t)
+
+	conf := &config.Config{
+		Token: "token",
+	}
+
+	handler, err := httpPort.NewHandler(testDatabases{testApp.Application}, httpPort.NewTokenAuthProvider(conf), conf)
+	require.NoError(t, err)
+
+	request := func(method, target, token string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, target, nil)
+		r.Header.Set("Access-Token", token)
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, r)
+		return recorder
+	}
+
+	recorder := request(http.MethodPost, "/api/token", "invalid")
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+
+	recorder = request(http.MethodPost, "/api/token", "token")
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var rotated httpPort.Token
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rotated))
+	require.NotEmpty(t, rotated.Token)
+	require.NotEqual(t, "token", rotated.Token)
+
+	recorder = request(http.MethodGet, "/api/keys/", "token")
+	require.Equal(t, http.StatusUnauthorized, recorder.Code, "previous token is rejected")
+
+	recorder = request(http.MethodGet, "/api/keys/", rotated.Token)
+	require.Equal(t, http.StatusOK, recorder.Code, "new token is accepted")
+}
+
+func TestRotateTokenWithTokenValidationDisabled(t *testing.T) {
+	testApp := NewTracker(t)
+
+	conf := &config.Config{
+		InsecureToken: true,
+	}
+
+	handler, err := httpPort.NewHandler(testDatabases{testApp.Application}, httpPort.NewTokenAuthProvider(conf), conf)
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/api/token", nil))
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+}
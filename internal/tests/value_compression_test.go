@@ -0,0 +1,120 @@
+package tests
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"github.com/contentforward/bolt-ui/adapters"
+	"github.com/contentforward/bolt-ui/application"
+	"github.com/contentforward/bolt-ui/internal/fixture"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/bbolt"
+)
+
+const testCompressionThreshold = 1024
+
+func TestValueCompressionRoundTrip(t *testing.T) {
+	random := make([]byte, 2*testCompressionThreshold)
+	_, err := rand.Read(random)
+	require.NoError(t, err)
+
+	framed, err := adapters.NewValueCompression(1).Encode(bytes.Repeat([]byte("a"), 500))
+	require.NoError(t, err)
+
+	testCases := []struct {
+		Name       string
+		Value      []byte
+		Compressed bool
+	}{
+		{
+			Name:       "below_threshold",
+			Value:      bytes.Repeat([]byte("a"), testCompressionThreshold-1),
+			Compressed: false,
+		},
+		{
+			Name:       "at_threshold",
+			Value:      bytes.Repeat([]byte("a"), testCompressionThreshold),
+			Compressed: true,
+		},
+		{
+			Name:       "above_threshold",
+			Value:      bytes.Repeat([]byte("a"), testCompressionThreshold+1),
+			Compressed: true,
+		},
+		{
+			Name:       "incompressible",
+			Value:      random,
+			Compressed: false,
+		},
+		{
+			Name:       "starts_with_magic",
+			Value:      append([]byte{0x00, 'b', 'u', 'z'}, bytes.Repeat([]byte("a"), 20)...),
+			Compressed: false,
+		},
+		{
+			Name:       "looks_compressed",
+			Value:      framed,
+			Compressed: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			db, cleanup := fixture.Bolt(t)
+			defer cleanup()
+
+			compression := adapters.NewValueCompression(testCompressionThreshold)
+			path := []application.Key{application.MustNewKey([]byte("bucket"))}
+			key := application.MustNewKey([]byte("key"))
+
+			err := db.Update(func(tx *bbolt.Tx) error {
+				database := adapters.NewDatabase(tx, compression)
+
+				if err := database.CreateBucket(nil, path[0]); err != nil {
+					return err
+				}
+
+				return database.PutValue(path, key, application.MustNewValue(testCase.Value))
+			})
+			require.NoError(t, err)
+
+			err = db.View(func(tx *bbolt.Tx) error {
+				stored := tx.Bucket(path[0].Bytes()).Get(key.Bytes())
+				require.Equal(t, !testCase.Compressed, bytes.Equal(testCase.Value, stored))
+
+				value, err := adapters.NewDatabase(tx, compression).GetValue(path, key)
+				require.NoError(t, err)
+				require.Equal(t, testCase.Value, value.Bytes())
+
+				page, err := adapters.NewDatabase(tx, compression).ListKeys(path, nil, 1, true, false)
+				require.NoError(t, err)
+				require.Len(t, page.Keys, 1)
+				require.Equal(t, len(testCase.Value), *page.Keys[0].Size)
+
+				return nil
+			})
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestValueCompressionLeavesOtherValuesUnchanged(t *testing.T) {
+	compression := adapters.NewValueCompression(testCompressionThreshold)
+
+	framed, err := compression.Encode(bytes.Repeat([]byte("a"), testCompressionThreshold))
+	require.NoError(t, err)
+	require.Equal(t, bytes.Repeat([]byte("a"), testCompressionThreshold), compression.Decode(framed))
+
+	truncated := framed[:len(framed)-1]
+	require.Equal(t, truncated, compression.Decode(truncated))
+
+	trailing := append(append([]byte{}, framed...), 'a')
+	require.Equal(t, trailing, compression.Decode(trailing))
+
+	wrongLength := append([]byte{}, framed...)
+	wrongLength[11]++
+	require.Equal(t, wrongLength, compression.Decode(wrongLength))
+
+	require.Equal(t, framed, adapters.NewValueCompression(0).Decode(framed), "values are not decompressed if compression is disabled")
+}
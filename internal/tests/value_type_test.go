@@ -0,0 +1,95 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/contentforward/bolt-ui/application"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectValueType(t *testing.T) {
+	testCases := []struct {
+		Name         string
+		Value        []byte
+		ExpectedType application.ValueType
+	}{
+		{
+			Name:         "json_object",
+			Value:        []byte(`{"key": ["value", 1, true]}`),
+			ExpectedType: application.ValueTypeJSON,
+		},
+		{
+			Name:         "json_number",
+			Value:        []byte(`123`),
+			ExpectedType: application.ValueTypeJSON,
+		},
+		{
+			Name:         "ascii",
+			Value:        []byte("some text"),
+			ExpectedType: application.ValueTypeText,
+		},
+		{
+			Name:         "utf8",
+			Value:        []byte("zażółć gęślą jaźń"),
+			ExpectedType: application.ValueTypeText,
+		},
+		{
+			Name:         "invalid_utf8",
+			Value:        []byte{0xff, 0xfe, 0xfd},
+			ExpectedType: application.ValueTypeBinary,
+		},
+		{
+			Name:         "empty",
+			Value:        nil,
+			ExpectedType: application.ValueTypeText,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			require.Equal(t, testCase.ExpectedType, application.DetectValueType(testCase.Value))
+		})
+	}
+}
+
+func TestFormatJSON(t *testing.T) {
+	testCases := []struct {
+		Name              string
+		Value             []byte
+		ExpectedValue     []byte
+		ExpectedFormatted bool
+	}{
+		{
+			Name:              "object_keys_are_sorted",
+			Value:             []byte(`{"b": [1, 2.50], "a": {"d": "<tag>", "c": null}}`),
+			ExpectedValue:     []byte("{\n  \"a\": {\n    \"c\": null,\n    \"d\": \"<tag>\"\n  },\n  \"b\": [\n    1,\n    2.50\n  ]\n}"),
+			ExpectedFormatted: true,
+		},
+		{
+			Name:              "string",
+			Value:             []byte(` "value" `),
+			ExpectedValue:     []byte(`"value"`),
+			ExpectedFormatted: true,
+		},
+		{
+			Name:              "invalid_json",
+			Value:             []byte(`{"key": `),
+			ExpectedValue:     []byte(`{"key": `),
+			ExpectedFormatted: false,
+		},
+		{
+			Name:              "binary",
+			Value:             []byte{0xff, 0xfe, 0xfd},
+			ExpectedValue:     []byte{0xff, 0xfe, 0xfd},
+			ExpectedFormatted: false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			value, formatted := application.FormatJSON(testCase.Value)
+			require.Equal(t, testCase.ExpectedFormatted, formatted)
+			require.Equal(t, string(testCase.ExpectedValue), string(value))
+		})
+	}
+}
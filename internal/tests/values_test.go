@@ -0,0 +1,834 @@
+package tests
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/contentforward/bolt-ui/application"
+	"github.com/contentforward/bolt-ui/internal/wire"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/bbolt"
+)
+
+func TestGetValue(t *testing.T) {
+	testApp := NewTracker(t)
+
+	bucketName := []byte("bucket")
+	childBucketName := []byte("child")
+	key := []byte("key")
+	emptyKey := []byte("empty")
+	value := []byte("value")
+
+	err := testApp.DB.Update(func(tx *bbolt.Tx) error {
+		bucket, err := tx.CreateBucket(bucketName)
+		if err != nil {
+			return err
+		}
+
+		if _, err := bucket.CreateBucket(childBucketName); err != nil {
+			return err
+		}
+
+		if err := bucket.Put(emptyKey, nil); err != nil {
+			return err
+		}
+
+		return bucket.Put(key, value)
+	})
+	require.NoError(t, err)
+
+	path := []application.Key{
+		application.MustNewKey(bucketName),
+	}
+
+	testCases := []struct {
+		Name          string
+		Path          []application.Key
+		Key           []byte
+		ExpectedValue application.Value
+		ExpectedError error
+	}{
+		{
+			Name:          "value",
+			Path:          path,
+			Key:           key,
+			ExpectedValue: application.MustNewValue(value),
+		},
+		{
+			Name:          "empty_value",
+			Path:          path,
+			Key:           emptyKey,
+			ExpectedValue: nilValue,
+		},
+		{
+			Name:          "key_not_found",
+			Path:          path,
+			Key:           []byte("missing"),
+			ExpectedError: application.ErrKeyNotFound,
+		},
+		{
+			Name:          "bucket",
+			Path:          path,
+			Key:           childBucketName,
+			ExpectedError: application.ErrNotAValue,
+		},
+		{
+			Name: "bucket_not_found",
+			Path: []application.Key{
+				application.MustNewKey([]byte("missing")),
+			},
+			Key:           key,
+			ExpectedError: application.ErrBucketNotFound,
+		},
+		{
+			Name:          "root",
+			Path:          nil,
+			Key:           bucketName,
+			ExpectedError: application.ErrNotAValue,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			v, err := testApp.Application.GetValue.Execute(
+				application.GetValue{
+					Path: testCase.Path,
+					Key:  application.MustNewKey(testCase.Key),
+				},
+			)
+			if testCase.ExpectedError != nil {
+				require.ErrorIs(t, err, testCase.ExpectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, testCase.ExpectedValue.Bytes(), v.Bytes())
+		})
+	}
+}
+
+func TestPutValue(t *testing.T) {
+	testApp := NewTracker(t)
+
+	bucketName := []byte("bucket")
+	childBucketName := []byte("child")
+	key := []byte("key")
+
+	err := testApp.DB.Update(func(tx *bbolt.Tx) error {
+		bucket, err := tx.CreateBucket(bucketName)
+		if err != nil {
+			return err
+		}
+
+		_, err = bucket.CreateBucket(childBucketName)
+		return err
+	})
+	require.NoError(t, err)
+
+	path := []application.Key{
+		application.MustNewKey(bucketName),
+	}
+
+	for _, value := range []string{"created", "overwritten"} {
+		err = testApp.Application.PutValue.Execute(
+			application.PutValue{
+				Path:  path,
+				Key:   application.MustNewKey(key),
+				Value: application.MustNewValue([]byte(value)),
+			},
+		)
+		require.NoError(t, err)
+
+		v, err := testApp.Application.GetValue.Execute(
+			application.GetValue{
+				Path: path,
+				Key:  application.MustNewKey(key),
+			},
+		)
+		require.NoError(t, err)
+		require.Equal(t, []byte(value), v.Bytes())
+	}
+
+	err = testApp.Application.PutValue.Execute(
+		application.PutValue{
+			Path:  path,
+			Key:   application.MustNewKey(childBucketName),
+			Value: application.MustNewValue([]byte("value")),
+		},
+	)
+	require.ErrorIs(t, err, application.ErrNotAValue)
+
+	err = testApp.Application.PutValue.Execute(
+		application.PutValue{
+			Path: []application.Key{
+				application.MustNewKey(bucketName),
+				application.MustNewKey([]byte("missing")),
+			},
+			Key:   application.MustNewKey(key),
+			Value: application.MustNewValue([]byte("value")),
+		},
+	)
+	require.ErrorIs(t, err, application.ErrBucketNotFound)
+
+	err = testApp.Application.PutValue.Execute(
+		application.PutValue{
+			Path:  nil,
+			Key:   application.MustNewKey(key),
+			Value: application.MustNewValue([]byte("value")),
+		},
+	)
+	require.Error(t, err)
+}
+
+func TestAppendValue(t *testing.T) {
+	testApp := NewTracker(t)
+
+	bucketName := []byte("bucket")
+
+	err := testApp.DB.Update(func(tx *bbolt.Tx) error {
+		bucket, err := tx.CreateBucket(bucketName)
+		if err != nil {
+			return err
+		}
+
+		return bucket.Put(application.NewUint64Key(3).Bytes(), []byte("existing"))
+	})
+	require.NoError(t, err)
+
+	path := []application.Key{
+		application.MustNewKey(bucketName),
+	}
+
+	for _, expectedSequence := range []uint64{1, 2} {
+		appended, err := testApp.Application.AppendValue.Execute(
+			application.AppendValue{
+				Path:  path,
+				Value: application.MustNewValue([]byte("value")),
+			},
+		)
+		require.NoError(t, err)
+		require.Equal(t, expectedSequence, appended.Sequence)
+		require.Equal(t, application.NewUint64Key(expectedSequence), appended.Key)
+
+		value, err := testApp.Application.GetValue.Execute(
+			application.GetValue{
+				Path: path,
+				Key:  appended.Key,
+			},
+		)
+		require.NoError(t, err)
+		require.Equal(t, []byte("value"), value.Bytes())
+	}
+
+	_, err = testApp.Application.AppendValue.Execute(
+		application.AppendValue{
+			Path:  path,
+			Value: application.MustNewValue([]byte("value")),
+		},
+	)
+	require.ErrorIs(t, err, application.ErrValueExists)
+
+	err = testApp.DB.View(func(tx *bbolt.Tx) error {
+		require.Equal(t, uint64(2), tx.Bucket(bucketName).Sequence(), "sequence should be rolled back")
+		require.Equal(t, []byte("existing"), tx.Bucket(bucketName).Get(application.NewUint64Key(3).Bytes()))
+		return nil
+	})
+	require.NoError(t, err)
+
+	_, err = testApp.Application.AppendValue.Execute(
+		application.AppendValue{
+			Path: []application.Key{
+				application.MustNewKey([]byte("missing")),
+			},
+			Value: application.MustNewValue([]byte("value")),
+		},
+	)
+	require.ErrorIs(t, err, application.ErrBucketNotFound)
+}
+
+func TestAppendValueAfterMovingBucket(t *testing.T) {
+	testApp := NewTracker(t)
+
+	parent := application.MustNewKey([]byte("parent"))
+	source := []application.Key{parent, application.MustNewKey([]byte("source"))}
+	renamed := []application.Key{parent, application.MustNewKey([]byte("renamed"))}
+
+	err := testApp.DB.Update(func(tx *bbolt.Tx) error {
+		bucket, err := tx.CreateBucket(parent.Bytes())
+		if err != nil {
+			return err
+		}
+
+		child, err := bucket.CreateBucket(source[1].Bytes())
+		if err != nil {
+			return err
+		}
+
+		_, err = child.CreateBucket([]byte("nested"))
+		return err
+	})
+	require.NoError(t, err)
+
+	nested := application.MustNewKey([]byte("nested"))
+	nestedSource := []application.Key{parent, source[1], nested}
+	nestedRenamed := []application.Key{parent, renamed[1], nested}
+
+	for _, path := range [][]application.Key{source, nestedSource} {
+		for i := 0; i < 2; i++ {
+			_, err := testApp.Application.AppendValue.Execute(
+				application.AppendValue{
+					Path:  path,
+					Value: application.MustNewValue([]byte("value")),
+				},
+			)
+			require.NoError(t, err)
+		}
+	}
+
+	err = testApp.Application.MoveBucket.Execute(
+		application.MoveBucket{
+			Path:              source,
+			DestinationParent: []application.Key{parent},
+			Name:              renamed[1],
+		},
+	)
+	require.NoError(t, err)
+
+	for _, path := range [][]application.Key{renamed, nestedRenamed} {
+		appended, err := testApp.Application.AppendValue.Execute(
+			application.AppendValue{
+				Path:  path,
+				Value: application.MustNewValue([]byte("value")),
+			},
+		)
+		require.NoError(t, err)
+		require.Equal(t, uint64(3), appended.Sequence, "sequence should be preserved")
+	}
+}
+
+func TestDeleteKey(t *testing.T) {
+	testApp := NewTracker(t)
+
+	bucketName := []byte("bucket")
+	childBucketName := []byte("child")
+	key := []byte("key")
+
+	err := testApp.DB.Update(func(tx *bbolt.Tx) error {
+		bucket, err := tx.CreateBucket(bucketName)
+		if err != nil {
+			return err
+		}
+
+		if _, err := bucket.CreateBucket(childBucketName); err != nil {
+			return err
+		}
+
+		return bucket.Put(key, []byte("value"))
+	})
+	require.NoError(t, err)
+
+	path := []application.Key{
+		application.MustNewKey(bucketName),
+	}
+
+	err = testApp.Application.DeleteKey.Execute(
+		application.DeleteKey{
+			Path: path,
+			Key:  application.MustNewKey(key),
+		},
+	)
+	require.NoError(t, err)
+
+	_, err = testApp.Application.GetValue.Execute(
+		application.GetValue{
+			Path: path,
+			Key:  application.MustNewKey(key),
+		},
+	)
+	require.ErrorIs(t, err, application.ErrKeyNotFound)
+
+	err = testApp.Application.DeleteKey.Execute(
+		application.DeleteKey{
+			Path: path,
+			Key:  application.MustNewKey(key),
+		},
+	)
+	require.ErrorIs(t, err, application.ErrKeyNotFound)
+
+	err = testApp.Application.DeleteKey.Execute(
+		application.DeleteKey{
+			Path: path,
+			Key:  application.MustNewKey(childBucketName),
+		},
+	)
+	require.ErrorIs(t, err, application.ErrNotAValue)
+}
+
+func TestStreamValue(t *testing.T) {
+	testApp := NewTracker(t)
+
+	bucketName := []byte("bucket")
+	key := []byte("key")
+	value := bytes.Repeat([]byte("value"), 1000)
+
+	err := testApp.DB.Update(func(tx *bbolt.Tx) error {
+		bucket, err := tx.CreateBucket(bucketName)
+		if err != nil {
+			return err
+		}
+
+		return bucket.Put(key, value)
+	})
+	require.NoError(t, err)
+
+	var size int
+	buf := &bytes.Buffer{}
+
+	err = testApp.Application.StreamValue.Execute(
+		application.StreamValue{
+			Path: []application.Key{application.MustNewKey(bucketName)},
+			Key:  application.MustNewKey(key),
+			Writer: func(s int, r io.Reader) error {
+				size = s
+				_, err := io.Copy(buf, r)
+				return err
+			},
+		},
+	)
+	require.NoError(t, err)
+	require.Equal(t, len(value), size)
+	require.Equal(t, value, buf.Bytes())
+
+	err = testApp.Application.StreamValue.Execute(
+		application.StreamValue{
+			Path: []application.Key{application.MustNewKey(bucketName)},
+			Key:  application.MustNewKey([]byte("missing")),
+			Writer: func(s int, r io.Reader) error {
+				t.Fatal("writer should not be called")
+				return nil
+			},
+		},
+	)
+	require.ErrorIs(t, err, application.ErrKeyNotFound)
+}
+
+func TestRenameKey(t *testing.T) {
+	bucketName := []byte("bucket")
+
+	path := []application.Key{
+		application.MustNewKey(bucketName),
+	}
+
+	testCases := []struct {
+		Name           string
+		OldKey         []byte
+		NewKey         []byte
+		Overwrite      bool
+		ExpectedErr    error
+		ExpectedValues map[string]string
+	}{
+		{
+			Name:   "rename",
+			OldKey: []byte("old"),
+			NewKey: []byte("new"),
+			ExpectedValues: map[string]string{
+				"new":      "old value",
+				"existing": "existing value",
+			},
+		},
+		{
+			Name:        "new_key_exists",
+			OldKey:      []byte("old"),
+			NewKey:      []byte("existing"),
+			ExpectedErr: application.ErrValueExists,
+			ExpectedValues: map[string]string{
+				"old":      "old value",
+				"existing": "existing value",
+			},
+		},
+		{
+			Name:      "new_key_exists_overwrite",
+			OldKey:    []byte("old"),
+			NewKey:    []byte("existing"),
+			Overwrite: true,
+			ExpectedValues: map[string]string{
+				"existing": "old value",
+			},
+		},
+		{
+			Name:        "new_key_is_a_bucket",
+			OldKey:      []byte("old"),
+			NewKey:      []byte("child"),
+			Overwrite:   true,
+			ExpectedErr: application.ErrNotAValue,
+			ExpectedValues: map[string]string{
+				"old":      "old value",
+				"existing": "existing value",
+			},
+		},
+		{
+			Name:        "old_key_does_not_exist",
+			OldKey:      []byte("missing"),
+			NewKey:      []byte("new"),
+			ExpectedErr: application.ErrKeyNotFound,
+			ExpectedValues: map[string]string{
+				"old":      "old value",
+				"existing": "existing value",
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			testApp := NewTracker(t)
+
+			err := testApp.DB.Update(func(tx *bbolt.Tx) error {
+				bucket, err := tx.CreateBucket(bucketName)
+				if err != nil {
+					return err
+				}
+
+				if err := bucket.Put([]byte("old"), []byte("old value")); err != nil {
+					return err
+				}
+
+				if err := bucket.Put([]byte("existing"), []byte("existing value")); err != nil {
+					return err
+				}
+
+				_, err = bucket.CreateBucket([]byte("child"))
+				return err
+			})
+			require.NoError(t, err)
+
+			err = testApp.Application.RenameKey.Execute(
+				application.RenameKey{
+					Path:      path,
+					OldKey:    application.MustNewKey(testCase.OldKey),
+					NewKey:    application.MustNewKey(testCase.NewKey),
+					Overwrite: testCase.Overwrite,
+				},
+			)
+			if testCase.ExpectedErr != nil {
+				require.ErrorIs(t, err, testCase.ExpectedErr)
+			} else {
+				require.NoError(t, err)
+			}
+
+			values := make(map[string]string)
+
+			err = testApp.DB.View(func(tx *bbolt.Tx) error {
+				return tx.Bucket(bucketName).ForEach(func(k, v []byte) error {
+					if v != nil {
+						values[string(k)] = string(v)
+					}
+					return nil
+				})
+			})
+			require.NoError(t, err)
+			require.Equal(t, testCase.ExpectedValues, values)
+		})
+	}
+}
+
+func TestCopyKey(t *testing.T) {
+	source := application.MustNewKey([]byte("source"))
+	destination := application.MustNewKey([]byte("destination"))
+	missing := application.MustNewKey([]byte("missing"))
+
+	key := application.MustNewKey([]byte("key"))
+	existing := application.MustNewKey([]byte("existing"))
+
+	testCases := []struct {
+		Name           string
+		Cmd            application.CopyKey
+		ExpectedErr    error
+		ExpectedValues map[string]map[string]string
+	}{
+		{
+			Name: "copy",
+			Cmd: application.CopyKey{
+				SourcePath:      []application.Key{source},
+				SourceKey:       key,
+				DestinationPath: []application.Key{destination},
+				DestinationKey:  key,
+			},
+			ExpectedValues: map[string]map[string]string{
+				"source":      {"key": "value"},
+				"destination": {"key": "value", "existing": "existing value"},
+			},
+		},
+		{
+			Name: "move",
+			Cmd: application.CopyKey{
+				SourcePath:      []application.Key{source},
+				SourceKey:       key,
+				DestinationPath: []application.Key{destination},
+				DestinationKey:  application.MustNewKey([]byte("new")),
+				Move:            true,
+			},
+			ExpectedValues: map[string]map[string]string{
+				"source":      {},
+				"destination": {"new": "value", "existing": "existing value"},
+			},
+		},
+		{
+			Name: "destination_key_exists",
+			Cmd: application.CopyKey{
+				SourcePath:      []application.Key{source},
+				SourceKey:       key,
+				DestinationPath: []application.Key{destination},
+				DestinationKey:  existing,
+				Move:            true,
+			},
+			ExpectedErr: application.ErrValueExists,
+			ExpectedValues: map[string]map[string]string{
+				"source":      {"key": "value"},
+				"destination": {"existing": "existing value"},
+			},
+		},
+		{
+			Name: "destination_key_exists_overwrite",
+			Cmd: application.CopyKey{
+				SourcePath:      []application.Key{source},
+				SourceKey:       key,
+				DestinationPath: []application.Key{destination},
+				DestinationKey:  existing,
+				Move:            true,
+				Overwrite:       true,
+			},
+			ExpectedValues: map[string]map[string]string{
+				"source":      {},
+				"destination": {"existing": "value"},
+			},
+		},
+		{
+			Name: "destination_bucket_does_not_exist",
+			Cmd: application.CopyKey{
+				SourcePath:      []application.Key{source},
+				SourceKey:       key,
+				DestinationPath: []application.Key{missing},
+				DestinationKey:  key,
+				Move:            true,
+			},
+			ExpectedErr: application.ErrBucketNotFound,
+			ExpectedValues: map[string]map[string]string{
+				"source":      {"key": "value"},
+				"destination": {"existing": "existing value"},
+			},
+		},
+		{
+			Name: "source_key_does_not_exist",
+			Cmd: application.CopyKey{
+				SourcePath:      []application.Key{source},
+				SourceKey:       missing,
+				DestinationPath: []application.Key{destination},
+				DestinationKey:  key,
+			},
+			ExpectedErr: application.ErrKeyNotFound,
+			ExpectedValues: map[string]map[string]string{
+				"source":      {"key": "value"},
+				"destination": {"existing": "existing value"},
+			},
+		},
+		{
+			Name: "move_onto_itself",
+			Cmd: application.CopyKey{
+				SourcePath:      []application.Key{source},
+				SourceKey:       key,
+				DestinationPath: []application.Key{source},
+				DestinationKey:  key,
+				Move:            true,
+			},
+			ExpectedValues: map[string]map[string]string{
+				"source":      {"key": "value"},
+				"destination": {"existing": "existing value"},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			testApp := NewTracker(t)
+
+			err := testApp.DB.Update(func(tx *bbolt.Tx) error {
+				sourceBucket, err := tx.CreateBucket(source.Bytes())
+				if err != nil {
+					return err
+				}
+
+				if err := sourceBucket.Put(key.Bytes(), []byte("value")); err != nil {
+					return err
+				}
+
+				destinationBucket, err := tx.CreateBucket(destination.Bytes())
+				if err != nil {
+					return err
+				}
+
+				return destinationBucket.Put(existing.Bytes(), []byte("existing value"))
+			})
+			require.NoError(t, err)
+
+			err = testApp.Application.CopyKey.Execute(testCase.Cmd)
+			if testCase.ExpectedErr != nil {
+				require.ErrorIs(t, err, testCase.ExpectedErr)
+			} else {
+				require.NoError(t, err)
+			}
+
+			values := make(map[string]map[string]string)
+
+			err = testApp.DB.View(func(tx *bbolt.Tx) error {
+				return tx.ForEach(func(name []byte, b *bbolt.Bucket) error {
+					values[string(name)] = make(map[string]string)
+					return b.ForEach(func(k, v []byte) error {
+						values[string(name)][string(k)] = string(v)
+						return nil
+					})
+				})
+			})
+			require.NoError(t, err)
+			require.Equal(t, testCase.ExpectedValues, values)
+		})
+	}
+}
+
+func TestPutValueWithExpectedETag(t *testing.T) {
+	testApp := NewTracker(t)
+
+	bucketName := []byte("bucket")
+
+	err := testApp.DB.Update(func(tx *bbolt.Tx) error {
+		bucket, err := tx.CreateBucket(bucketName)
+		if err != nil {
+			return err
+		}
+
+		return bucket.Put([]byte("key"), []byte("original"))
+	})
+	require.NoError(t, err)
+
+	path := []application.Key{
+		application.MustNewKey(bucketName),
+	}
+	key := application.MustNewKey([]byte("key"))
+
+	value, err := testApp.Application.GetValue.Execute(
+		application.GetValue{
+			Path: path,
+			Key:  key,
+		},
+	)
+	require.NoError(t, err)
+
+	etag := value.ETag()
+	require.NotEmpty(t, etag)
+
+	err = testApp.Application.PutValue.Execute(
+		application.PutValue{
+			Path:         path,
+			Key:          key,
+			Value:        application.MustNewValue([]byte("first")),
+			ExpectedETag: etag,
+		},
+	)
+	require.NoError(t, err)
+
+	err = testApp.Application.PutValue.Execute(
+		application.PutValue{
+			Path:         path,
+			Key:          key,
+			Value:        application.MustNewValue([]byte("second")),
+			ExpectedETag: etag,
+		},
+	)
+	require.ErrorIs(t, err, application.ErrValueChanged)
+
+	value, err = testApp.Application.GetValue.Execute(
+		application.GetValue{
+			Path: path,
+			Key:  key,
+		},
+	)
+	require.NoError(t, err)
+	require.Equal(t, []byte("first"), value.Bytes())
+	require.NotEqual(t, etag, value.ETag())
+
+	err = testApp.Application.PutValue.Execute(
+		application.PutValue{
+			Path:         path,
+			Key:          application.MustNewKey([]byte("missing")),
+			Value:        application.MustNewValue([]byte("value")),
+			ExpectedETag: etag,
+		},
+	)
+	require.ErrorIs(t, err, application.ErrValueChanged)
+}
+
+func TestWritesRejectLargeValues(t *testing.T) {
+	testApp := NewTracker(t)
+
+	bucketName := []byte("bucket")
+
+	err := testApp.DB.Update(func(tx *bbolt.Tx) error {
+		_, err := tx.CreateBucket(bucketName)
+		return err
+	})
+	require.NoError(t, err)
+
+	path := []application.Key{
+		application.MustNewKey(bucketName),
+	}
+	key := application.MustNewKey([]byte("key"))
+
+	allowed := application.MustNewValue(make([]byte, wire.TestMaxValueSize))
+	tooLarge := application.MustNewValue(make([]byte, wire.TestMaxValueSize+1))
+
+	err = testApp.Application.PutValue.Execute(
+		application.PutValue{
+			Path:  path,
+			Key:   key,
+			Value: allowed,
+		},
+	)
+	require.NoError(t, err)
+
+	err = testApp.Application.PutValue.Execute(
+		application.PutValue{
+			Path:  path,
+			Key:   key,
+			Value: tooLarge,
+		},
+	)
+	require.ErrorIs(t, err, application.ErrValueTooLarge)
+
+	_, err = testApp.Application.AppendValue.Execute(
+		application.AppendValue{
+			Path:  path,
+			Value: tooLarge,
+		},
+	)
+	require.ErrorIs(t, err, application.ErrValueTooLarge)
+
+	err = testApp.Application.BatchWrite.Execute(
+		application.BatchWrite{
+			Path: path,
+			Operations: []application.KeyOperation{
+				{Type: application.KeyOperationTypeDelete, Key: key},
+				{Type: application.KeyOperationTypePut, Key: key, Value: tooLarge},
+			},
+		},
+	)
+	require.ErrorIs(t, err, application.ErrValueTooLarge)
+
+	value, err := testApp.Application.GetValue.Execute(
+		application.GetValue{
+			Path: path,
+			Key:  key,
+		},
+	)
+	require.NoError(t, err)
+	require.Equal(t, allowed, value, "rejected batches are not applied")
+}
@@ -3,6 +3,7 @@ package wire
 import (
 	"github.com/contentforward/bolt-ui/adapters"
 	"github.com/contentforward/bolt-ui/application"
+	"github.com/contentforward/bolt-ui/internal/config"
 	"github.com/google/wire"
 	bolt "go.etcd.io/bbolt"
 )
@@ -10,7 +11,28 @@ import (
 //lint:ignore U1000 because
 var adaptersSet = wire.NewSet(
 	adapters.NewTransactionProvider,
-	wire.Bind(new(application.TransactionProvider), new(*adapters.TransactionProvider)),
+	newTransactionProvider,
+
+	newCompactor,
+	newBoltFileImporter,
+	newFileInspector,
+
+	adapters.NewPubSub,
+	wire.Bind(new(application.ChangeSubscriber), new(*adapters.PubSub)),
+
+	newCache,
+	wire.Bind(new(application.Cache), new(*adapters.Cache)),
+
+	newMaxValueSize,
+	newBucketTreeMaxDepth,
+
+	wire.Bind(new(application.AuditLog), new(*adapters.AuditLog)),
+
+	newChangePublisher,
+
+	newValueCompression,
+	adapters.NewImportDecoder,
+	wire.Bind(new(application.ImportDecoder), new(*adapters.ImportDecoder)),
 
 	newAdaptersProvider,
 	wire.Bind(new(adapters.AdaptersProvider), new(*adaptersProvider)),
@@ -18,9 +40,31 @@ var adaptersSet = wire.NewSet(
 
 //lint:ignore U1000 because
 var testAdaptersSet = wire.NewSet(
+	adapters.NewDatabaseFile,
+	wire.Bind(new(application.Compactor), new(*adapters.DatabaseFile)),
+	wire.Bind(new(application.BoltFileImporter), new(*adapters.DatabaseFile)),
+	wire.Bind(new(application.FileInspector), new(*adapters.DatabaseFile)),
+
 	adapters.NewTransactionProvider,
 	wire.Bind(new(application.TransactionProvider), new(*adapters.TransactionProvider)),
 
+	adapters.NewPubSub,
+	wire.Bind(new(application.ChangeSubscriber), new(*adapters.PubSub)),
+
+	newTestCache,
+	wire.Bind(new(application.Cache), new(*adapters.Cache)),
+
+	newTestMaxValueSize,
+	newTestBucketTreeMaxDepth,
+
+	newTestAuditLog,
+	wire.Bind(new(application.AuditLog), new(*adapters.AuditLog)),
+
+	newChangePublisher,
+
+	adapters.NewImportDecoder,
+	wire.Bind(new(application.ImportDecoder), new(*adapters.ImportDecoder)),
+
 	newTestAdaptersProvider,
 	wire.Bind(new(adapters.AdaptersProvider), new(*testAdaptersProvider)),
 )
@@ -37,19 +81,102 @@ var transactableAdaptersSet = wire.NewSet(
 var testTransactableAdaptersSet = wire.NewSet(
 	wire.Struct(new(application.TransactableAdapters), "*"),
 
+	newTestValueCompression,
 	adapters.NewDatabase,
 	wire.Bind(new(application.Database), new(*adapters.Database)),
 )
 
+func newTransactionProvider(conf *config.Config, provider *adapters.TransactionProvider) application.TransactionProvider {
+	if conf.ReadOnly || conf.OpenMode == config.OpenModeReadOnly {
+		return adapters.NewReadOnlyTransactionProvider(provider)
+	}
+	return provider
+}
+
+func newCompactor(conf *config.Config, file *adapters.DatabaseFile) application.Compactor {
+	if conf.ReadOnly || conf.OpenMode == config.OpenModeReadOnly {
+		return adapters.NewReadOnlyCompactor()
+	}
+	return file
+}
+
+func newBoltFileImporter(conf *config.Config, file *adapters.DatabaseFile) application.BoltFileImporter {
+	if conf.ReadOnly || conf.OpenMode == config.OpenModeReadOnly {
+		return adapters.NewReadOnlyBoltFileImporter()
+	}
+	return file
+}
+
+func newFileInspector(conf *config.Config, file *adapters.DatabaseFile) application.FileInspector {
+	if conf.ReadOnly {
+		return adapters.NewReadOnlyFileInspector(file)
+	}
+	return file
+}
+
+func newCache(conf *config.Config) *adapters.Cache {
+	return adapters.NewCache(conf.CacheTTL)
+}
+
+func newTestCache() *adapters.Cache {
+	return adapters.NewCache(0)
+}
+
+func newMaxValueSize(conf *config.Config) application.MaxValueSize {
+	return application.MaxValueSize(conf.MaxValueSize)
+}
+
+// TestMaxValueSize is the maximum size of the values written using the test
+// application.
+const TestMaxValueSize = 64 * 1024
+
+func newTestMaxValueSize() application.MaxValueSize {
+	return TestMaxValueSize
+}
+
+func newBucketTreeMaxDepth(conf *config.Config) application.BucketTreeMaxDepth {
+	return application.BucketTreeMaxDepth(conf.BucketTreeMaxDepth)
+}
+
+// TestBucketTreeMaxDepth is the maximum depth of the bucket trees returned by
+// the test application.
+const TestBucketTreeMaxDepth = 3
+
+func newTestBucketTreeMaxDepth() application.BucketTreeMaxDepth {
+	return TestBucketTreeMaxDepth
+}
+
+func newTestAuditLog() *adapters.AuditLog {
+	return adapters.NewAuditLog(nil, "")
+}
+
+// newChangePublisher invalidates the cache before notifying the subscribers
+// so that they don't receive stale data if they query the database after
+// receiving a change.
+func newChangePublisher(pubSub *adapters.PubSub, cache *adapters.Cache, auditLog *adapters.AuditLog) application.ChangePublisher {
+	return adapters.ChangePublishers{cache, auditLog, pubSub}
+}
+
+func newValueCompression(conf *config.Config) *adapters.ValueCompression {
+	return adapters.NewValueCompression(conf.ValueCompressionThreshold)
+}
+
+func newTestValueCompression() *adapters.ValueCompression {
+	return adapters.NewValueCompression(0)
+}
+
 type adaptersProvider struct {
+	compression *adapters.ValueCompression
 }
 
-func newAdaptersProvider() *adaptersProvider {
-	return &adaptersProvider{}
+func newAdaptersProvider(compression *adapters.ValueCompression) *adaptersProvider {
+	return &adaptersProvider{
+		compression: compression,
+	}
 }
 
 func (p *adaptersProvider) Provide(tx *bolt.Tx) (*application.TransactableAdapters, error) {
-	return BuildTransactableAdapters(tx)
+	return BuildTransactableAdapters(tx, p.compression)
 }
 
 type testAdaptersProvider struct {
@@ -9,4 +9,38 @@ import (
 var appSet = wire.NewSet(
 	wire.Struct(new(application.Application), "*"),
 	application.NewBrowseHandler,
+	application.NewResolvePathHandler,
+	application.NewListBucketsHandler,
+	application.NewListKeysHandler,
+	application.NewSearchKeysHandler,
+	application.NewCountKeysByPrefixHandler,
+	application.NewSearchAllBucketsHandler,
+	application.NewSearchValuesHandler,
+	application.NewGetValueHandler,
+	application.NewPutValueHandler,
+	application.NewAppendValueHandler,
+	application.NewDeleteKeyHandler,
+	application.NewRenameKeyHandler,
+	application.NewCopyKeyHandler,
+	application.NewCreateBucketHandler,
+	application.NewDeleteBucketHandler,
+	application.NewPreviewDeleteBucketHandler,
+	application.NewMoveBucketHandler,
+	application.NewCountBucketContentsHandler,
+	application.NewGetBucketStatsHandler,
+	application.NewGetBucketTreeHandler,
+	application.NewExportBucketHandler,
+	application.NewExportBucketCSVHandler,
+	application.NewImportBucketHandler,
+	application.NewImportBoltFileHandler,
+	application.NewBackupHandler,
+	application.NewCompactDatabaseHandler,
+	application.NewCheckHealthHandler,
+	application.NewCheckConsistencyHandler,
+	application.NewGetDatabaseStatsHandler,
+	application.NewGetFileInfoHandler,
+	application.NewSubscribeToChangesHandler,
+	application.NewStreamValueHandler,
+	application.NewBatchWriteHandler,
+	application.NewListAuditEntriesHandler,
 )
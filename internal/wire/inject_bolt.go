@@ -3,15 +3,21 @@ package wire
 import (
 	"github.com/contentforward/bolt-ui/adapters"
 	"github.com/contentforward/bolt-ui/internal/config"
-	"github.com/google/wire"
 	bolt "go.etcd.io/bbolt"
 )
 
-//lint:ignore U1000 because
-var boltSet = wire.NewSet(
-	newBolt,
-)
+func newBolt(conf *config.Config, databaseConf config.Database) (*bolt.DB, error) {
+	if conf.CreateIfMissing {
+		return adapters.CreateBolt(databaseConf.File, conf.OpenTimeout)
+	}
+	return adapters.NewBolt(databaseConf.File, conf.OpenMode == config.OpenModeReadOnly, conf.OpenTimeout)
+}
 
-func newBolt(conf *config.Config) (*bolt.DB, error) {
-	return adapters.NewBolt(conf.DatabaseFile)
+// newAuditLogBolt opens the audit log file, nil is returned if the audit log
+// is disabled.
+func newAuditLogBolt(conf *config.Config) (*bolt.DB, error) {
+	if conf.AuditLog == "" {
+		return nil, nil
+	}
+	return adapters.CreateBolt(conf.AuditLog, conf.OpenTimeout)
 }
@@ -0,0 +1,70 @@
+package wire
+
+import (
+	"github.com/boreq/errors"
+	"github.com/contentforward/bolt-ui/adapters"
+	"github.com/contentforward/bolt-ui/internal/config"
+	"github.com/contentforward/bolt-ui/internal/service"
+	"github.com/contentforward/bolt-ui/logging"
+	httpPort "github.com/contentforward/bolt-ui/ports/http"
+	"github.com/google/wire"
+)
+
+//lint:ignore U1000 because
+var databasesSet = wire.NewSet(
+	newDatabases,
+	wire.Bind(new(httpPort.Databases), new(*service.Databases)),
+)
+
+// newDatabases validates the config and opens all databases specified in it.
+// Databases which are invalid or can't be opened are skipped so that the
+// remaining ones can still be browsed.
+func newDatabases(conf *config.Config) (*service.Databases, error) {
+	log := logging.New("wire.newDatabases")
+
+	if err := conf.Validate(); err != nil {
+		return nil, errors.Wrap(err, "invalid config")
+	}
+
+	auditLogDB, err := newAuditLogBolt(conf)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not open the audit log")
+	}
+
+	var databases []service.Database
+
+	for _, databaseConf := range conf.Databases {
+		if err := conf.ValidateDatabase(databaseConf); err != nil {
+			log.Error("invalid database", "name", databaseConf.Name, "file", databaseConf.File, "err", err)
+			continue
+		}
+
+		db, err := newBolt(conf, databaseConf)
+		if err != nil {
+			log.Error("could not open the database", "name", databaseConf.Name, "file", databaseConf.File, "err", err)
+			continue
+		}
+
+		file := adapters.NewDatabaseFile(db)
+
+		app, err := BuildApplication(file, conf, adapters.NewAuditLog(auditLogDB, databaseConf.Name))
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not build the application for database '%s'", databaseConf.Name)
+		}
+
+		databases = append(databases, service.Database{
+			Name:        databaseConf.Name,
+			DB:          file,
+			Application: app,
+		})
+	}
+
+	if len(databases) == 0 {
+		if auditLogDB != nil {
+			auditLogDB.Close()
+		}
+		return nil, errors.New("none of the databases could be opened")
+	}
+
+	return service.NewDatabases(databases, auditLogDB), nil
+}
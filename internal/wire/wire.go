@@ -4,6 +4,7 @@
 package wire
 
 import (
+	"github.com/contentforward/bolt-ui/adapters"
 	"github.com/contentforward/bolt-ui/application"
 	"github.com/contentforward/bolt-ui/internal/config"
 	"github.com/contentforward/bolt-ui/internal/service"
@@ -11,7 +12,7 @@ import (
 	bolt "go.etcd.io/bbolt"
 )
 
-func BuildTransactableAdapters(_ *bolt.Tx) (*application.TransactableAdapters, error) {
+func BuildTransactableAdapters(_ *bolt.Tx, _ *adapters.ValueCompression) (*application.TransactableAdapters, error) {
 	wire.Build(
 		transactableAdaptersSet,
 	)
@@ -48,13 +49,20 @@ type TestApplication struct {
 type Mocks struct {
 }
 
+func BuildApplication(db *adapters.DatabaseFile, conf *config.Config, auditLog *adapters.AuditLog) (*application.Application, error) {
+	wire.Build(
+		appSet,
+		adaptersSet,
+	)
+
+	return nil, nil
+}
+
 func BuildService(conf *config.Config) (*service.Service, error) {
 	wire.Build(
 		service.NewService,
 		httpSet,
-		appSet,
-		boltSet,
-		adaptersSet,
+		databasesSet,
 	)
 
 	return nil, nil
@@ -16,8 +16,8 @@ import (
 
 // Injectors from wire.go:
 
-func BuildTransactableAdapters(tx *bbolt.Tx) (*application.TransactableAdapters, error) {
-	database := adapters.NewDatabase(tx)
+func BuildTransactableAdapters(tx *bbolt.Tx, valueCompression *adapters.ValueCompression) (*application.TransactableAdapters, error) {
+	database := adapters.NewDatabase(tx, valueCompression)
 	transactableAdapters := &application.TransactableAdapters{
 		Database: database,
 	}
@@ -25,7 +25,8 @@ func BuildTransactableAdapters(tx *bbolt.Tx) (*application.TransactableAdapters,
 }
 
 func BuildTestTransactableAdapters(tx *bbolt.Tx, mocks Mocks) (*application.TransactableAdapters, error) {
-	database := adapters.NewDatabase(tx)
+	valueCompression := newTestValueCompression()
+	database := adapters.NewDatabase(tx, valueCompression)
 	transactableAdapters := &application.TransactableAdapters{
 		Database: database,
 	}
@@ -35,10 +36,86 @@ func BuildTestTransactableAdapters(tx *bbolt.Tx, mocks Mocks) (*application.Tran
 func BuildApplicationForTest(db *bbolt.DB) (TestApplication, error) {
 	mocks := Mocks{}
 	wireTestAdaptersProvider := newTestAdaptersProvider(mocks)
-	transactionProvider := adapters.NewTransactionProvider(db, wireTestAdaptersProvider)
+	databaseFile := adapters.NewDatabaseFile(db)
+	transactionProvider := adapters.NewTransactionProvider(databaseFile, wireTestAdaptersProvider)
+	pubSub := adapters.NewPubSub()
+	cache := newTestCache()
+	maxValueSize := newTestMaxValueSize()
+	bucketTreeMaxDepth := newTestBucketTreeMaxDepth()
+	auditLog := newTestAuditLog()
+	changePublisher := newChangePublisher(pubSub, cache, auditLog)
+	importDecoder := adapters.NewImportDecoder()
 	browseHandler := application.NewBrowseHandler(transactionProvider)
+	resolvePathHandler := application.NewResolvePathHandler(transactionProvider)
+	listBucketsHandler := application.NewListBucketsHandler(transactionProvider, cache)
+	listKeysHandler := application.NewListKeysHandler(transactionProvider)
+	searchKeysHandler := application.NewSearchKeysHandler(transactionProvider)
+	countKeysByPrefixHandler := application.NewCountKeysByPrefixHandler(transactionProvider)
+	searchAllBucketsHandler := application.NewSearchAllBucketsHandler(transactionProvider)
+	searchValuesHandler := application.NewSearchValuesHandler(transactionProvider)
+	getValueHandler := application.NewGetValueHandler(transactionProvider)
+	putValueHandler := application.NewPutValueHandler(transactionProvider, changePublisher, maxValueSize)
+	appendValueHandler := application.NewAppendValueHandler(transactionProvider, changePublisher, maxValueSize)
+	deleteKeyHandler := application.NewDeleteKeyHandler(transactionProvider, changePublisher)
+	renameKeyHandler := application.NewRenameKeyHandler(transactionProvider, changePublisher)
+	copyKeyHandler := application.NewCopyKeyHandler(transactionProvider, changePublisher)
+	createBucketHandler := application.NewCreateBucketHandler(transactionProvider, changePublisher)
+	deleteBucketHandler := application.NewDeleteBucketHandler(transactionProvider, changePublisher)
+	previewDeleteBucketHandler := application.NewPreviewDeleteBucketHandler(transactionProvider)
+	moveBucketHandler := application.NewMoveBucketHandler(transactionProvider, changePublisher)
+	countBucketContentsHandler := application.NewCountBucketContentsHandler(transactionProvider)
+	getBucketStatsHandler := application.NewGetBucketStatsHandler(transactionProvider, cache)
+	getBucketTreeHandler := application.NewGetBucketTreeHandler(transactionProvider, bucketTreeMaxDepth)
+	exportBucketHandler := application.NewExportBucketHandler(transactionProvider)
+	exportBucketCSVHandler := application.NewExportBucketCSVHandler(transactionProvider)
+	importBucketHandler := application.NewImportBucketHandler(transactionProvider, changePublisher, importDecoder)
+	importBoltFileHandler := application.NewImportBoltFileHandler(databaseFile, changePublisher)
+	backupHandler := application.NewBackupHandler(transactionProvider)
+	compactDatabaseHandler := application.NewCompactDatabaseHandler(databaseFile)
+	checkHealthHandler := application.NewCheckHealthHandler(transactionProvider)
+	checkConsistencyHandler := application.NewCheckConsistencyHandler(transactionProvider)
+	getDatabaseStatsHandler := application.NewGetDatabaseStatsHandler(transactionProvider)
+	getFileInfoHandler := application.NewGetFileInfoHandler(databaseFile)
+	subscribeToChangesHandler := application.NewSubscribeToChangesHandler(pubSub)
+	streamValueHandler := application.NewStreamValueHandler(transactionProvider)
+	batchWriteHandler := application.NewBatchWriteHandler(transactionProvider, changePublisher, maxValueSize)
+	listAuditEntriesHandler := application.NewListAuditEntriesHandler(auditLog)
 	applicationApplication := &application.Application{
-		Browse: browseHandler,
+		Browse:              browseHandler,
+		ResolvePath:         resolvePathHandler,
+		ListBuckets:         listBucketsHandler,
+		ListKeys:            listKeysHandler,
+		SearchKeys:          searchKeysHandler,
+		CountKeysByPrefix:   countKeysByPrefixHandler,
+		SearchAllBuckets:    searchAllBucketsHandler,
+		SearchValues:        searchValuesHandler,
+		GetValue:            getValueHandler,
+		PutValue:            putValueHandler,
+		AppendValue:         appendValueHandler,
+		DeleteKey:           deleteKeyHandler,
+		RenameKey:           renameKeyHandler,
+		CopyKey:             copyKeyHandler,
+		CreateBucket:        createBucketHandler,
+		DeleteBucket:        deleteBucketHandler,
+		PreviewDeleteBucket: previewDeleteBucketHandler,
+		MoveBucket:          moveBucketHandler,
+		CountBucketContents: countBucketContentsHandler,
+		GetBucketStats:      getBucketStatsHandler,
+		GetBucketTree:       getBucketTreeHandler,
+		ExportBucket:        exportBucketHandler,
+		ExportBucketCSV:     exportBucketCSVHandler,
+		ImportBucket:        importBucketHandler,
+		ImportBoltFile:      importBoltFileHandler,
+		Backup:              backupHandler,
+		CompactDatabase:     compactDatabaseHandler,
+		CheckHealth:         checkHealthHandler,
+		CheckConsistency:    checkConsistencyHandler,
+		GetDatabaseStats:    getDatabaseStatsHandler,
+		GetFileInfo:         getFileInfoHandler,
+		SubscribeToChanges:  subscribeToChangesHandler,
+		StreamValue:         streamValueHandler,
+		BatchWrite:          batchWriteHandler,
+		ListAuditEntries:    listAuditEntriesHandler,
 	}
 	testApplication := TestApplication{
 		Application: applicationApplication,
@@ -48,24 +125,107 @@ func BuildApplicationForTest(db *bbolt.DB) (TestApplication, error) {
 	return testApplication, nil
 }
 
+func BuildApplication(db *adapters.DatabaseFile, conf *config.Config, auditLog *adapters.AuditLog) (*application.Application, error) {
+	valueCompression := newValueCompression(conf)
+	wireAdaptersProvider := newAdaptersProvider(valueCompression)
+	adaptersTransactionProvider := adapters.NewTransactionProvider(db, wireAdaptersProvider)
+	transactionProvider := newTransactionProvider(conf, adaptersTransactionProvider)
+	pubSub := adapters.NewPubSub()
+	cache := newCache(conf)
+	maxValueSize := newMaxValueSize(conf)
+	bucketTreeMaxDepth := newBucketTreeMaxDepth(conf)
+	changePublisher := newChangePublisher(pubSub, cache, auditLog)
+	importDecoder := adapters.NewImportDecoder()
+	browseHandler := application.NewBrowseHandler(transactionProvider)
+	resolvePathHandler := application.NewResolvePathHandler(transactionProvider)
+	listBucketsHandler := application.NewListBucketsHandler(transactionProvider, cache)
+	listKeysHandler := application.NewListKeysHandler(transactionProvider)
+	searchKeysHandler := application.NewSearchKeysHandler(transactionProvider)
+	countKeysByPrefixHandler := application.NewCountKeysByPrefixHandler(transactionProvider)
+	searchAllBucketsHandler := application.NewSearchAllBucketsHandler(transactionProvider)
+	searchValuesHandler := application.NewSearchValuesHandler(transactionProvider)
+	getValueHandler := application.NewGetValueHandler(transactionProvider)
+	putValueHandler := application.NewPutValueHandler(transactionProvider, changePublisher, maxValueSize)
+	appendValueHandler := application.NewAppendValueHandler(transactionProvider, changePublisher, maxValueSize)
+	deleteKeyHandler := application.NewDeleteKeyHandler(transactionProvider, changePublisher)
+	renameKeyHandler := application.NewRenameKeyHandler(transactionProvider, changePublisher)
+	copyKeyHandler := application.NewCopyKeyHandler(transactionProvider, changePublisher)
+	createBucketHandler := application.NewCreateBucketHandler(transactionProvider, changePublisher)
+	deleteBucketHandler := application.NewDeleteBucketHandler(transactionProvider, changePublisher)
+	previewDeleteBucketHandler := application.NewPreviewDeleteBucketHandler(transactionProvider)
+	moveBucketHandler := application.NewMoveBucketHandler(transactionProvider, changePublisher)
+	countBucketContentsHandler := application.NewCountBucketContentsHandler(transactionProvider)
+	getBucketStatsHandler := application.NewGetBucketStatsHandler(transactionProvider, cache)
+	getBucketTreeHandler := application.NewGetBucketTreeHandler(transactionProvider, bucketTreeMaxDepth)
+	exportBucketHandler := application.NewExportBucketHandler(transactionProvider)
+	exportBucketCSVHandler := application.NewExportBucketCSVHandler(transactionProvider)
+	importBucketHandler := application.NewImportBucketHandler(transactionProvider, changePublisher, importDecoder)
+	boltFileImporter := newBoltFileImporter(conf, db)
+	importBoltFileHandler := application.NewImportBoltFileHandler(boltFileImporter, changePublisher)
+	backupHandler := application.NewBackupHandler(transactionProvider)
+	compactor := newCompactor(conf, db)
+	compactDatabaseHandler := application.NewCompactDatabaseHandler(compactor)
+	checkHealthHandler := application.NewCheckHealthHandler(transactionProvider)
+	checkConsistencyHandler := application.NewCheckConsistencyHandler(transactionProvider)
+	getDatabaseStatsHandler := application.NewGetDatabaseStatsHandler(transactionProvider)
+	fileInspector := newFileInspector(conf, db)
+	getFileInfoHandler := application.NewGetFileInfoHandler(fileInspector)
+	subscribeToChangesHandler := application.NewSubscribeToChangesHandler(pubSub)
+	streamValueHandler := application.NewStreamValueHandler(transactionProvider)
+	batchWriteHandler := application.NewBatchWriteHandler(transactionProvider, changePublisher, maxValueSize)
+	listAuditEntriesHandler := application.NewListAuditEntriesHandler(auditLog)
+	applicationApplication := &application.Application{
+		Browse:              browseHandler,
+		ResolvePath:         resolvePathHandler,
+		ListBuckets:         listBucketsHandler,
+		ListKeys:            listKeysHandler,
+		SearchKeys:          searchKeysHandler,
+		CountKeysByPrefix:   countKeysByPrefixHandler,
+		SearchAllBuckets:    searchAllBucketsHandler,
+		SearchValues:        searchValuesHandler,
+		GetValue:            getValueHandler,
+		PutValue:            putValueHandler,
+		AppendValue:         appendValueHandler,
+		DeleteKey:           deleteKeyHandler,
+		RenameKey:           renameKeyHandler,
+		CopyKey:             copyKeyHandler,
+		CreateBucket:        createBucketHandler,
+		DeleteBucket:        deleteBucketHandler,
+		PreviewDeleteBucket: previewDeleteBucketHandler,
+		MoveBucket:          moveBucketHandler,
+		CountBucketContents: countBucketContentsHandler,
+		GetBucketStats:      getBucketStatsHandler,
+		GetBucketTree:       getBucketTreeHandler,
+		ExportBucket:        exportBucketHandler,
+		ExportBucketCSV:     exportBucketCSVHandler,
+		ImportBucket:        importBucketHandler,
+		ImportBoltFile:      importBoltFileHandler,
+		Backup:              backupHandler,
+		CompactDatabase:     compactDatabaseHandler,
+		CheckHealth:         checkHealthHandler,
+		CheckConsistency:    checkConsistencyHandler,
+		GetDatabaseStats:    getDatabaseStatsHandler,
+		GetFileInfo:         getFileInfoHandler,
+		SubscribeToChanges:  subscribeToChangesHandler,
+		StreamValue:         streamValueHandler,
+		BatchWrite:          batchWriteHandler,
+		ListAuditEntries:    listAuditEntriesHandler,
+	}
+	return applicationApplication, nil
+}
+
 func BuildService(conf *config.Config) (*service.Service, error) {
-	db, err := newBolt(conf)
+	databases, err := newDatabases(conf)
 	if err != nil {
 		return nil, err
 	}
-	wireAdaptersProvider := newAdaptersProvider()
-	transactionProvider := adapters.NewTransactionProvider(db, wireAdaptersProvider)
-	browseHandler := application.NewBrowseHandler(transactionProvider)
-	applicationApplication := &application.Application{
-		Browse: browseHandler,
-	}
 	tokenAuthProvider := http.NewTokenAuthProvider(conf)
-	handler, err := http.NewHandler(applicationApplication, tokenAuthProvider)
+	handler, err := http.NewHandler(databases, tokenAuthProvider, conf)
 	if err != nil {
 		return nil, err
 	}
 	server := http.NewServer(handler, conf)
-	serviceService := service.NewService(server)
+	serviceService := service.NewService(server, databases)
 	return serviceService, nil
 }
 
@@ -9,6 +9,14 @@ type Logger = log15.Logger
 
 type Level = log15.Lvl
 
+const (
+	LevelCrit  = log15.LvlCrit
+	LevelError = log15.LvlError
+	LevelWarn  = log15.LvlWarn
+	LevelInfo  = log15.LvlInfo
+	LevelDebug = log15.LvlDebug
+)
+
 var maxLevel *Level
 
 func init() {
@@ -0,0 +1,77 @@
+package http
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/contentforward/bolt-ui/logging"
+)
+
+// maxLoggedBodySize limits the size of request bodies which are logged.
+const maxLoggedBodySize = 4096
+
+// accessLog logs every request. Successful requests are logged using the
+// provided level, client errors as warnings and server errors as errors. The
+// query string and headers are never logged as they may contain the token.
+func accessLog(handler http.Handler, log logging.Logger, level logging.Level, logBodies bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := newStatusRecorder(w)
+
+		var body []byte
+		if logBodies && r.Body != nil {
+			b, err := ioutil.ReadAll(io.LimitReader(r.Body, maxLoggedBodySize))
+			if err != nil {
+				log.Warn("could not read the request body", "err", err)
+			}
+			body = b
+			r.Body = readCloser{io.MultiReader(bytes.NewReader(b), r.Body), r.Body}
+		}
+
+		handler.ServeHTTP(recorder, r)
+
+		ctx := []interface{}{
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", recorder.Status(),
+			"duration", time.Since(start),
+			"remoteAddr", r.RemoteAddr,
+		}
+
+		if logBodies {
+			ctx = append(ctx, "body", string(body))
+		}
+
+		switch status := recorder.Status(); {
+		case status >= 500:
+			log.Error("request", ctx...)
+		case status >= 400:
+			log.Warn("request", ctx...)
+		default:
+			logAtLevel(log, level, "request", ctx...)
+		}
+	})
+}
+
+func logAtLevel(log logging.Logger, level logging.Level, msg string, ctx ...interface{}) {
+	switch level {
+	case logging.LevelCrit:
+		log.Crit(msg, ctx...)
+	case logging.LevelError:
+		log.Error(msg, ctx...)
+	case logging.LevelWarn:
+		log.Warn(msg, ctx...)
+	case logging.LevelInfo:
+		log.Info(msg, ctx...)
+	default:
+		log.Debug(msg, ctx...)
+	}
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
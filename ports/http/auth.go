@@ -1,39 +1,99 @@
 package http
 
 import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
 	"errors"
+	"fmt"
 	"net/http"
+	"sync"
 
 	"github.com/contentforward/bolt-ui/internal/config"
 )
 
+// errTokenDisabled is returned when rotating the token if token validation is
+// disabled.
+var errTokenDisabled = errors.New("token validation is disabled")
+
 type AuthProvider interface {
 	Check(r *http.Request) (bool, error)
+
+	// Rotate replaces the token with a newly generated one and returns it.
+	// The previous token is no longer accepted once Rotate returns.
+	Rotate() (string, error)
 }
 
 type TokenAuthProvider struct {
 	conf *config.Config
+
+	mutex sync.RWMutex
+	token string
 }
 
 func NewTokenAuthProvider(conf *config.Config) *TokenAuthProvider {
 	return &TokenAuthProvider{
-		conf: conf,
+		conf:  conf,
+		token: conf.Token,
 	}
 }
 
+// Check accepts the token passed in the Access-Token header. If basic auth is
+// enabled the token can also be passed as the basic auth password, the user
+// name is ignored.
 func (h *TokenAuthProvider) Check(r *http.Request) (bool, error) {
 	if h.conf.InsecureToken {
 		return true, nil
 	}
 
-	if h.conf.Token == "" {
+	h.mutex.RLock()
+	expected := h.token
+	h.mutex.RUnlock()
+
+	if expected == "" {
 		return false, errors.New("auth token is not set in the config")
 	}
 
 	token := r.Header.Get("Access-Token")
-	if token != h.conf.Token {
+	if token == "" && h.conf.BasicAuth {
+		if _, password, ok := r.BasicAuth(); ok {
+			token = password
+		}
+	}
+
+	if subtle.ConstantTimeCompare([]byte(token), []byte(expected)) != 1 {
 		return false, nil
 	}
 
 	return true, nil
 }
+
+// Rotate returns errTokenDisabled if token validation is disabled.
+func (h *TokenAuthProvider) Rotate() (string, error) {
+	if h.conf.InsecureToken {
+		return "", errTokenDisabled
+	}
+
+	token, err := GenerateToken()
+	if err != nil {
+		return "", err
+	}
+
+	h.mutex.Lock()
+	h.token = token
+	h.mutex.Unlock()
+
+	return token, nil
+}
+
+const tokenLength = 32
+
+// GenerateToken returns a random token which can be used to access the
+// program.
+func GenerateToken() (string, error) {
+	b := make([]byte, tokenLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to read random bytes: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
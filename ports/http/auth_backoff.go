@@ -0,0 +1,119 @@
+package http
+
+import (
+	"sync"
+	"time"
+)
+
+// authBackoffCleanupInterval specifies how often the failures of the clients
+// which stopped making requests are forgotten.
+const authBackoffCleanupInterval = time.Minute
+
+// authBackoff slows down the clients which repeatedly fail to authenticate.
+// After each consecutive failure the client has to wait twice as long before
+// its credentials are checked again, starting with base and up to max. The
+// failures are forgotten once the client authenticates successfully or stops
+// making requests for max after its last wait ended. A nil authBackoff is
+// disabled.
+type authBackoff struct {
+	base time.Duration
+	max  time.Duration
+
+	mutex       sync.Mutex
+	clients     map[string]*authFailures
+	lastCleanup time.Time
+}
+
+type authFailures struct {
+	n     int
+	until time.Time
+}
+
+func newAuthBackoff(base, max time.Duration) *authBackoff {
+	if base <= 0 {
+		return nil
+	}
+
+	return &authBackoff{
+		base:        base,
+		max:         max,
+		clients:     make(map[string]*authFailures),
+		lastCleanup: time.Now(),
+	}
+}
+
+// Wait returns for how long the client has to wait before its credentials can
+// be checked, zero if they can be checked now.
+func (b *authBackoff) Wait(client string, now time.Time) time.Duration {
+	if b == nil {
+		return 0
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if now.Sub(b.lastCleanup) > authBackoffCleanupInterval {
+		b.cleanup(now)
+		b.lastCleanup = now
+	}
+
+	failures, ok := b.clients[client]
+	if !ok || !now.Before(failures.until) {
+		return 0
+	}
+	return failures.until.Sub(now)
+}
+
+// Failure records a failed authentication attempt and returns for how long
+// the client has to wait before its next attempt.
+func (b *authBackoff) Failure(client string, now time.Time) time.Duration {
+	if b == nil {
+		return 0
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	failures, ok := b.clients[client]
+	if !ok {
+		failures = &authFailures{}
+		b.clients[client] = failures
+	}
+
+	failures.n++
+	delay := b.delay(failures.n)
+	failures.until = now.Add(delay)
+	return delay
+}
+
+// Success forgets the failures of the client.
+func (b *authBackoff) Success(client string) {
+	if b == nil {
+		return
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	delete(b.clients, client)
+}
+
+func (b *authBackoff) delay(n int) time.Duration {
+	delay := b.base
+	for i := 1; i < n && delay < b.max; i++ {
+		delay *= 2
+	}
+
+	if delay > b.max {
+		return b.max
+	}
+	return delay
+}
+
+func (b *authBackoff) cleanup(now time.Time) {
+	for client, failures := range b.clients {
+		if now.Sub(failures.until) > b.max {
+			delete(b.clients, client)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+)
+
+// withBasePath serves the handler under the base path. Requests for the root
+// or for the base path without the trailing slash are redirected to the
+// frontend, other requests outside of the base path receive 404.
+func withBasePath(handler http.Handler, basePath string) http.Handler {
+	if basePath == "" {
+		return handler
+	}
+
+	stripped := http.StripPrefix(basePath, handler)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasPrefix(r.URL.Path, basePath+"/"):
+			stripped.ServeHTTP(w, r)
+		case r.URL.Path == "/" || r.URL.Path == basePath:
+			target := basePath + "/"
+			if r.URL.RawQuery != "" {
+				target += "?" + r.URL.RawQuery
+			}
+			http.Redirect(w, r, target, http.StatusFound)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
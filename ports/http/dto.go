@@ -1,10 +1,14 @@
 package http
 
 import (
+	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
+	"path/filepath"
+	"time"
 	"unicode"
 
+	"github.com/boreq/errors"
 	"github.com/contentforward/bolt-ui/application"
 )
 
@@ -13,26 +17,287 @@ type Tree struct {
 	Entries []Entry `json:"entries"`
 }
 
+// Key contains the key encoded using each of the encodings accepted by the
+// API. Base64 uses the URL-safe alphabet without padding. Str is set only if
+// the key can be displayed as a string.
 type Key struct {
-	Hex string `json:"hex"`
-	Str string `json:"str,omitempty"`
+	Hex    string `json:"hex"`
+	Base64 string `json:"base64"`
+	Str    string `json:"str,omitempty"`
+	Uint64 string `json:"uint64,omitempty"`
+}
+
+type PathInfo struct {
+	Segments []PathSegment `json:"segments"`
+	Resolved bool          `json:"resolved"`
+}
+
+type PathSegment struct {
+	Key    Key  `json:"key"`
+	Exists bool `json:"exists"`
+	Bucket bool `json:"bucket"`
 }
 
 type Value struct {
-	Hex string `json:"hex"`
-	Str string `json:"str,omitempty"`
+	Hex    string `json:"hex"`
+	Str    string `json:"str,omitempty"`
+	Type   string `json:"type"`
+	Uint64 string `json:"uint64,omitempty"`
 }
 
+const (
+	valueTypeBinary = "binary"
+	valueTypeText   = "text"
+	valueTypeJSON   = "json"
+)
+
 type Entry struct {
 	Bucket bool   `json:"bucket"`
 	Key    Key    `json:"key"`
 	Value  *Value `json:"value,omitempty"`
 }
 
-func toTree(tree application.Tree) Tree {
+type Change struct {
+	Path      []Key  `json:"path"`
+	Key       *Key   `json:"key,omitempty"`
+	Operation string `json:"operation"`
+}
+
+// BatchWrite is sent by the clients, keys are encoded using the encoding
+// selected by the request and values are hex encoded.
+type BatchWrite struct {
+	Operations []BatchWriteOperation `json:"operations"`
+}
+
+type BatchWriteOperation struct {
+	Type  string `json:"type"`
+	Key   string `json:"key"`
+	Value string `json:"value,omitempty"`
+}
+
+// RenameKey is sent by the clients, the new key is encoded using the encoding
+// selected by the request.
+type RenameKey struct {
+	NewKey    string `json:"newKey"`
+	Overwrite bool   `json:"overwrite"`
+}
+
+// CopyKey is sent by the clients, the destination path and key are encoded
+// using the encoding selected by the request.
+type CopyKey struct {
+	Path      []string `json:"path"`
+	Key       string   `json:"key"`
+	Move      bool     `json:"move"`
+	Overwrite bool     `json:"overwrite"`
+}
+
+// MoveBucket is sent by the clients, the parent path and the name are encoded
+// using the encoding selected by the request.
+type MoveBucket struct {
+	Parent []string `json:"parent"`
+	Name   string   `json:"name"`
+}
+
+// AppendedValue contains the generated key. The sequence number used to
+// generate it is available as the uint64 interpretation of the key.
+type AppendedValue struct {
+	Key Key `json:"key"`
+}
+
+type KeysPage struct {
+	Keys  []KeyInfo `json:"keys"`
+	Next  *Key      `json:"next,omitempty"`
+	Total *int      `json:"total,omitempty"`
+}
+
+type SearchHit struct {
+	Path    []Key  `json:"path"`
+	Bucket  bool   `json:"bucket"`
+	Key     Key    `json:"key"`
+	Snippet string `json:"snippet,omitempty"`
+}
+
+type AuditEntry struct {
+	ID        uint64    `json:"id"`
+	Time      time.Time `json:"time"`
+	Operation string    `json:"operation"`
+	Path      []Key     `json:"path"`
+	Key       *Key      `json:"key,omitempty"`
+}
+
+type KeyInfo struct {
+	Bucket bool `json:"bucket"`
+	Key    Key  `json:"key"`
+	Size   *int `json:"size,omitempty"`
+}
+
+type BucketContents struct {
+	Values  int `json:"values"`
+	Buckets int `json:"buckets"`
+}
+
+type KeyCount struct {
+	Count int `json:"count"`
+}
+
+type DeleteBucketPreview struct {
+	Contents     BucketContents `json:"contents"`
+	Sample       []KeyInfo      `json:"sample"`
+	Confirmation string         `json:"confirmation"`
+}
+
+type BucketTreeNode struct {
+	Name      Key              `json:"name"`
+	KeyN      int              `json:"keyN"`
+	Truncated bool             `json:"truncated"`
+	Buckets   []BucketTreeNode `json:"buckets"`
+}
+
+type ImportSummary struct {
+	Created     int      `json:"created"`
+	Overwritten int      `json:"overwritten"`
+	Errors      []string `json:"errors"`
+}
+
+type CompactionResult struct {
+	SizeBefore int64 `json:"sizeBefore"`
+	SizeAfter  int64 `json:"sizeAfter"`
+}
+
+type FileInfo struct {
+	Path     string    `json:"path"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+	ReadOnly bool      `json:"readOnly"`
+}
+
+type Token struct {
+	Token string `json:"token"`
+}
+
+type Health struct {
+	Status string `json:"status"`
+}
+
+type ConsistencyCheck struct {
+	Problems []string `json:"problems"`
+}
+
+type BucketStats struct {
+	BranchPageN       int `json:"branchPageN"`
+	BranchOverflowN   int `json:"branchOverflowN"`
+	LeafPageN         int `json:"leafPageN"`
+	LeafOverflowN     int `json:"leafOverflowN"`
+	KeyN              int `json:"keyN"`
+	Depth             int `json:"depth"`
+	BranchAlloc       int `json:"branchAlloc"`
+	BranchInuse       int `json:"branchInuse"`
+	LeafAlloc         int `json:"leafAlloc"`
+	LeafInuse         int `json:"leafInuse"`
+	BucketN           int `json:"bucketN"`
+	InlineBucketN     int `json:"inlineBucketN"`
+	InlineBucketInuse int `json:"inlineBucketInuse"`
+}
+
+func toTree(tree application.Tree, i interpretation) Tree {
 	return Tree{
 		toKeys(tree.Path),
-		toEntries(tree.Entries),
+		toEntries(tree.Entries, i),
+	}
+}
+
+func toKeysPage(page application.KeysPage, i interpretation) KeysPage {
+	result := KeysPage{
+		Keys:  toKeyInfos(page.Keys, i),
+		Total: page.Total,
+	}
+
+	if page.Next != nil {
+		next := i.key(*page.Next)
+		result.Next = &next
+	}
+
+	return result
+}
+
+func toAppendedValue(appended application.AppendedValue) AppendedValue {
+	return AppendedValue{
+		Key: interpretation{KeysAsUint64: true}.key(appended.Key),
+	}
+}
+
+func toKeyInfos(keys []application.KeyInfo, i interpretation) []KeyInfo {
+	result := make([]KeyInfo, 0)
+	for _, keyInfo := range keys {
+		result = append(result, toKeyInfo(keyInfo, i))
+	}
+	return result
+}
+
+func toKeyInfo(keyInfo application.KeyInfo, i interpretation) KeyInfo {
+	return KeyInfo{
+		Bucket: keyInfo.Bucket,
+		Key:    i.key(keyInfo.Key),
+		Size:   keyInfo.Size,
+	}
+}
+
+func toBucketTreeNodes(nodes []application.BucketTreeNode) []BucketTreeNode {
+	result := make([]BucketTreeNode, 0)
+	for _, node := range nodes {
+		result = append(result, BucketTreeNode{
+			Name:      toKey(node.Name),
+			KeyN:      node.KeyN,
+			Truncated: node.Truncated,
+			Buckets:   toBucketTreeNodes(node.Buckets),
+		})
+	}
+	return result
+}
+
+func toImportSummary(summary application.ImportSummary) ImportSummary {
+	problems := summary.Errors
+	if problems == nil {
+		problems = []string{}
+	}
+
+	return ImportSummary{
+		Created:     summary.Created,
+		Overwritten: summary.Overwritten,
+		Errors:      problems,
+	}
+}
+
+func toBucketContents(contents application.BucketContents) BucketContents {
+	return BucketContents{
+		Values:  contents.Values,
+		Buckets: contents.Buckets,
+	}
+}
+
+func toDeleteBucketPreview(preview application.DeleteBucketPreview, i interpretation) DeleteBucketPreview {
+	return DeleteBucketPreview{
+		Contents:     toBucketContents(preview.Contents),
+		Sample:       toKeyInfos(preview.Sample, i),
+		Confirmation: preview.Confirmation,
+	}
+}
+
+func toBucketStats(stats application.BucketStats) BucketStats {
+	return BucketStats{
+		BranchPageN:       stats.BranchPageN,
+		BranchOverflowN:   stats.BranchOverflowN,
+		LeafPageN:         stats.LeafPageN,
+		LeafOverflowN:     stats.LeafOverflowN,
+		KeyN:              stats.KeyN,
+		Depth:             stats.Depth,
+		BranchAlloc:       stats.BranchAlloc,
+		BranchInuse:       stats.BranchInuse,
+		LeafAlloc:         stats.LeafAlloc,
+		LeafInuse:         stats.LeafInuse,
+		BucketN:           stats.BucketN,
+		InlineBucketN:     stats.InlineBucketN,
+		InlineBucketInuse: stats.InlineBucketInuse,
 	}
 }
 
@@ -44,19 +309,49 @@ func toKeys(keys []application.Key) []Key {
 	return result
 }
 
-func toEntries(entries []application.Entry) []Entry {
+func toEntries(entries []application.Entry, i interpretation) []Entry {
 	result := make([]Entry, 0)
 	for _, entry := range entries {
-		result = append(result, toEntry(entry))
+		result = append(result, toEntry(entry, i))
 	}
 	return result
 }
 
-func toEntry(entry application.Entry) Entry {
+func toEntry(entry application.Entry, i interpretation) Entry {
 	return Entry{
 		Bucket: entry.Bucket,
-		Key:    toKey(entry.Key),
-		Value:  toValue(entry.Value),
+		Key:    i.key(entry.Key),
+		Value:  toValue(entry.Value, i),
+	}
+}
+
+func toChange(change application.Change) Change {
+	result := Change{
+		Path:      toKeys(change.Path),
+		Operation: string(change.Operation),
+	}
+
+	if change.Key != nil {
+		key := toKey(*change.Key)
+		result.Key = &key
+	}
+
+	return result
+}
+
+func toPathInfo(info application.PathInfo) PathInfo {
+	segments := make([]PathSegment, 0, len(info.Segments))
+	for _, segment := range info.Segments {
+		segments = append(segments, PathSegment{
+			Key:    toKey(segment.Key),
+			Exists: segment.Exists,
+			Bucket: segment.Bucket,
+		})
+	}
+
+	return PathInfo{
+		Segments: segments,
+		Resolved: info.Resolved(),
 	}
 }
 
@@ -64,7 +359,8 @@ func toKey(key application.Key) Key {
 	b := key.Bytes()
 
 	result := Key{
-		Hex: hex.EncodeToString(b),
+		Hex:    hex.EncodeToString(b),
+		Base64: base64.RawURLEncoding.EncodeToString(b),
 	}
 
 	if canDisplayAsString(b) {
@@ -74,15 +370,21 @@ func toKey(key application.Key) Key {
 	return result
 }
 
-func toValue(value application.Value) *Value {
+func toValue(value application.Value, i interpretation) *Value {
 	if value.IsEmpty() {
 		return nil
 	}
 
+	result := i.value(value)
+	return &result
+}
+
+func toValueNotNil(value application.Value) Value {
 	b := value.Bytes()
 
-	result := &Value{
-		Hex: hex.EncodeToString(b),
+	result := Value{
+		Hex:  hex.EncodeToString(b),
+		Type: toValueType(application.DetectValueType(b)),
 	}
 
 	if canDisplayAsString(b) {
@@ -92,6 +394,17 @@ func toValue(value application.Value) *Value {
 	return result
 }
 
+func toValueType(valueType application.ValueType) string {
+	switch valueType {
+	case application.ValueTypeJSON:
+		return valueTypeJSON
+	case application.ValueTypeText:
+		return valueTypeText
+	default:
+		return valueTypeBinary
+	}
+}
+
 func canDisplayAsString(b []byte) bool {
 	if json.Valid(b) {
 		return true
@@ -105,3 +418,152 @@ func canDisplayAsString(b []byte) bool {
 
 	return true
 }
+
+func fromBatchWrite(batchWrite BatchWrite, encoding keyEncoding) ([]application.KeyOperation, error) {
+	var operations []application.KeyOperation
+
+	for i, o := range batchWrite.Operations {
+		key, err := fromKey(o.Key, encoding)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid key of operation %d", i)
+		}
+
+		b, err := hex.DecodeString(o.Value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not decode the value of operation %d", i)
+		}
+
+		value, err := application.NewValue(b)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid value of operation %d", i)
+		}
+
+		operations = append(operations, application.KeyOperation{
+			Type:  application.KeyOperationType(o.Type),
+			Key:   key,
+			Value: value,
+		})
+	}
+
+	return operations, nil
+}
+
+func toSearchHits(hits []application.SearchHit) []SearchHit {
+	result := make([]SearchHit, 0, len(hits))
+	for _, hit := range hits {
+		result = append(result, toSearchHit(hit))
+	}
+	return result
+}
+
+func toSearchHit(hit application.SearchHit) SearchHit {
+	return SearchHit{
+		Path:    toKeys(hit.Path),
+		Bucket:  hit.Bucket,
+		Key:     toKey(hit.Key),
+		Snippet: hit.Snippet,
+	}
+}
+
+func toCompactionResult(result application.CompactionResult) CompactionResult {
+	return CompactionResult{
+		SizeBefore: result.SizeBefore,
+		SizeAfter:  result.SizeAfter,
+	}
+}
+
+// toFileInfo returns only the base name of the file if redactPath is set.
+func toFileInfo(info application.FileInfo, redactPath bool) FileInfo {
+	path := info.Path
+	if redactPath {
+		path = filepath.Base(path)
+	}
+
+	return FileInfo{
+		Path:     path,
+		Size:     info.Size,
+		Modified: info.Modified,
+		ReadOnly: info.ReadOnly,
+	}
+}
+
+func toAuditEntries(entries []application.AuditEntry) []AuditEntry {
+	result := make([]AuditEntry, 0, len(entries))
+	for _, entry := range entries {
+		result = append(result, toAuditEntry(entry))
+	}
+	return result
+}
+
+func toAuditEntry(entry application.AuditEntry) AuditEntry {
+	result := AuditEntry{
+		ID:        entry.ID,
+		Time:      entry.Time,
+		Operation: string(entry.Operation),
+		Path:      toKeys(entry.Path),
+	}
+
+	if entry.Key != nil {
+		key := toKey(*entry.Key)
+		result.Key = &key
+	}
+
+	return result
+}
+
+func fromCopyKey(copyKey CopyKey, encoding keyEncoding) ([]application.Key, application.Key, error) {
+	path, err := fromPath(copyKey.Path, encoding)
+	if err != nil {
+		return nil, application.Key{}, errors.Wrap(err, "invalid path")
+	}
+
+	key, err := fromKey(copyKey.Key, encoding)
+	if err != nil {
+		return nil, application.Key{}, errors.Wrap(err, "invalid key")
+	}
+
+	return path, key, nil
+}
+
+func fromMoveBucket(moveBucket MoveBucket, encoding keyEncoding) ([]application.Key, application.Key, error) {
+	parent, err := fromPath(moveBucket.Parent, encoding)
+	if err != nil {
+		return nil, application.Key{}, errors.Wrap(err, "invalid parent")
+	}
+
+	name, err := fromKey(moveBucket.Name, encoding)
+	if err != nil {
+		return nil, application.Key{}, errors.Wrap(err, "invalid name")
+	}
+
+	return parent, name, nil
+}
+
+func fromPath(elements []string, encoding keyEncoding) ([]application.Key, error) {
+	var path []application.Key
+
+	for i, element := range elements {
+		key, err := fromKey(element, encoding)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid path element %d", i)
+		}
+
+		path = append(path, key)
+	}
+
+	return path, nil
+}
+
+func fromKey(s string, encoding keyEncoding) (application.Key, error) {
+	b, err := encoding.Decode(s)
+	if err != nil {
+		return application.Key{}, errors.Wrap(err, "could not decode")
+	}
+
+	key, err := application.NewKey(b)
+	if err != nil {
+		return application.Key{}, errors.Wrap(err, "could not create a key")
+	}
+
+	return key, nil
+}
@@ -0,0 +1,75 @@
+package http
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/boreq/errors"
+	"github.com/contentforward/bolt-ui/application"
+)
+
+// keyEncoding specifies how the keys are encoded in the request paths and
+// query parameters. Keys are hex encoded by default. Passing encoding=base64
+// in the query selects the URL-safe base64 encoding with the padding being
+// optional which results in shorter URLs for binary keys. The responses
+// always include both encodings of the keys. Passing encoding=uint64 makes it
+// possible to look up the keys containing big-endian integers using decimal
+// numbers.
+type keyEncoding string
+
+const (
+	keyEncodingHex    keyEncoding = "hex"
+	keyEncodingBase64 keyEncoding = "base64"
+	keyEncodingUint64 keyEncoding = "uint64"
+)
+
+func readKeyEncoding(r *http.Request) (keyEncoding, error) {
+	switch encoding := keyEncoding(r.URL.Query().Get("encoding")); encoding {
+	case "", keyEncodingHex:
+		return keyEncodingHex, nil
+	case keyEncodingBase64:
+		return keyEncodingBase64, nil
+	case keyEncodingUint64:
+		return keyEncodingUint64, nil
+	default:
+		return "", errors.New("unknown key encoding")
+	}
+}
+
+func (e keyEncoding) Decode(s string) ([]byte, error) {
+	switch e {
+	case keyEncodingBase64:
+		return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+	case keyEncodingUint64:
+		n, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return nil, errors.Wrap(err, "parse uint failed")
+		}
+		return application.NewUint64Key(n).Bytes(), nil
+	default:
+		return hex.DecodeString(s)
+	}
+}
+
+// decodeKey decodes a key passed in the request using the encoding selected
+// by the request.
+func decodeKey(r *http.Request, s string) ([]byte, error) {
+	encoding, err := readKeyEncoding(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read the encoding")
+	}
+
+	if s == "" {
+		return nil, nil
+	}
+
+	b, err := encoding.Decode(s)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not decode")
+	}
+
+	return b, nil
+}
@@ -0,0 +1,169 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/contentforward/bolt-ui/application"
+)
+
+// apiError is an error response carrying a stable machine-readable code which
+// can be used by the clients instead of the message. Status codes and
+// messages are still included at the top level of the body to preserve
+// compatibility with the older clients. The body is serialized in the
+// following way:
+//
+//	{
+//		"statusCode": 404,
+//		"message": "Not found.",
+//		"error": {
+//			"code": "not_found",
+//			"message": "Not found."
+//		}
+//	}
+type apiError struct {
+	statusCode int
+	code       string
+	message    string
+	header     http.Header
+}
+
+func newAPIError(statusCode int, code, message string) apiError {
+	return apiError{
+		statusCode: statusCode,
+		code:       code,
+		message:    message,
+	}
+}
+
+// WithMessage returns a new error with the same code and the changed message.
+func (e apiError) WithMessage(message string) apiError {
+	e.message = message
+	return e
+}
+
+// WithHeader returns a new error which sets the header in the response.
+func (e apiError) WithHeader(key, value string) apiError {
+	header := make(http.Header)
+	for k, v := range e.header {
+		header[k] = v
+	}
+	header.Set(key, value)
+
+	e.header = header
+	return e
+}
+
+func (e apiError) Header() http.Header {
+	header := make(http.Header)
+	for k, v := range e.header {
+		header[k] = v
+	}
+	return header
+}
+
+func (e apiError) StatusCode() int {
+	return e.statusCode
+}
+
+func (e apiError) Body() interface{} {
+	return errorResponse{
+		StatusCode: e.statusCode,
+		Message:    e.message,
+		Error: errorResponseError{
+			Code:    e.code,
+			Message: e.message,
+		},
+	}
+}
+
+type errorResponse struct {
+	StatusCode int                `json:"statusCode"`
+	Message    string             `json:"message"`
+	Error      errorResponseError `json:"error"`
+}
+
+type errorResponseError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Generic errors. The codes are a part of the API and must not be changed.
+var (
+	errBadRequest          = newAPIError(http.StatusBadRequest, "bad_request", "Bad request.")
+	errUnauthorized        = newAPIError(http.StatusUnauthorized, "unauthorized", "Unauthorized.")
+	errForbidden           = newAPIError(http.StatusForbidden, "forbidden", "Forbidden.")
+	errNotFound            = newAPIError(http.StatusNotFound, "not_found", "Not found.")
+	errConflict            = newAPIError(http.StatusConflict, "conflict", "Conflict.")
+	errPreconditionFailed  = newAPIError(http.StatusPreconditionFailed, "precondition_failed", "Precondition failed.")
+	errTooManyRequests     = newAPIError(http.StatusTooManyRequests, "too_many_requests", "Too many requests.")
+	errInternalServerError = newAPIError(http.StatusInternalServerError, "internal", "Internal server error.")
+	errServiceUnavailable  = newAPIError(http.StatusServiceUnavailable, "unavailable", "Service unavailable.")
+	errRequestTimeout      = newAPIError(http.StatusGatewayTimeout, "timeout", "Request took too long.")
+	errRequestCanceled     = newAPIError(statusClientClosedRequest, "canceled", "Request was canceled.")
+)
+
+// statusClientClosedRequest is the non-standard status code used when the
+// client goes away before the response is written. The response is usually
+// never received but a response still has to be returned by the handler.
+const statusClientClosedRequest = 499
+
+// Errors describing the application errors.
+var (
+	errReadOnly             = newAPIError(http.StatusForbidden, "read_only", "Database is read-only.")
+	errNotABucket           = newAPIError(http.StatusBadRequest, "not_a_bucket", "Path points to a value.")
+	errNotAValue            = newAPIError(http.StatusBadRequest, "not_a_value", "Key points to a bucket.")
+	errBucketExists         = newAPIError(http.StatusConflict, "conflict", "Bucket already exists.")
+	errValueExists          = newAPIError(http.StatusConflict, "conflict", "Value already exists.")
+	errValueChanged         = newAPIError(http.StatusPreconditionFailed, "value_changed", "Value was modified since it was read.")
+	errMoveIntoDescendant   = newAPIError(http.StatusBadRequest, "move_into_descendant", "Bucket can not be moved into itself or its descendant.")
+	errContainsBuckets      = newAPIError(http.StatusBadRequest, "contains_buckets", "Bucket contains nested buckets.")
+	errAuditLogDisabled     = newAPIError(http.StatusBadRequest, "audit_log_disabled", "Audit log is disabled.")
+	errValueTooLarge        = newAPIError(http.StatusRequestEntityTooLarge, "value_too_large", "Value is too large.")
+	errInvalidImport        = newAPIError(http.StatusBadRequest, "invalid_import", "Imported data is invalid.")
+	errConfirmationMismatch = newAPIError(http.StatusPreconditionFailed, "confirmation_mismatch", "Bucket was modified since the deletion was previewed.")
+)
+
+// Errors returned by the specific handlers.
+var (
+	errConfirmationRequired    = newAPIError(http.StatusBadRequest, "confirmation_required", "Deleting a bucket requires the confirmation returned by the preview.")
+	errTokenValidationDisabled = newAPIError(http.StatusBadRequest, "token_disabled", "Token validation is disabled.")
+	errImportTooLarge          = newAPIError(http.StatusRequestEntityTooLarge, "import_too_large", "Imported file is too large.")
+)
+
+var applicationErrors = []struct {
+	Err      error
+	Response apiError
+}{
+	{application.ErrBucketNotFound, errNotFound},
+	{application.ErrKeyNotFound, errNotFound},
+	{application.ErrNotABucket, errNotABucket},
+	{application.ErrNotAValue, errNotAValue},
+	{application.ErrBucketExists, errBucketExists},
+	{application.ErrValueExists, errValueExists},
+	{application.ErrReadOnly, errReadOnly},
+	{application.ErrValueChanged, errValueChanged},
+	{application.ErrMoveIntoDescendant, errMoveIntoDescendant},
+	{application.ErrContainsBuckets, errContainsBuckets},
+	{application.ErrAuditLogDisabled, errAuditLogDisabled},
+	{application.ErrConfirmationMismatch, errConfirmationMismatch},
+	{application.ErrValueTooLarge, errValueTooLarge},
+	{application.ErrInvalidImport, errInvalidImport},
+	{context.DeadlineExceeded, errRequestTimeout},
+	{context.Canceled, errRequestCanceled},
+}
+
+// applicationError returns the response describing an error returned by the
+// application, including the errors caused by the request timing out or
+// being canceled by the client. Handlers which need a more specific message for one of those
+// errors should check for it before calling this function. False is returned
+// if the error is unknown.
+func applicationError(err error) (apiError, bool) {
+	for _, e := range applicationErrors {
+		if errors.Is(err, e.Err) {
+			return e.Response, true
+		}
+	}
+	return apiError{}, false
+}
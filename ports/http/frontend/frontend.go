@@ -1,31 +1,123 @@
 package frontend
 
 import (
+	"bytes"
 	"embed"
+	"io/ioutil"
 	"net/http"
+	"os"
+	"path"
+	"strings"
 )
 
 //go:embed css/* js/* index.html favicon.ico
 var content embed.FS
 
 type FrontendFileSystem struct {
-	fs http.FileSystem
+	fs       http.FileSystem
+	basePath string
 }
 
-func NewFrontendFileSystem() (*FrontendFileSystem, error) {
+// NewFrontendFileSystem serves the frontend. If the base path is not empty
+// the absolute paths used by the frontend are prefixed with it so that it
+// can be served under a subpath.
+func NewFrontendFileSystem(basePath string) (*FrontendFileSystem, error) {
 	return &FrontendFileSystem{
-		fs: http.FS(content),
+		fs:       http.FS(content),
+		basePath: basePath,
 	}, nil
 }
 
 func (f *FrontendFileSystem) Open(name string) (http.File, error) {
 	file, err := f.fs.Open(name)
 	if err != nil {
-		file, err := f.fs.Open("/index.html")
+		name = "/index.html"
+		file, err = f.fs.Open(name)
 		if err != nil {
 			return nil, err
 		}
-		return file, nil
 	}
+
+	if replacer := f.replacer(name); replacer != nil {
+		return newReplacedFile(file, replacer)
+	}
+
 	return file, nil
 }
+
+// replacer returns the replacer which prefixes the absolute paths in the
+// file with the base path or nil if the file doesn't have to be modified.
+// The frontend is built with the assets, the API and the router mounted at
+// the root.
+func (f *FrontendFileSystem) replacer(name string) *strings.Replacer {
+	if f.basePath == "" {
+		return nil
+	}
+
+	if name == "/index.html" {
+		return strings.NewReplacer(
+			`href="/`, `href="`+f.basePath+`/`,
+			`src="/`, `src="`+f.basePath+`/`,
+		)
+	}
+
+	if path.Dir(name) == "/js" && strings.HasPrefix(path.Base(name), "app.") {
+		return strings.NewReplacer(
+			`"/api/"`, `"`+f.basePath+`/api/"`,
+			`base:"/"`, `base:"`+f.basePath+`/"`,
+		)
+	}
+
+	return nil
+}
+
+// replacedFile holds the modified contents of a file in memory.
+type replacedFile struct {
+	*bytes.Reader
+	info os.FileInfo
+}
+
+func newReplacedFile(file http.File, replacer *strings.Replacer) (http.File, error) {
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := ioutil.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+
+	replaced := []byte(replacer.Replace(string(b)))
+
+	return &replacedFile{
+		Reader: bytes.NewReader(replaced),
+		info: replacedFileInfo{
+			FileInfo: info,
+			size:     int64(len(replaced)),
+		},
+	}, nil
+}
+
+func (f *replacedFile) Close() error {
+	return nil
+}
+
+func (f *replacedFile) Readdir(count int) ([]os.FileInfo, error) {
+	return nil, os.ErrInvalid
+}
+
+func (f *replacedFile) Stat() (os.FileInfo, error) {
+	return f.info, nil
+}
+
+type replacedFileInfo struct {
+	os.FileInfo
+	size int64
+}
+
+func (i replacedFileInfo) Size() int64 {
+	return i.size
+}
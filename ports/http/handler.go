@@ -1,65 +1,218 @@
 package http
 
 import (
+	"bytes"
+	"context"
 	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
 	"net/http"
+	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/boreq/errors"
 	"github.com/boreq/rest"
 	"github.com/contentforward/bolt-ui/application"
+	"github.com/contentforward/bolt-ui/internal/config"
 	"github.com/contentforward/bolt-ui/logging"
 	"github.com/contentforward/bolt-ui/ports/http/frontend"
 	"github.com/julienschmidt/httprouter"
 )
 
+// Databases provides access to the applications operating on each of the
+// databases opened by the program.
+type Databases interface {
+	// Names returns the names of all databases. The first database is used
+	// by the endpoints which don't specify the database explicitly.
+	Names() []string
+
+	// Get returns the application operating on the database with the
+	// provided name or false if such database doesn't exist.
+	Get(name string) (*application.Application, bool)
+}
+
 type Handler struct {
-	app          *application.Application
+	databases    Databases
 	authProvider AuthProvider
+	conf         *config.Config
+	metrics      *metrics
+	authBackoff  *authBackoff
 	router       *httprouter.Router
+	root         http.Handler
 	log          logging.Logger
 }
 
-func NewHandler(app *application.Application, authProvider AuthProvider) (*Handler, error) {
+func NewHandler(databases Databases, authProvider AuthProvider, conf *config.Config) (*Handler, error) {
 	h := &Handler{
-		app:          app,
+		databases:    databases,
 		authProvider: authProvider,
+		conf:         conf,
+		metrics:      newMetrics(),
+		authBackoff:  newAuthBackoff(conf.AuthBackoff, conf.AuthBackoffMax),
 		router:       httprouter.New(),
 		log:          logging.New("ports/http.Handler"),
 	}
 
-	h.router.HandlerFunc(http.MethodGet, "/api/browse/*path", rest.Wrap(h.browse))
+	if conf.Metrics {
+		h.router.HandlerFunc(http.MethodGet, "/metrics", h.serveMetrics)
+	}
+
+	h.handle(http.MethodGet, "/healthz", rest.Wrap(h.healthz))
+	h.handle(http.MethodGet, "/api/databases", rest.Wrap(h.listDatabases))
+	h.handle(http.MethodPost, "/api/token", rest.Wrap(h.rotateToken))
+
+	for _, prefix := range []string{"/api", "/api/databases/:database"} {
+		h.handle(http.MethodGet, prefix+"/browse/*path", rest.Wrap(h.browse))
+		h.handle(http.MethodGet, prefix+"/resolve/*path", rest.Wrap(h.resolvePath))
+		h.handle(http.MethodGet, prefix+"/buckets/*path", rest.Wrap(h.listBuckets))
+		h.handle(http.MethodPost, prefix+"/buckets/*path", rest.Wrap(h.createBucket))
+		h.handle(http.MethodDelete, prefix+"/buckets/*path", rest.Wrap(h.deleteBucket))
+		h.handle(http.MethodGet, prefix+"/delete-preview/*path", rest.Wrap(h.previewDeleteBucket))
+		h.handle(http.MethodPost, prefix+"/move/*path", rest.Wrap(h.moveBucket))
+		h.handle(http.MethodGet, prefix+"/contents/*path", rest.Wrap(h.countBucketContents))
+		h.handle(http.MethodGet, prefix+"/stats/*path", rest.Wrap(h.bucketStats))
+		h.handle(http.MethodGet, prefix+"/tree/*path", rest.Wrap(h.bucketTree))
+		h.handle(http.MethodGet, prefix+"/export/*path", wrapStreaming(h.exportBucket))
+		h.handle(http.MethodPost, prefix+"/import/*path", rest.Wrap(h.importBucket))
+		h.handle(http.MethodPost, prefix+"/batch/*path", rest.Wrap(h.batchWrite))
+		h.handleWithoutTimeout(http.MethodGet, prefix+"/backup", wrapStreaming(h.backup))
+		h.handleWithoutTimeout(http.MethodPost, prefix+"/compact", rest.Wrap(h.compactDatabase))
+		h.handle(http.MethodGet, prefix+"/check", rest.Wrap(h.checkConsistency))
+		h.handle(http.MethodGet, prefix+"/file", rest.Wrap(h.getFileInfo))
+		h.handleWithoutTimeout(http.MethodGet, prefix+"/changes/*path", wrapStreaming(h.changes))
+		h.handle(http.MethodGet, prefix+"/audit", rest.Wrap(h.listAuditEntries))
+		h.handle(http.MethodGet, prefix+"/keys/*path", rest.Wrap(h.listKeys))
+		h.handle(http.MethodGet, prefix+"/search/*path", rest.Wrap(h.searchKeys))
+		h.handle(http.MethodGet, prefix+"/count/*path", rest.Wrap(h.countKeysByPrefix))
+		h.handle(http.MethodGet, prefix+"/search-all", rest.Wrap(h.searchAllBuckets))
+		h.handle(http.MethodGet, prefix+"/search-values/*path", wrapStreaming(h.searchValues))
+		h.handle(http.MethodGet, prefix+"/value/*path", rest.Wrap(h.getValue))
+		h.handle(http.MethodGet, prefix+"/raw/*path", wrapStreaming(h.getRawValue))
+		h.handle(http.MethodGet, prefix+"/pretty/*path", wrapStreaming(h.getPrettyValue))
+		h.handle(http.MethodGet, prefix+"/hex/*path", wrapStreaming(h.getHexDump))
+		h.handle(http.MethodPut, prefix+"/value/*path", rest.Wrap(h.putValue))
+		h.handle(http.MethodPost, prefix+"/append/*path", rest.Wrap(h.appendValue))
+		h.handle(http.MethodDelete, prefix+"/value/*path", rest.Wrap(h.deleteKey))
+		h.handle(http.MethodPost, prefix+"/rename/*path", rest.Wrap(h.renameKey))
+		h.handle(http.MethodPost, prefix+"/copy/*path", rest.Wrap(h.copyKey))
+	}
 
-	ffs, err := frontend.NewFrontendFileSystem()
+	ffs, err := frontend.NewFrontendFileSystem(conf.BasePath)
 	if err != nil {
 		return nil, err
 	}
 	h.router.NotFound = http.FileServer(ffs)
 
+	h.root = withBasePath(h.router, conf.BasePath)
+
 	return h, nil
 }
 
 func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	h.router.ServeHTTP(w, r)
+	h.root.ServeHTTP(w, r)
+}
+
+// handle registers the handler which has to finish within the configured
+// request timeout.
+func (h *Handler) handle(method, path string, handler http.HandlerFunc) {
+	h.handleWithoutTimeout(method, path, withTimeout(handler, h.conf.RequestTimeout))
+}
+
+// handleWithoutTimeout registers the handler and instruments it if metrics are
+// enabled. It is used for long-lived requests and for operations which can't
+// be stopped in the middle.
+func (h *Handler) handleWithoutTimeout(method, path string, handler http.HandlerFunc) {
+	if h.conf.Metrics {
+		h.router.Handler(method, path, h.metrics.instrument(path, handler))
+		return
+	}
+	h.router.HandlerFunc(method, path, handler)
+}
+
+func (h *Handler) serveMetrics(w http.ResponseWriter, r *http.Request) {
+	if !h.conf.InsecureMetrics {
+		if response := h.checkAuth(r); response != nil {
+			rest.Wrap(func(r *http.Request) rest.RestResponse {
+				return response
+			})(w, r)
+			return
+		}
+	}
+
+	var databases []databaseMetrics
+
+	for _, name := range h.databases.Names() {
+		app, ok := h.databases.Get(name)
+		if !ok {
+			continue
+		}
+
+		stats, err := app.GetDatabaseStats.Execute(application.GetDatabaseStats{})
+		if err != nil {
+			h.log.Error("could not get the database stats", "database", name, "err", err)
+			continue
+		}
+
+		database := databaseMetrics{
+			Name:        name,
+			Stats:       stats,
+			BucketStats: make(map[string]application.BucketStats),
+		}
+
+		for _, bucket := range h.conf.MetricsBuckets {
+			bucketStats, err := app.GetBucketStats.Execute(application.GetBucketStats{
+				Path: []application.Key{
+					application.MustNewKey([]byte(bucket)),
+				},
+			})
+			if err != nil {
+				if !errors.Is(err, application.ErrBucketNotFound) {
+					h.log.Error("could not get the bucket stats", "database", name, "bucket", bucket, "err", err)
+				}
+				continue
+			}
+			database.BucketStats[bucket] = bucketStats
+		}
+
+		databases = append(databases, database)
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+
+	mw := newMetricsWriter(w)
+	h.metrics.writeRequests(mw)
+	writeDatabaseMetrics(mw, databases)
+	if err := mw.Err(); err != nil {
+		h.log.Warn("could not write the metrics", "err", err)
+	}
 }
 
 func (h *Handler) browse(r *http.Request) rest.RestResponse {
 	ps := httprouter.ParamsFromContext(r.Context())
 
-	ok, err := h.authProvider.Check(r)
-	if err != nil {
-		h.log.Error("auth provider get failed", "err", err)
-		return rest.ErrInternalServerError
+	if response := h.checkAuth(r); response != nil {
+		return response
 	}
 
-	if !ok {
-		return rest.ErrForbidden.WithMessage("Invalid token.")
+	app, response := h.getApplication(r)
+	if response != nil {
+		return response
 	}
 
-	path, err := readPath(ps.ByName("path"))
+	path, err := readPath(r, ps.ByName("path"))
 	if err != nil {
 		h.log.Warn("invalid path", "err", err)
-		return rest.ErrBadRequest.WithMessage("Invalid path.")
+		return errBadRequest.WithMessage("Invalid path.")
+	}
+
+	interpretation, err := readInterpretation(r)
+	if err != nil {
+		return errBadRequest.WithMessage("Invalid interpretKeys or interpretValues query param.")
 	}
 
 	query := application.Browse{
@@ -67,50 +220,2000 @@ func (h *Handler) browse(r *http.Request) rest.RestResponse {
 	}
 
 	if beforeString := r.URL.Query().Get("before"); beforeString != "" {
-		b, err := hex.DecodeString(beforeString)
+		b, err := decodeKey(r, beforeString)
 		if err != nil {
-			return rest.ErrBadRequest.WithMessage("Invalid before query param.")
+			return errBadRequest.WithMessage("Invalid before query param.")
 		}
 
 		before, err := application.NewKey(b)
 		if err != nil {
-			return rest.ErrBadRequest.WithMessage("Invalid before.")
+			return errBadRequest.WithMessage("Invalid before.")
 		}
 
 		query.Before = &before
 	}
 
 	if afterString := r.URL.Query().Get("after"); afterString != "" {
-		b, err := hex.DecodeString(afterString)
+		b, err := decodeKey(r, afterString)
 		if err != nil {
-			return rest.ErrBadRequest.WithMessage("Invalid after query param.")
+			return errBadRequest.WithMessage("Invalid after query param.")
 		}
 
 		after, err := application.NewKey(b)
 		if err != nil {
-			return rest.ErrBadRequest.WithMessage("Invalid after.")
+			return errBadRequest.WithMessage("Invalid after.")
 		}
 
 		query.After = &after
 	}
 
-	tree, err := h.app.Browse.Execute(query)
+	tree, err := app.Browse.Execute(query)
 	if err != nil {
-		if errors.Is(err, application.ErrBucketNotFound) {
-			return rest.ErrNotFound
+		if response, ok := applicationError(err); ok {
+			return response
 		}
 		h.log.Error("browse failure", "err", err)
-		return rest.ErrInternalServerError
+		return errInternalServerError
 	}
 
 	return rest.NewResponse(
-		toTree(tree),
+		toTree(tree, interpretation),
 	)
 }
 
-const sep = "/"
+func (h *Handler) resolvePath(r *http.Request) rest.RestResponse {
+	ps := httprouter.ParamsFromContext(r.Context())
+
+	if response := h.checkAuth(r); response != nil {
+		return response
+	}
+
+	app, response := h.getApplication(r)
+	if response != nil {
+		return response
+	}
+
+	path, err := readPath(r, ps.ByName("path"))
+	if err != nil {
+		h.log.Warn("invalid path", "err", err)
+		return errBadRequest.WithMessage("Invalid path.")
+	}
+
+	info, err := app.ResolvePath.Execute(application.ResolvePath{Path: path})
+	if err != nil {
+		h.log.Error("resolve path failure", "err", err)
+		return errInternalServerError
+	}
+
+	return rest.NewResponse(
+		toPathInfo(info),
+	)
+}
+
+func (h *Handler) listBuckets(r *http.Request) rest.RestResponse {
+	if response := h.checkAuth(r); response != nil {
+		return response
+	}
+
+	app, response := h.getApplication(r)
+	if response != nil {
+		return response
+	}
+
+	ps := httprouter.ParamsFromContext(r.Context())
+
+	path, err := readPath(r, ps.ByName("path"))
+	if err != nil {
+		h.log.Warn("invalid path", "err", err)
+		return errBadRequest.WithMessage("Invalid path.")
+	}
+
+	query := application.ListBuckets{
+		Path: path,
+	}
+
+	buckets, err := app.ListBuckets.Execute(query)
+	if err != nil {
+		if response, ok := applicationError(err); ok {
+			return response
+		}
+		h.log.Error("list buckets failure", "err", err)
+		return errInternalServerError
+	}
+
+	return rest.NewResponse(
+		toKeys(buckets),
+	)
+}
+
+func (h *Handler) createBucket(r *http.Request) rest.RestResponse {
+	ps := httprouter.ParamsFromContext(r.Context())
+
+	if response := h.checkAuth(r); response != nil {
+		return response
+	}
+
+	app, response := h.getApplication(r)
+	if response != nil {
+		return response
+	}
+
+	path, name, err := readPathAndKey(r, ps.ByName("path"))
+	if err != nil {
+		h.log.Warn("invalid path", "err", err)
+		return errBadRequest.WithMessage("Invalid path.")
+	}
+
+	cmd := application.CreateBucket{
+		Path: path,
+		Name: name,
+	}
+
+	if err := app.CreateBucket.Execute(cmd); err != nil {
+		if errors.Is(err, application.ErrValueExists) {
+			return errConflict.WithMessage("A value with this name already exists.")
+		}
+		if response, ok := applicationError(err); ok {
+			return response
+		}
+		h.log.Error("create bucket failure", "err", err)
+		return errInternalServerError
+	}
+
+	return rest.NewResponse(nil)
+}
+
+func (h *Handler) deleteBucket(r *http.Request) rest.RestResponse {
+	ps := httprouter.ParamsFromContext(r.Context())
+
+	if response := h.checkAuth(r); response != nil {
+		return response
+	}
+
+	app, response := h.getApplication(r)
+	if response != nil {
+		return response
+	}
+
+	path, err := readPath(r, ps.ByName("path"))
+	if err != nil {
+		h.log.Warn("invalid path", "err", err)
+		return errBadRequest.WithMessage("Invalid path.")
+	}
+
+	if len(path) == 0 {
+		return errBadRequest.WithMessage("Path can not be empty.")
+	}
+
+	// The confirmation makes sure that the bucket isn't deleted by accident
+	// e.g. by repeating a request.
+	confirmation := r.URL.Query().Get("confirmation")
+	if confirmation == "" {
+		return errConfirmationRequired
+	}
+
+	cmd := application.DeleteBucket{
+		Path:         path,
+		Confirmation: confirmation,
+	}
+
+	if err := app.DeleteBucket.Execute(cmd); err != nil {
+		if response, ok := applicationError(err); ok {
+			return response
+		}
+		h.log.Error("delete bucket failure", "err", err)
+		return errInternalServerError
+	}
+
+	return rest.NewResponse(nil)
+}
+
+func (h *Handler) previewDeleteBucket(r *http.Request) rest.RestResponse {
+	ps := httprouter.ParamsFromContext(r.Context())
+
+	if response := h.checkAuth(r); response != nil {
+		return response
+	}
+
+	app, response := h.getApplication(r)
+	if response != nil {
+		return response
+	}
+
+	path, err := readPath(r, ps.ByName("path"))
+	if err != nil {
+		h.log.Warn("invalid path", "err", err)
+		return errBadRequest.WithMessage("Invalid path.")
+	}
+
+	if len(path) == 0 {
+		return errBadRequest.WithMessage("Path can not be empty.")
+	}
+
+	interpretation, err := readInterpretation(r)
+	if err != nil {
+		return errBadRequest.WithMessage("Invalid interpretKeys or interpretValues query param.")
+	}
+
+	query := application.PreviewDeleteBucket{
+		Context: r.Context(),
+		Path:    path,
+	}
+
+	preview, err := app.PreviewDeleteBucket.Execute(query)
+	if err != nil {
+		if response, ok := applicationError(err); ok {
+			return response
+		}
+		h.log.Error("preview delete bucket failure", "err", err)
+		return errInternalServerError
+	}
+
+	return rest.NewResponse(
+		toDeleteBucketPreview(preview, interpretation),
+	)
+}
+
+func (h *Handler) moveBucket(r *http.Request) rest.RestResponse {
+	ps := httprouter.ParamsFromContext(r.Context())
+
+	if response := h.checkAuth(r); response != nil {
+		return response
+	}
+
+	app, response := h.getApplication(r)
+	if response != nil {
+		return response
+	}
+
+	path, err := readPath(r, ps.ByName("path"))
+	if err != nil {
+		h.log.Warn("invalid path", "err", err)
+		return errBadRequest.WithMessage("Invalid path.")
+	}
+
+	if len(path) == 0 {
+		return errBadRequest.WithMessage("Path can not be empty.")
+	}
+
+	var moveBucket MoveBucket
+	if err := json.NewDecoder(r.Body).Decode(&moveBucket); err != nil {
+		h.log.Warn("invalid move bucket", "err", err)
+		return errBadRequest.WithMessage("Invalid JSON.")
+	}
+
+	encoding, err := readKeyEncoding(r)
+	if err != nil {
+		h.log.Warn("invalid encoding", "err", err)
+		return errBadRequest.WithMessage("Invalid encoding.")
+	}
+
+	parent, name, err := fromMoveBucket(moveBucket, encoding)
+	if err != nil {
+		h.log.Warn("invalid move bucket", "err", err)
+		return errBadRequest.WithMessage("Invalid destination.")
+	}
+
+	cmd := application.MoveBucket{
+		Path:              path,
+		DestinationParent: parent,
+		Name:              name,
+	}
+
+	if err := app.MoveBucket.Execute(cmd); err != nil {
+		if errors.Is(err, application.ErrBucketExists) || errors.Is(err, application.ErrValueExists) {
+			return errConflict.WithMessage("Destination already exists.")
+		}
+		if response, ok := applicationError(err); ok {
+			return response
+		}
+		h.log.Error("move bucket failure", "err", err)
+		return errInternalServerError
+	}
+
+	return rest.NewResponse(nil)
+}
+
+func (h *Handler) countBucketContents(r *http.Request) rest.RestResponse {
+	ps := httprouter.ParamsFromContext(r.Context())
+
+	if response := h.checkAuth(r); response != nil {
+		return response
+	}
+
+	app, response := h.getApplication(r)
+	if response != nil {
+		return response
+	}
+
+	path, err := readPath(r, ps.ByName("path"))
+	if err != nil {
+		h.log.Warn("invalid path", "err", err)
+		return errBadRequest.WithMessage("Invalid path.")
+	}
+
+	query := application.CountBucketContents{
+		Context: r.Context(),
+		Path:    path,
+	}
+
+	contents, err := app.CountBucketContents.Execute(query)
+	if err != nil {
+		if response, ok := applicationError(err); ok {
+			return response
+		}
+		h.log.Error("count bucket contents failure", "err", err)
+		return errInternalServerError
+	}
+
+	return rest.NewResponse(
+		toBucketContents(contents),
+	)
+}
+
+func (h *Handler) bucketTree(r *http.Request) rest.RestResponse {
+	ps := httprouter.ParamsFromContext(r.Context())
+
+	if response := h.checkAuth(r); response != nil {
+		return response
+	}
+
+	app, response := h.getApplication(r)
+	if response != nil {
+		return response
+	}
+
+	path, err := readPath(r, ps.ByName("path"))
+	if err != nil {
+		h.log.Warn("invalid path", "err", err)
+		return errBadRequest.WithMessage("Invalid path.")
+	}
+
+	query := application.GetBucketTree{
+		Context: r.Context(),
+		Path:    path,
+	}
+
+	tree, err := app.GetBucketTree.Execute(query)
+	if err != nil {
+		if response, ok := applicationError(err); ok {
+			return response
+		}
+		h.log.Error("bucket tree failure", "err", err)
+		return errInternalServerError
+	}
+
+	return rest.NewResponse(
+		toBucketTreeNodes(tree),
+	)
+}
+
+func (h *Handler) bucketStats(r *http.Request) rest.RestResponse {
+	ps := httprouter.ParamsFromContext(r.Context())
+
+	if response := h.checkAuth(r); response != nil {
+		return response
+	}
+
+	app, response := h.getApplication(r)
+	if response != nil {
+		return response
+	}
+
+	path, err := readPath(r, ps.ByName("path"))
+	if err != nil {
+		h.log.Warn("invalid path", "err", err)
+		return errBadRequest.WithMessage("Invalid path.")
+	}
+
+	query := application.GetBucketStats{
+		Path: path,
+	}
+
+	stats, err := app.GetBucketStats.Execute(query)
+	if err != nil {
+		if response, ok := applicationError(err); ok {
+			return response
+		}
+		h.log.Error("bucket stats failure", "err", err)
+		return errInternalServerError
+	}
+
+	return rest.NewResponse(
+		toBucketStats(stats),
+	)
+}
+
+// changes pushes the changes related to the bucket over a WebSocket. As
+// browsers can't set headers when opening a WebSocket the token can also be
+// passed using the token query parameter.
+func (h *Handler) changes(w http.ResponseWriter, r *http.Request) rest.RestResponse {
+	ps := httprouter.ParamsFromContext(r.Context())
+
+	if r.Header.Get("Access-Token") == "" {
+		r.Header.Set("Access-Token", r.URL.Query().Get("token"))
+	}
+
+	if response := h.checkAuth(r); response != nil {
+		return response
+	}
+
+	app, response := h.getApplication(r)
+	if response != nil {
+		return response
+	}
+
+	path, err := readPath(r, ps.ByName("path"))
+	if err != nil {
+		h.log.Warn("invalid path", "err", err)
+		return errBadRequest.WithMessage("Invalid path.")
+	}
+
+	conn, err := upgradeWebsocket(w, r)
+	if err != nil {
+		h.log.Warn("websocket upgrade failed", "err", err)
+		return errBadRequest.WithMessage("Invalid WebSocket upgrade request.")
+	}
+	defer conn.Close()
+
+	changes, unsubscribe := app.SubscribeToChanges.Execute(application.SubscribeToChanges{
+		Path: path,
+	})
+	defer unsubscribe()
+
+	for {
+		select {
+		case change := <-changes:
+			b, err := json.Marshal(toChange(change))
+			if err != nil {
+				h.log.Error("could not marshal the change", "err", err)
+				return nil
+			}
+
+			if err := conn.WriteText(b); err != nil {
+				return nil
+			}
+		case <-conn.Closed():
+			return nil
+		}
+	}
+}
+
+func (h *Handler) exportBucket(w http.ResponseWriter, r *http.Request) rest.RestResponse {
+	ps := httprouter.ParamsFromContext(r.Context())
+
+	if response := h.checkAuth(r); response != nil {
+		return response
+	}
+
+	app, response := h.getApplication(r)
+	if response != nil {
+		return response
+	}
+
+	path, err := readPath(r, ps.ByName("path"))
+	if err != nil {
+		h.log.Warn("invalid path", "err", err)
+		return errBadRequest.WithMessage("Invalid path.")
+	}
+
+	var writer *attachmentWriter
+	var export func() error
+
+	switch format := r.URL.Query().Get("format"); format {
+	case "", "json":
+		writer = newAttachmentWriter(w, "application/json", "export.json")
+		export = func() error {
+			return app.ExportBucket.Execute(application.ExportBucket{
+				Context: r.Context(),
+				Path:    path,
+				Writer:  writer,
+			})
+		}
+	case "csv":
+		writer = newAttachmentWriter(w, "text/csv; charset=utf-8", "export.csv")
+		export = func() error {
+			return app.ExportBucketCSV.Execute(application.ExportBucketCSV{
+				Context: r.Context(),
+				Path:    path,
+				Writer:  writer,
+			})
+		}
+	default:
+		return errBadRequest.WithMessage("Format must be json or csv.")
+	}
+
+	if err := export(); err != nil {
+		if writer.Written() {
+			h.log.Error("export failed after writing the response", "err", err)
+			return nil
+		}
+		if errors.Is(err, application.ErrContainsBuckets) {
+			return errContainsBuckets.WithMessage("Bucket contains nested buckets, only flat buckets can be exported as CSV.")
+		}
+		if response, ok := applicationError(err); ok {
+			return response
+		}
+		h.log.Error("export failure", "err", err)
+		return errInternalServerError
+	}
+
+	return nil
+}
+
+func (h *Handler) importBucket(r *http.Request) rest.RestResponse {
+	ps := httprouter.ParamsFromContext(r.Context())
+
+	if response := h.checkAuth(r); response != nil {
+		return response
+	}
+
+	app, response := h.getApplication(r)
+	if response != nil {
+		return response
+	}
+
+	path, err := readPath(r, ps.ByName("path"))
+	if err != nil {
+		h.log.Warn("invalid path", "err", err)
+		return errBadRequest.WithMessage("Invalid path.")
+	}
+
+	mode, err := readImportMode(r.URL.Query().Get("mode"))
+	if err != nil {
+		return errBadRequest.WithMessage("Invalid mode query param.")
+	}
+
+	var dryRun bool
+	if dryRunString := r.URL.Query().Get("dryRun"); dryRunString != "" {
+		dryRun, err = strconv.ParseBool(dryRunString)
+		if err != nil {
+			return errBadRequest.WithMessage("Invalid dryRun query param.")
+		}
+	}
+
+	if h.conf.MaxImportSize > 0 && r.ContentLength > int64(h.conf.MaxImportSize) {
+		return h.importTooLarge()
+	}
+
+	if r.URL.Query().Get("format") == importFormatBolt {
+		if dryRun {
+			return errBadRequest.WithMessage("Dry runs are not supported when importing Bolt files.")
+		}
+		return h.importBoltFile(r, app, path, mode)
+	}
+
+	format, err := readImportFormat(r.URL.Query().Get("format"))
+	if err != nil {
+		return errBadRequest.WithMessage("Invalid format query param.")
+	}
+
+	cmd := application.ImportBucket{
+		Path:   path,
+		Reader: h.limitImportBody(r),
+		Mode:   mode,
+		Format: format,
+		DryRun: dryRun,
+	}
+
+	summary, err := app.ImportBucket.Execute(cmd)
+	if err != nil {
+		return h.importError(err)
+	}
+
+	return rest.NewResponse(toImportSummary(summary))
+}
+
+// importFormatBolt selects importing an uploaded Bolt database file. Bolt can
+// only open files stored on disk so the upload is written to a temporary file
+// which is completely received before the import starts.
+const importFormatBolt = "bolt"
+
+func (h *Handler) importBoltFile(r *http.Request, app *application.Application, path []application.Key, mode application.ImportMode) rest.RestResponse {
+	tmp, err := ioutil.TempFile("", "bolt-ui-import-")
+	if err != nil {
+		h.log.Error("could not create a temporary file", "err", err)
+		return errInternalServerError
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, h.limitImportBody(r)); err != nil {
+		tmp.Close()
+		if errors.Is(err, errBodyTooLarge) {
+			return h.importTooLarge()
+		}
+		h.log.Warn("could not read the body", "err", err)
+		return errBadRequest.WithMessage("Could not read the body.")
+	}
+
+	if err := tmp.Close(); err != nil {
+		h.log.Error("could not close the temporary file", "err", err)
+		return errInternalServerError
+	}
+
+	cmd := application.ImportBoltFile{
+		SourcePath: tmp.Name(),
+		Path:       path,
+		Mode:       mode,
+	}
+
+	if err := app.ImportBoltFile.Execute(cmd); err != nil {
+		return h.importError(err)
+	}
+
+	return rest.NewResponse(nil)
+}
+
+func (h *Handler) importError(err error) rest.RestResponse {
+	if errors.Is(err, errBodyTooLarge) {
+		return h.importTooLarge()
+	}
+	if errors.Is(err, application.ErrNotAValue) || errors.Is(err, application.ErrValueExists) {
+		return errConflict.WithMessage("Imported data conflicts with the existing data.")
+	}
+	if response, ok := applicationError(err); ok {
+		return response
+	}
+	h.log.Error("import failure", "err", err)
+	return errInternalServerError
+}
+
+func (h *Handler) batchWrite(r *http.Request) rest.RestResponse {
+	ps := httprouter.ParamsFromContext(r.Context())
+
+	if response := h.checkAuth(r); response != nil {
+		return response
+	}
+
+	app, response := h.getApplication(r)
+	if response != nil {
+		return response
+	}
+
+	path, err := readPath(r, ps.ByName("path"))
+	if err != nil {
+		h.log.Warn("invalid path", "err", err)
+		return errBadRequest.WithMessage("Invalid path.")
+	}
+
+	var batchWrite BatchWrite
+	if err := json.NewDecoder(r.Body).Decode(&batchWrite); err != nil {
+		h.log.Warn("invalid batch write", "err", err)
+		return errBadRequest.WithMessage("Invalid JSON.")
+	}
+
+	encoding, err := readKeyEncoding(r)
+	if err != nil {
+		h.log.Warn("invalid encoding", "err", err)
+		return errBadRequest.WithMessage("Invalid encoding.")
+	}
+
+	operations, err := fromBatchWrite(batchWrite, encoding)
+	if err != nil {
+		h.log.Warn("invalid batch write", "err", err)
+		return errBadRequest.WithMessage("Invalid operations.")
+	}
+
+	cmd := application.BatchWrite{
+		Path:       path,
+		Operations: operations,
+	}
+
+	if err := app.BatchWrite.Execute(cmd); err != nil {
+		if errors.Is(err, application.ErrNotAValue) {
+			return errConflict.WithMessage("Key points to a bucket.")
+		}
+		if errors.Is(err, application.ErrValueTooLarge) {
+			return h.valueTooLarge()
+		}
+		if response, ok := applicationError(err); ok {
+			return response
+		}
+		h.log.Error("batch write failure", "err", err)
+		return errInternalServerError
+	}
+
+	return rest.NewResponse(nil)
+}
+
+func (h *Handler) backup(w http.ResponseWriter, r *http.Request) rest.RestResponse {
+	if response := h.checkAuth(r); response != nil {
+		return response
+	}
+
+	app, response := h.getApplication(r)
+	if response != nil {
+		return response
+	}
+
+	writer := newAttachmentWriter(w, "application/octet-stream", "backup.db")
+
+	query := application.Backup{
+		Writer: writer,
+	}
+
+	n, err := app.Backup.Execute(query)
+	if err != nil {
+		if writer.Written() {
+			h.log.Error("backup failed after writing the response", "err", err)
+			return nil
+		}
+		h.log.Error("backup failure", "err", err)
+		return errInternalServerError
+	}
+
+	h.log.Debug("backup written", "bytes", n)
+	return nil
+}
+
+// compactDatabase replaces the database file with a compacted copy. Other
+// requests wait until the compaction is done.
+func (h *Handler) compactDatabase(r *http.Request) rest.RestResponse {
+	if response := h.checkAuth(r); response != nil {
+		return response
+	}
+
+	app, response := h.getApplication(r)
+	if response != nil {
+		return response
+	}
+
+	result, err := app.CompactDatabase.Execute(application.CompactDatabase{})
+	if err != nil {
+		if response, ok := applicationError(err); ok {
+			return response
+		}
+		h.log.Error("compaction failure", "err", err)
+		return errInternalServerError
+	}
+
+	h.log.Info("database compacted", "sizeBefore", result.SizeBefore, "sizeAfter", result.SizeAfter)
+
+	return rest.NewResponse(
+		toCompactionResult(result),
+	)
+}
+
+func (h *Handler) checkConsistency(r *http.Request) rest.RestResponse {
+	if response := h.checkAuth(r); response != nil {
+		return response
+	}
+
+	app, response := h.getApplication(r)
+	if response != nil {
+		return response
+	}
+
+	problems, err := app.CheckConsistency.Execute(application.CheckConsistency{})
+	if err != nil {
+		h.log.Error("consistency check failure", "err", err)
+		return errInternalServerError
+	}
+
+	return rest.NewResponse(
+		ConsistencyCheck{
+			Problems: problems,
+		},
+	)
+}
+
+func (h *Handler) getFileInfo(r *http.Request) rest.RestResponse {
+	if response := h.checkAuth(r); response != nil {
+		return response
+	}
+
+	app, response := h.getApplication(r)
+	if response != nil {
+		return response
+	}
+
+	info, err := app.GetFileInfo.Execute(application.GetFileInfo{})
+	if err != nil {
+		h.log.Error("get file info failure", "err", err)
+		return errInternalServerError
+	}
+
+	return rest.NewResponse(
+		toFileInfo(info, h.conf.RedactPaths),
+	)
+}
+
+const defaultListKeysLimit = 100
+
+func (h *Handler) listKeys(r *http.Request) rest.RestResponse {
+	ps := httprouter.ParamsFromContext(r.Context())
+
+	if response := h.checkAuth(r); response != nil {
+		return response
+	}
+
+	app, response := h.getApplication(r)
+	if response != nil {
+		return response
+	}
+
+	path, err := readPath(r, ps.ByName("path"))
+	if err != nil {
+		h.log.Warn("invalid path", "err", err)
+		return errBadRequest.WithMessage("Invalid path.")
+	}
+
+	interpretation, err := readInterpretation(r)
+	if err != nil {
+		return errBadRequest.WithMessage("Invalid interpretKeys or interpretValues query param.")
+	}
+
+	query := application.ListKeys{
+		Context: r.Context(),
+		Path:    path,
+		Limit:   defaultListKeysLimit,
+	}
+
+	if afterString := r.URL.Query().Get("after"); afterString != "" {
+		b, err := decodeKey(r, afterString)
+		if err != nil {
+			return errBadRequest.WithMessage("Invalid after query param.")
+		}
+
+		after, err := application.NewKey(b)
+		if err != nil {
+			return errBadRequest.WithMessage("Invalid after.")
+		}
+
+		query.After = &after
+	}
+
+	limit, err := readLimit(r)
+	if err != nil {
+		return errBadRequest.WithMessage("Invalid limit query param.")
+	}
+	query.Limit = limit
+
+	if countString := r.URL.Query().Get("count"); countString != "" {
+		count, err := strconv.ParseBool(countString)
+		if err != nil {
+			return errBadRequest.WithMessage("Invalid count query param.")
+		}
+		query.Count = count
+	}
+
+	if sizesString := r.URL.Query().Get("sizes"); sizesString != "" {
+		sizes, err := strconv.ParseBool(sizesString)
+		if err != nil {
+			return errBadRequest.WithMessage("Invalid sizes query param.")
+		}
+		query.Sizes = sizes
+	}
+
+	order, err := readListKeysOrder(r.URL.Query().Get("sort"))
+	if err != nil {
+		return errBadRequest.WithMessage("Invalid sort query param.")
+	}
+	query.Order = order
+
+	if (order == application.ListKeysOrderSizeAscending || order == application.ListKeysOrderSizeDescending) && !query.Sizes {
+		return errBadRequest.WithMessage("Sorting by size requires the sizes query param.")
+	}
+
+	page, err := app.ListKeys.Execute(query)
+	if err != nil {
+		if response, ok := applicationError(err); ok {
+			return response
+		}
+		h.log.Error("list keys failure", "err", err)
+		return errInternalServerError
+	}
+
+	return rest.NewResponse(
+		toKeysPage(page, interpretation),
+	)
+}
+
+func (h *Handler) searchKeys(r *http.Request) rest.RestResponse {
+	ps := httprouter.ParamsFromContext(r.Context())
+
+	if response := h.checkAuth(r); response != nil {
+		return response
+	}
+
+	app, response := h.getApplication(r)
+	if response != nil {
+		return response
+	}
+
+	path, err := readPath(r, ps.ByName("path"))
+	if err != nil {
+		h.log.Warn("invalid path", "err", err)
+		return errBadRequest.WithMessage("Invalid path.")
+	}
+
+	interpretation, err := readInterpretation(r)
+	if err != nil {
+		return errBadRequest.WithMessage("Invalid interpretKeys or interpretValues query param.")
+	}
+
+	prefix, err := decodeKey(r, r.URL.Query().Get("prefix"))
+	if err != nil {
+		return errBadRequest.WithMessage("Invalid prefix query param.")
+	}
+
+	limit, err := readLimit(r)
+	if err != nil {
+		return errBadRequest.WithMessage("Invalid limit query param.")
+	}
+
+	query := application.SearchKeys{
+		Path:   path,
+		Prefix: prefix,
+		Limit:  limit,
+	}
+
+	keys, err := app.SearchKeys.Execute(query)
+	if err != nil {
+		if response, ok := applicationError(err); ok {
+			return response
+		}
+		h.log.Error("search keys failure", "err", err)
+		return errInternalServerError
+	}
+
+	return rest.NewResponse(
+		toKeyInfos(keys, interpretation),
+	)
+}
+
+func (h *Handler) countKeysByPrefix(r *http.Request) rest.RestResponse {
+	ps := httprouter.ParamsFromContext(r.Context())
+
+	if response := h.checkAuth(r); response != nil {
+		return response
+	}
+
+	app, response := h.getApplication(r)
+	if response != nil {
+		return response
+	}
+
+	path, err := readPath(r, ps.ByName("path"))
+	if err != nil {
+		h.log.Warn("invalid path", "err", err)
+		return errBadRequest.WithMessage("Invalid path.")
+	}
+
+	prefix, err := decodeKey(r, r.URL.Query().Get("prefix"))
+	if err != nil {
+		return errBadRequest.WithMessage("Invalid prefix query param.")
+	}
+
+	query := application.CountKeysByPrefix{
+		Context: r.Context(),
+		Path:    path,
+		Prefix:  prefix,
+	}
+
+	n, err := app.CountKeysByPrefix.Execute(query)
+	if err != nil {
+		if response, ok := applicationError(err); ok {
+			return response
+		}
+		h.log.Error("count keys by prefix failure", "err", err)
+		return errInternalServerError
+	}
+
+	return rest.NewResponse(
+		KeyCount{
+			Count: n,
+		},
+	)
+}
+
+// searchTimeout limits the time spent walking large databases when
+// searching keys or values.
+const searchTimeout = 10 * time.Second
+
+func (h *Handler) searchAllBuckets(r *http.Request) rest.RestResponse {
+	if response := h.checkAuth(r); response != nil {
+		return response
+	}
+
+	app, response := h.getApplication(r)
+	if response != nil {
+		return response
+	}
+
+	pattern := r.URL.Query().Get("pattern")
+	if pattern == "" {
+		return errBadRequest.WithMessage("Missing pattern query param.")
+	}
+
+	limit, err := readLimit(r)
+	if err != nil {
+		return errBadRequest.WithMessage("Invalid limit query param.")
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), searchTimeout)
+	defer cancel()
+
+	query := application.SearchAllBuckets{
+		Context: ctx,
+		Pattern: pattern,
+		Limit:   limit,
+	}
+
+	hits, err := app.SearchAllBuckets.Execute(query)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return errRequestTimeout.WithMessage("Search took too long, try a more specific pattern.")
+		}
+		if response, ok := applicationError(err); ok {
+			return response
+		}
+		h.log.Error("search all buckets failure", "err", err)
+		return errInternalServerError
+	}
+
+	return rest.NewResponse(
+		toSearchHits(hits),
+	)
+}
+
+func (h *Handler) listAuditEntries(r *http.Request) rest.RestResponse {
+	if response := h.checkAuth(r); response != nil {
+		return response
+	}
+
+	app, response := h.getApplication(r)
+	if response != nil {
+		return response
+	}
+
+	limit, err := readLimit(r)
+	if err != nil {
+		return errBadRequest.WithMessage("Invalid limit query param.")
+	}
+
+	var since time.Time
+	if sinceString := r.URL.Query().Get("since"); sinceString != "" {
+		since, err = time.Parse(time.RFC3339, sinceString)
+		if err != nil {
+			return errBadRequest.WithMessage("Invalid since query param, expected an RFC 3339 timestamp.")
+		}
+	}
+
+	var before uint64
+	if beforeString := r.URL.Query().Get("before"); beforeString != "" {
+		before, err = strconv.ParseUint(beforeString, 10, 64)
+		if err != nil {
+			return errBadRequest.WithMessage("Invalid before query param, expected the ID of an entry.")
+		}
+	}
+
+	query := application.ListAuditEntries{
+		Since:  since,
+		Before: before,
+		Limit:  limit,
+	}
+
+	entries, err := app.ListAuditEntries.Execute(query)
+	if err != nil {
+		if response, ok := applicationError(err); ok {
+			return response
+		}
+		h.log.Error("list audit entries failure", "err", err)
+		return errInternalServerError
+	}
+
+	return rest.NewResponse(
+		toAuditEntries(entries),
+	)
+}
+
+// searchValues streams the hits as newline delimited JSON.
+func (h *Handler) searchValues(w http.ResponseWriter, r *http.Request) rest.RestResponse {
+	ps := httprouter.ParamsFromContext(r.Context())
+
+	if response := h.checkAuth(r); response != nil {
+		return response
+	}
+
+	app, response := h.getApplication(r)
+	if response != nil {
+		return response
+	}
+
+	path, err := readPath(r, ps.ByName("path"))
+	if err != nil {
+		h.log.Warn("invalid path", "err", err)
+		return errBadRequest.WithMessage("Invalid path.")
+	}
+
+	valueQuery := application.ValueQuery{
+		Substring: r.URL.Query().Get("substring"),
+		JSONPath:  r.URL.Query().Get("jsonPath"),
+	}
+
+	if valueQuery.Substring == "" && valueQuery.JSONPath == "" {
+		return errBadRequest.WithMessage("Missing substring or jsonPath query param.")
+	}
+
+	if includeBinaryString := r.URL.Query().Get("includeBinary"); includeBinaryString != "" {
+		includeBinary, err := strconv.ParseBool(includeBinaryString)
+		if err != nil {
+			return errBadRequest.WithMessage("Invalid includeBinary query param.")
+		}
+		valueQuery.IncludeBinary = includeBinary
+	}
+
+	limit, err := readLimit(r)
+	if err != nil {
+		return errBadRequest.WithMessage("Invalid limit query param.")
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), searchTimeout)
+	defer cancel()
+
+	written := false
+	encoder := json.NewEncoder(w)
+
+	query := application.SearchValues{
+		Context: ctx,
+		Path:    path,
+		Query:   valueQuery,
+		Limit:   limit,
+		Writer: func(hit application.SearchHit) error {
+			if !written {
+				written = true
+				w.Header().Set("Content-Type", "application/x-ndjson")
+				w.WriteHeader(http.StatusOK)
+			}
+
+			if err := encoder.Encode(toSearchHit(hit)); err != nil {
+				return errors.Wrap(err, "encoding failed")
+			}
+
+			if flusher, ok := w.(http.Flusher); ok {
+				flusher.Flush()
+			}
+
+			return nil
+		},
+	}
+
+	if err := app.SearchValues.Execute(query); err != nil {
+		if written {
+			h.log.Warn("search values failed after writing the response", "err", err)
+			return nil
+		}
+		if errors.Is(err, context.DeadlineExceeded) {
+			return errRequestTimeout.WithMessage("Search took too long, try searching a smaller bucket.")
+		}
+		if response, ok := applicationError(err); ok {
+			return response
+		}
+		h.log.Error("search values failure", "err", err)
+		return errInternalServerError
+	}
+
+	if !written {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.WriteHeader(http.StatusOK)
+	}
+
+	return nil
+}
+
+func (h *Handler) getValue(r *http.Request) rest.RestResponse {
+	ps := httprouter.ParamsFromContext(r.Context())
+
+	if response := h.checkAuth(r); response != nil {
+		return response
+	}
+
+	app, response := h.getApplication(r)
+	if response != nil {
+		return response
+	}
+
+	path, key, err := readPathAndKey(r, ps.ByName("path"))
+	if err != nil {
+		h.log.Warn("invalid path", "err", err)
+		return errBadRequest.WithMessage("Invalid path.")
+	}
+
+	interpretation, err := readInterpretation(r)
+	if err != nil {
+		return errBadRequest.WithMessage("Invalid interpretKeys or interpretValues query param.")
+	}
+
+	query := application.GetValue{
+		Path: path,
+		Key:  key,
+	}
+
+	value, err := app.GetValue.Execute(query)
+	if err != nil {
+		if response, ok := applicationError(err); ok {
+			return response
+		}
+		h.log.Error("get value failure", "err", err)
+		return errInternalServerError
+	}
+
+	return rest.NewResponse(
+		interpretation.value(value),
+	).WithHeader("ETag", formatETag(value.ETag()))
+}
+
+// getPrettyValue writes JSON values re-indented with sorted keys. Other values
+// and values requested with the raw query param are written as is.
+func (h *Handler) getPrettyValue(w http.ResponseWriter, r *http.Request) rest.RestResponse {
+	ps := httprouter.ParamsFromContext(r.Context())
+
+	if response := h.checkAuth(r); response != nil {
+		return response
+	}
+
+	app, response := h.getApplication(r)
+	if response != nil {
+		return response
+	}
+
+	path, key, err := readPathAndKey(r, ps.ByName("path"))
+	if err != nil {
+		h.log.Warn("invalid path", "err", err)
+		return errBadRequest.WithMessage("Invalid path.")
+	}
+
+	raw := false
+	if rawString := r.URL.Query().Get("raw"); rawString != "" {
+		raw, err = strconv.ParseBool(rawString)
+		if err != nil {
+			return errBadRequest.WithMessage("Invalid raw query param.")
+		}
+	}
+
+	query := application.GetValue{
+		Path: path,
+		Key:  key,
+	}
+
+	value, err := app.GetValue.Execute(query)
+	if err != nil {
+		if response, ok := applicationError(err); ok {
+			return response
+		}
+		h.log.Error("get value failure", "err", err)
+		return errInternalServerError
+	}
+
+	b := value.Bytes()
+	contentType := "application/octet-stream"
+
+	if !raw {
+		if formatted, ok := application.FormatJSON(b); ok {
+			b = formatted
+			contentType = "application/json"
+		}
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(b)))
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(b); err != nil {
+		h.log.Warn("writing the value failed", "err", err)
+	}
+
+	return nil
+}
+
+// getHexDump writes a hex dump of the value in the format produced by
+// hex.Dump. The dump is limited to the number of bytes specified by the
+// limit query param or by the configuration, zero means no limit.
+func (h *Handler) getHexDump(w http.ResponseWriter, r *http.Request) rest.RestResponse {
+	ps := httprouter.ParamsFromContext(r.Context())
+
+	if response := h.checkAuth(r); response != nil {
+		return response
+	}
+
+	app, response := h.getApplication(r)
+	if response != nil {
+		return response
+	}
+
+	path, key, err := readPathAndKey(r, ps.ByName("path"))
+	if err != nil {
+		h.log.Warn("invalid path", "err", err)
+		return errBadRequest.WithMessage("Invalid path.")
+	}
+
+	limit := h.conf.HexDumpLimit
+	if limitString := r.URL.Query().Get("limit"); limitString != "" {
+		limit, err = strconv.Atoi(limitString)
+		if err != nil || limit < 0 {
+			return errBadRequest.WithMessage("Invalid limit query param.")
+		}
+	}
+
+	written := false
+
+	query := application.StreamValue{
+		Path: path,
+		Key:  key,
+		Writer: func(size int, reader io.Reader) error {
+			written = true
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			w.WriteHeader(http.StatusOK)
+
+			truncated := limit > 0 && size > limit
+			if truncated {
+				reader = io.LimitReader(reader, int64(limit))
+			}
+
+			dumper := hex.Dumper(w)
+			if _, err := io.Copy(dumper, reader); err != nil {
+				return errors.Wrap(err, "copy failed")
+			}
+
+			if err := dumper.Close(); err != nil {
+				return errors.Wrap(err, "could not close the dumper")
+			}
+
+			if truncated {
+				if _, err := fmt.Fprintf(w, "\n(truncated: showing the first %d of %d bytes)\n", limit, size); err != nil {
+					return errors.Wrap(err, "could not write the note")
+				}
+			}
+
+			return nil
+		},
+	}
+
+	if err := app.StreamValue.Execute(query); err != nil {
+		if written {
+			h.log.Warn("writing the hex dump failed after writing the response", "err", err)
+			return nil
+		}
+		if response, ok := applicationError(err); ok {
+			return response
+		}
+		h.log.Error("hex dump failure", "err", err)
+		return errInternalServerError
+	}
+
+	return nil
+}
+
+// getRawValue writes the value as the response body directly from the
+// database without loading it into memory first.
+func (h *Handler) getRawValue(w http.ResponseWriter, r *http.Request) rest.RestResponse {
+	ps := httprouter.ParamsFromContext(r.Context())
+
+	if response := h.checkAuth(r); response != nil {
+		return response
+	}
+
+	app, response := h.getApplication(r)
+	if response != nil {
+		return response
+	}
+
+	path, key, err := readPathAndKey(r, ps.ByName("path"))
+	if err != nil {
+		h.log.Warn("invalid path", "err", err)
+		return errBadRequest.WithMessage("Invalid path.")
+	}
+
+	// Values are only read into memory to detect their type if the client
+	// asked for JSON, otherwise they are streamed.
+	negotiate := acceptsJSON(r)
+	written := false
+
+	query := application.StreamValue{
+		Path: path,
+		Key:  key,
+		Writer: func(size int, reader io.Reader) error {
+			contentType := "application/octet-stream"
+
+			if negotiate {
+				b, err := ioutil.ReadAll(reader)
+				if err != nil {
+					return errors.Wrap(err, "could not read the value")
+				}
+
+				if application.DetectValueType(b) == application.ValueTypeJSON {
+					contentType = "application/json"
+				}
+				reader = bytes.NewReader(b)
+			}
+
+			written = true
+			w.Header().Set("Content-Type", contentType)
+			w.Header().Set("Content-Length", strconv.Itoa(size))
+			w.Header().Set("Vary", "Accept")
+			w.WriteHeader(http.StatusOK)
+			_, err := io.Copy(w, reader)
+			return err
+		},
+	}
+
+	if err := app.StreamValue.Execute(query); err != nil {
+		if written {
+			h.log.Warn("streaming the value failed after writing the response", "err", err)
+			return nil
+		}
+		if response, ok := applicationError(err); ok {
+			return response
+		}
+		h.log.Error("stream value failure", "err", err)
+		return errInternalServerError
+	}
+
+	return nil
+}
+
+func (h *Handler) putValue(r *http.Request) rest.RestResponse {
+	ps := httprouter.ParamsFromContext(r.Context())
+
+	if response := h.checkAuth(r); response != nil {
+		return response
+	}
+
+	app, response := h.getApplication(r)
+	if response != nil {
+		return response
+	}
+
+	path, key, err := readPathAndKey(r, ps.ByName("path"))
+	if err != nil {
+		h.log.Warn("invalid path", "err", err)
+		return errBadRequest.WithMessage("Invalid path.")
+	}
+
+	if len(path) == 0 {
+		return errBadRequest.WithMessage("Values can not be stored in the root of the database.")
+	}
+
+	b, err := h.readValueBody(r)
+	if err != nil {
+		if errors.Is(err, errBodyTooLarge) {
+			return h.valueTooLarge()
+		}
+		h.log.Warn("could not read the body", "err", err)
+		return errBadRequest.WithMessage("Could not read the body.")
+	}
+
+	value, err := application.NewValue(b)
+	if err != nil {
+		return errBadRequest.WithMessage("Invalid value.")
+	}
+
+	cmd := application.PutValue{
+		Path:         path,
+		Key:          key,
+		Value:        value,
+		ExpectedETag: parseETag(r.Header.Get("If-Match")),
+	}
+
+	if err := app.PutValue.Execute(cmd); err != nil {
+		if response, ok := applicationError(err); ok {
+			return response
+		}
+		h.log.Error("put value failure", "err", err)
+		return errInternalServerError
+	}
+
+	return rest.NewResponse(nil).WithHeader("ETag", formatETag(value.ETag()))
+}
+
+func (h *Handler) appendValue(r *http.Request) rest.RestResponse {
+	ps := httprouter.ParamsFromContext(r.Context())
+
+	if response := h.checkAuth(r); response != nil {
+		return response
+	}
+
+	app, response := h.getApplication(r)
+	if response != nil {
+		return response
+	}
+
+	path, err := readPath(r, ps.ByName("path"))
+	if err != nil {
+		h.log.Warn("invalid path", "err", err)
+		return errBadRequest.WithMessage("Invalid path.")
+	}
+
+	if len(path) == 0 {
+		return errBadRequest.WithMessage("Values can not be stored in the root of the database.")
+	}
+
+	b, err := h.readValueBody(r)
+	if err != nil {
+		if errors.Is(err, errBodyTooLarge) {
+			return h.valueTooLarge()
+		}
+		h.log.Warn("could not read the body", "err", err)
+		return errBadRequest.WithMessage("Could not read the body.")
+	}
+
+	value, err := application.NewValue(b)
+	if err != nil {
+		return errBadRequest.WithMessage("Invalid value.")
+	}
+
+	cmd := application.AppendValue{
+		Path:  path,
+		Value: value,
+	}
+
+	appended, err := app.AppendValue.Execute(cmd)
+	if err != nil {
+		if errors.Is(err, application.ErrValueExists) {
+			return errConflict.WithMessage("Key generated from the bucket sequence already exists.")
+		}
+		if response, ok := applicationError(err); ok {
+			return response
+		}
+		h.log.Error("append value failure", "err", err)
+		return errInternalServerError
+	}
+
+	return rest.NewResponse(
+		toAppendedValue(appended),
+	).WithHeader("ETag", formatETag(value.ETag()))
+}
+
+var errBodyTooLarge = errors.New("body too large")
+
+// readValueBody reads the value sent as the request body. The body is read
+// only up to the maximum value size so that oversized values don't have to
+// be kept in memory.
+func (h *Handler) readValueBody(r *http.Request) ([]byte, error) {
+	if h.conf.MaxValueSize <= 0 {
+		return ioutil.ReadAll(r.Body)
+	}
+
+	if r.ContentLength > int64(h.conf.MaxValueSize) {
+		return nil, errBodyTooLarge
+	}
+
+	b, err := ioutil.ReadAll(io.LimitReader(r.Body, int64(h.conf.MaxValueSize)+1))
+	if err != nil {
+		return nil, errors.Wrap(err, "read failed")
+	}
+
+	if len(b) > h.conf.MaxValueSize {
+		return nil, errBodyTooLarge
+	}
+
+	return b, nil
+}
+
+func (h *Handler) valueTooLarge() rest.RestResponse {
+	return errValueTooLarge.WithMessage(fmt.Sprintf("Value can not be larger than %d bytes.", h.conf.MaxValueSize))
+}
+
+// limitImportBody returns the request body which fails with errBodyTooLarge
+// once more than the maximum import size is read as the imported files are
+// kept in memory.
+func (h *Handler) limitImportBody(r *http.Request) io.Reader {
+	if h.conf.MaxImportSize <= 0 {
+		return r.Body
+	}
+	return &limitedReader{r: r.Body, n: int64(h.conf.MaxImportSize)}
+}
+
+func (h *Handler) importTooLarge() rest.RestResponse {
+	return errImportTooLarge.WithMessage(fmt.Sprintf("Imported file can not be larger than %d bytes.", h.conf.MaxImportSize))
+}
+
+// limitedReader works like io.LimitedReader but returns errBodyTooLarge
+// instead of io.EOF if the underlying reader has more data.
+type limitedReader struct {
+	r io.Reader
+	n int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.n < 0 {
+		return 0, errBodyTooLarge
+	}
+
+	if int64(len(p)) > l.n+1 {
+		p = p[:l.n+1]
+	}
+
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+	if l.n < 0 {
+		return n + int(l.n), errBodyTooLarge
+	}
+	return n, err
+}
+
+func formatETag(etag string) string {
+	return `"` + etag + `"`
+}
+
+// parseETag extracts the ETag from the If-Match header. An empty string is
+// returned if the header is missing or matches any value.
+func parseETag(header string) string {
+	header = strings.TrimSpace(header)
+	if header == "*" {
+		return ""
+	}
+	return strings.Trim(strings.TrimPrefix(header, "W/"), `"`)
+}
+
+func (h *Handler) renameKey(r *http.Request) rest.RestResponse {
+	ps := httprouter.ParamsFromContext(r.Context())
+
+	if response := h.checkAuth(r); response != nil {
+		return response
+	}
+
+	app, response := h.getApplication(r)
+	if response != nil {
+		return response
+	}
+
+	path, key, err := readPathAndKey(r, ps.ByName("path"))
+	if err != nil {
+		h.log.Warn("invalid path", "err", err)
+		return errBadRequest.WithMessage("Invalid path.")
+	}
+
+	if len(path) == 0 {
+		return errBadRequest.WithMessage("Values can not be stored in the root of the database.")
+	}
+
+	var renameKey RenameKey
+	if err := json.NewDecoder(r.Body).Decode(&renameKey); err != nil {
+		h.log.Warn("invalid rename key", "err", err)
+		return errBadRequest.WithMessage("Invalid JSON.")
+	}
+
+	encoding, err := readKeyEncoding(r)
+	if err != nil {
+		h.log.Warn("invalid encoding", "err", err)
+		return errBadRequest.WithMessage("Invalid encoding.")
+	}
+
+	newKey, err := fromKey(renameKey.NewKey, encoding)
+	if err != nil {
+		h.log.Warn("invalid rename key", "err", err)
+		return errBadRequest.WithMessage("Invalid new key.")
+	}
+
+	cmd := application.RenameKey{
+		Path:      path,
+		OldKey:    key,
+		NewKey:    newKey,
+		Overwrite: renameKey.Overwrite,
+	}
+
+	if err := app.RenameKey.Execute(cmd); err != nil {
+		if errors.Is(err, application.ErrValueExists) {
+			return errConflict.WithMessage("New key already exists.")
+		}
+		if response, ok := applicationError(err); ok {
+			return response
+		}
+		h.log.Error("rename key failure", "err", err)
+		return errInternalServerError
+	}
+
+	return rest.NewResponse(nil)
+}
+
+func (h *Handler) copyKey(r *http.Request) rest.RestResponse {
+	ps := httprouter.ParamsFromContext(r.Context())
+
+	if response := h.checkAuth(r); response != nil {
+		return response
+	}
+
+	app, response := h.getApplication(r)
+	if response != nil {
+		return response
+	}
+
+	path, key, err := readPathAndKey(r, ps.ByName("path"))
+	if err != nil {
+		h.log.Warn("invalid path", "err", err)
+		return errBadRequest.WithMessage("Invalid path.")
+	}
+
+	var copyKey CopyKey
+	if err := json.NewDecoder(r.Body).Decode(&copyKey); err != nil {
+		h.log.Warn("invalid copy key", "err", err)
+		return errBadRequest.WithMessage("Invalid JSON.")
+	}
+
+	encoding, err := readKeyEncoding(r)
+	if err != nil {
+		h.log.Warn("invalid encoding", "err", err)
+		return errBadRequest.WithMessage("Invalid encoding.")
+	}
+
+	destinationPath, destinationKey, err := fromCopyKey(copyKey, encoding)
+	if err != nil {
+		h.log.Warn("invalid copy key", "err", err)
+		return errBadRequest.WithMessage("Invalid destination.")
+	}
+
+	if len(path) == 0 || len(destinationPath) == 0 {
+		return errBadRequest.WithMessage("Values can not be stored in the root of the database.")
+	}
+
+	cmd := application.CopyKey{
+		SourcePath:      path,
+		SourceKey:       key,
+		DestinationPath: destinationPath,
+		DestinationKey:  destinationKey,
+		Move:            copyKey.Move,
+		Overwrite:       copyKey.Overwrite,
+	}
+
+	if err := app.CopyKey.Execute(cmd); err != nil {
+		if errors.Is(err, application.ErrValueExists) {
+			return errConflict.WithMessage("Destination key already exists.")
+		}
+		if response, ok := applicationError(err); ok {
+			return response
+		}
+		h.log.Error("copy key failure", "err", err)
+		return errInternalServerError
+	}
+
+	return rest.NewResponse(nil)
+}
+
+func (h *Handler) deleteKey(r *http.Request) rest.RestResponse {
+	ps := httprouter.ParamsFromContext(r.Context())
+
+	if response := h.checkAuth(r); response != nil {
+		return response
+	}
+
+	app, response := h.getApplication(r)
+	if response != nil {
+		return response
+	}
+
+	path, key, err := readPathAndKey(r, ps.ByName("path"))
+	if err != nil {
+		h.log.Warn("invalid path", "err", err)
+		return errBadRequest.WithMessage("Invalid path.")
+	}
+
+	cmd := application.DeleteKey{
+		Path: path,
+		Key:  key,
+	}
+
+	if err := app.DeleteKey.Execute(cmd); err != nil {
+		if response, ok := applicationError(err); ok {
+			return response
+		}
+		h.log.Error("delete key failure", "err", err)
+		return errInternalServerError
+	}
+
+	return rest.NewResponse(nil)
+}
+
+// healthz doesn't require authentication so that it can be used by liveness
+// and readiness probes.
+func (h *Handler) healthz(r *http.Request) rest.RestResponse {
+	for _, name := range h.databases.Names() {
+		app, ok := h.databases.Get(name)
+		if !ok {
+			continue
+		}
+
+		if err := app.CheckHealth.Execute(application.CheckHealth{}); err != nil {
+			h.log.Error("health check failure", "database", name, "err", err)
+			return errServiceUnavailable.WithMessage(fmt.Sprintf("Database '%s' is unavailable.", name))
+		}
+	}
+
+	return rest.NewResponse(
+		Health{
+			Status: "ok",
+		},
+	)
+}
+
+func (h *Handler) listDatabases(r *http.Request) rest.RestResponse {
+	if response := h.checkAuth(r); response != nil {
+		return response
+	}
+
+	return rest.NewResponse(
+		h.databases.Names(),
+	)
+}
+
+// rotateToken replaces the token and returns the new one so that the client
+// which requested the rotation can continue using the program. The previous
+// token stops working immediately.
+func (h *Handler) rotateToken(r *http.Request) rest.RestResponse {
+	if response := h.checkAuth(r); response != nil {
+		return response
+	}
+
+	token, err := h.authProvider.Rotate()
+	if err != nil {
+		if errors.Is(err, errTokenDisabled) {
+			return errTokenValidationDisabled
+		}
+		h.log.Error("token rotation failure", "err", err)
+		return errInternalServerError
+	}
+
+	h.log.Warn("access token rotated", "client", clientAddress(r, h.conf.TrustedProxyHeader))
+
+	return rest.NewResponse(
+		Token{
+			Token: token,
+		},
+	).WithHeader("Cache-Control", "no-store")
+}
+
+// getApplication returns the application operating on the database specified
+// in the request or a response which should be returned by the handler if the
+// database doesn't exist.
+func (h *Handler) getApplication(r *http.Request) (*application.Application, rest.RestResponse) {
+	ps := httprouter.ParamsFromContext(r.Context())
+
+	name := ps.ByName("database")
+	if name == "" {
+		name = h.databases.Names()[0]
+	}
+
+	app, ok := h.databases.Get(name)
+	if !ok {
+		return nil, errNotFound.WithMessage("Database not found.")
+	}
+
+	return app, nil
+}
+
+// checkAuth returns a response which should be returned by the handler if the
+// request is not authorized or nil otherwise. If the client has to wait
+// because of its previous failures 429 is returned without checking the
+// credentials.
+func (h *Handler) checkAuth(r *http.Request) rest.RestResponse {
+	client := clientAddress(r, h.conf.TrustedProxyHeader)
+
+	if wait := h.authBackoff.Wait(client, time.Now()); wait > 0 {
+		return errTooManyRequests.
+			WithMessage("Too many failed authentication attempts.").
+			WithHeader("Retry-After", formatRetryAfter(wait))
+	}
+
+	ok, err := h.authProvider.Check(r)
+	if err != nil {
+		h.log.Error("auth provider get failed", "err", err)
+		return errInternalServerError
+	}
+
+	h.metrics.recordAuth(ok)
+
+	if !ok {
+		if wait := h.authBackoff.Failure(client, time.Now()); wait > 0 {
+			return errUnauthorized.
+				WithMessage("Invalid token.").
+				WithHeader("Retry-After", formatRetryAfter(wait))
+		}
+		return errUnauthorized.WithMessage("Invalid token.")
+	}
+
+	h.authBackoff.Success(client)
+
+	return nil
+}
+
+// acceptsJSON returns true if the Accept header explicitly lists JSON.
+// Wildcards are ignored so that the clients which accept anything still
+// receive the raw bytes.
+func acceptsJSON(r *http.Request) bool {
+	for _, header := range r.Header.Values("Accept") {
+		for _, mediaRange := range strings.Split(header, ",") {
+			mediaType, params, err := mime.ParseMediaType(mediaRange)
+			if err != nil || mediaType != "application/json" {
+				continue
+			}
+
+			if q, ok := params["q"]; ok {
+				if v, err := strconv.ParseFloat(q, 64); err != nil || v <= 0 {
+					continue
+				}
+			}
+
+			return true
+		}
+	}
+	return false
+}
+
+func readLimit(r *http.Request) (int, error) {
+	limitString := r.URL.Query().Get("limit")
+	if limitString == "" {
+		return defaultListKeysLimit, nil
+	}
+
+	limit, err := strconv.Atoi(limitString)
+	if err != nil {
+		return 0, errors.Wrap(err, "atoi failed")
+	}
+
+	if limit <= 0 || limit > application.MaxListKeysLimit {
+		return 0, errors.New("limit out of range")
+	}
+
+	return limit, nil
+}
+
+// readListKeysOrder parses the sort query param. Prefixing the field with a
+// minus sign reverses the order.
+func readListKeysOrder(s string) (application.ListKeysOrder, error) {
+	switch s {
+	case "", "key":
+		return application.ListKeysOrderKeyAscending, nil
+	case "-key":
+		return application.ListKeysOrderKeyDescending, nil
+	case "size":
+		return application.ListKeysOrderSizeAscending, nil
+	case "-size":
+		return application.ListKeysOrderSizeDescending, nil
+	default:
+		return 0, errors.New("unknown order")
+	}
+}
+
+func readImportMode(s string) (application.ImportMode, error) {
+	switch s {
+	case "", "merge":
+		return application.ImportModeMerge, nil
+	case "replace":
+		return application.ImportModeReplace, nil
+	default:
+		return 0, errors.New("unknown import mode")
+	}
+}
+
+func readImportFormat(s string) (application.ImportFormat, error) {
+	switch s {
+	case "", "json":
+		return application.ImportFormatJSON, nil
+	case "csv":
+		return application.ImportFormatCSV, nil
+	default:
+		return 0, errors.New("unknown import format")
+	}
+}
+
+const sep = "/"
+
+// readPathAndKey reads a path in which the last element is a key pointing to
+// a value in the bucket specified by the preceding elements.
+func readPathAndKey(r *http.Request, s string) ([]application.Key, application.Key, error) {
+	path, err := readPath(r, s)
+	if err != nil {
+		return nil, application.Key{}, errors.Wrap(err, "could not read the path")
+	}
+
+	if len(path) == 0 {
+		return nil, application.Key{}, errors.New("path is empty")
+	}
+
+	return path[:len(path)-1], path[len(path)-1], nil
+}
 
-func readPath(s string) ([]application.Key, error) {
+func readPath(r *http.Request, s string) ([]application.Key, error) {
 	s = strings.Trim(s, sep)
 
 	if s == "" {
@@ -120,9 +2223,9 @@ func readPath(s string) ([]application.Key, error) {
 	var path []application.Key
 
 	for _, element := range strings.Split(s, "/") {
-		b, err := hex.DecodeString(element)
+		b, err := decodeKey(r, element)
 		if err != nil {
-			return nil, errors.Wrap(err, "could not decode")
+			return nil, errors.Wrap(err, "could not decode the key")
 		}
 
 		key, err := application.NewKey(b)
@@ -0,0 +1,65 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/boreq/errors"
+	"github.com/contentforward/bolt-ui/application"
+)
+
+const interpretationUint64 = "uint64"
+
+// interpretation specifies if the keys and values in the listings should be
+// additionally interpreted as big-endian unsigned integers. It is selected
+// using the interpretKeys=uint64 and interpretValues=uint64 query params.
+// Keys and values which aren't 8 bytes long are presented only in the raw
+// form. The integers are formatted as strings as JavaScript can't represent
+// all uint64 values. Keys encoded this way are already sorted numerically
+// by bolt so the listings don't have to be sorted again.
+type interpretation struct {
+	KeysAsUint64   bool
+	ValuesAsUint64 bool
+}
+
+func readInterpretation(r *http.Request) (interpretation, error) {
+	var result interpretation
+
+	switch r.URL.Query().Get("interpretKeys") {
+	case "":
+	case interpretationUint64:
+		result.KeysAsUint64 = true
+	default:
+		return interpretation{}, errors.New("unknown key interpretation")
+	}
+
+	switch r.URL.Query().Get("interpretValues") {
+	case "":
+	case interpretationUint64:
+		result.ValuesAsUint64 = true
+	default:
+		return interpretation{}, errors.New("unknown value interpretation")
+	}
+
+	return result, nil
+}
+
+func (i interpretation) key(key application.Key) Key {
+	result := toKey(key)
+	if i.KeysAsUint64 {
+		if n, ok := key.Uint64(); ok {
+			result.Uint64 = strconv.FormatUint(n, 10)
+		}
+	}
+	return result
+}
+
+func (i interpretation) value(value application.Value) Value {
+	result := toValueNotNil(value)
+	if i.ValuesAsUint64 {
+		if n, ok := value.Uint64(); ok {
+			result.Uint64 = strconv.FormatUint(n, 10)
+		}
+	}
+	return result
+}
@@ -0,0 +1,300 @@
+package http
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/contentforward/bolt-ui/application"
+)
+
+// requestDurationBuckets are the upper bounds of the request duration
+// histogram buckets in seconds. They match the default buckets of the
+// Prometheus client libraries.
+var requestDurationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// metrics collects the request metrics and exposes them in the Prometheus
+// text exposition format. The format is written directly as the program only
+// exposes a handful of counters, gauges and a single histogram which doesn't
+// justify depending on the Prometheus client library.
+type metrics struct {
+	mutex        sync.Mutex
+	requests     map[requestLabels]*requestMetrics
+	authSuccess  int
+	authFailures int
+}
+
+type requestLabels struct {
+	Method string
+	Route  string
+	Status int
+}
+
+type requestMetrics struct {
+	Count    int
+	Duration time.Duration
+
+	// Buckets contains the number of requests which took at most the
+	// corresponding number of seconds in requestDurationBuckets.
+	Buckets []int
+}
+
+func newMetrics() *metrics {
+	return &metrics{
+		requests: make(map[requestLabels]*requestMetrics),
+	}
+}
+
+// instrument records the number of requests and their duration. The route
+// is used as a label instead of the request path so that the number of
+// reported series doesn't depend on the contents of the database.
+func (m *metrics) instrument(route string, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := newStatusRecorder(w)
+
+		handler.ServeHTTP(recorder, r)
+
+		m.recordRequest(requestLabels{
+			Method: r.Method,
+			Route:  route,
+			Status: recorder.Status(),
+		}, time.Since(start))
+	})
+}
+
+func (m *metrics) recordRequest(labels requestLabels, duration time.Duration) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	metric, ok := m.requests[labels]
+	if !ok {
+		metric = &requestMetrics{
+			Buckets: make([]int, len(requestDurationBuckets)),
+		}
+		m.requests[labels] = metric
+	}
+
+	metric.Count++
+	metric.Duration += duration
+
+	for i, bound := range requestDurationBuckets {
+		if duration.Seconds() <= bound {
+			metric.Buckets[i]++
+		}
+	}
+}
+
+func (m *metrics) recordAuth(ok bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if ok {
+		m.authSuccess++
+	} else {
+		m.authFailures++
+	}
+}
+
+func (m *metrics) writeRequests(w *metricsWriter) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	var labels []requestLabels
+	for l := range m.requests {
+		labels = append(labels, l)
+	}
+
+	sort.Slice(labels, func(i, j int) bool {
+		if labels[i].Route != labels[j].Route {
+			return labels[i].Route < labels[j].Route
+		}
+		if labels[i].Method != labels[j].Method {
+			return labels[i].Method < labels[j].Method
+		}
+		return labels[i].Status < labels[j].Status
+	})
+
+	w.Header("bolt_ui_http_requests_total", "counter", "Number of handled HTTP requests.")
+	for _, l := range labels {
+		w.Value("bolt_ui_http_requests_total", requestLabelsToMetricLabels(l), float64(m.requests[l].Count))
+	}
+
+	w.Header("bolt_ui_http_request_duration_seconds", "histogram", "Time spent handling HTTP requests.")
+	for _, l := range labels {
+		for i, bound := range requestDurationBuckets {
+			bucketLabels := append(requestLabelsToMetricLabels(l), metricLabel{"le", strconv.FormatFloat(bound, 'g', -1, 64)})
+			w.Value("bolt_ui_http_request_duration_seconds_bucket", bucketLabels, float64(m.requests[l].Buckets[i]))
+		}
+		w.Value("bolt_ui_http_request_duration_seconds_bucket", append(requestLabelsToMetricLabels(l), metricLabel{"le", "+Inf"}), float64(m.requests[l].Count))
+		w.Value("bolt_ui_http_request_duration_seconds_sum", requestLabelsToMetricLabels(l), m.requests[l].Duration.Seconds())
+		w.Value("bolt_ui_http_request_duration_seconds_count", requestLabelsToMetricLabels(l), float64(m.requests[l].Count))
+	}
+
+	w.Header("bolt_ui_auth_checks_total", "counter", "Number of performed authentication checks.")
+	w.Value("bolt_ui_auth_checks_total", []metricLabel{{"result", "success"}}, float64(m.authSuccess))
+	w.Value("bolt_ui_auth_checks_total", []metricLabel{{"result", "failure"}}, float64(m.authFailures))
+}
+
+func requestLabelsToMetricLabels(l requestLabels) []metricLabel {
+	return []metricLabel{
+		{"method", l.Method},
+		{"route", l.Route},
+		{"status", strconv.Itoa(l.Status)},
+	}
+}
+
+type databaseMetrics struct {
+	Name        string
+	Stats       application.DatabaseStats
+	BucketStats map[string]application.BucketStats
+}
+
+func writeDatabaseMetrics(w *metricsWriter, databases []databaseMetrics) {
+	values := []struct {
+		Name  string
+		Type  string
+		Help  string
+		Value func(stats application.DatabaseStats) float64
+	}{
+		{"bolt_ui_db_size_bytes", "gauge", "Size of the database file.", func(s application.DatabaseStats) float64 { return float64(s.Size) }},
+		{"bolt_ui_db_free_pages", "gauge", "Number of free pages on the freelist.", func(s application.DatabaseStats) float64 { return float64(s.FreePageN) }},
+		{"bolt_ui_db_pending_pages", "gauge", "Number of pending pages on the freelist.", func(s application.DatabaseStats) float64 { return float64(s.PendingPageN) }},
+		{"bolt_ui_db_free_alloc_bytes", "gauge", "Bytes allocated in free pages.", func(s application.DatabaseStats) float64 { return float64(s.FreeAlloc) }},
+		{"bolt_ui_db_freelist_inuse_bytes", "gauge", "Bytes used by the freelist.", func(s application.DatabaseStats) float64 { return float64(s.FreelistInuse) }},
+		{"bolt_ui_db_read_tx_total", "counter", "Number of started read transactions.", func(s application.DatabaseStats) float64 { return float64(s.TxN) }},
+		{"bolt_ui_db_open_read_tx", "gauge", "Number of currently open read transactions.", func(s application.DatabaseStats) float64 { return float64(s.OpenTxN) }},
+		{"bolt_ui_db_page_allocations_total", "counter", "Number of page allocations.", func(s application.DatabaseStats) float64 { return float64(s.PageCount) }},
+		{"bolt_ui_db_page_alloc_bytes_total", "counter", "Bytes allocated in pages.", func(s application.DatabaseStats) float64 { return float64(s.PageAlloc) }},
+	}
+
+	for _, value := range values {
+		w.Header(value.Name, value.Type, value.Help)
+		for _, database := range databases {
+			w.Value(value.Name, []metricLabel{{"database", database.Name}}, value.Value(database.Stats))
+		}
+	}
+
+	w.Header("bolt_ui_bucket_keys", "gauge", "Number of keys in the bucket including nested buckets.")
+	for _, database := range databases {
+		var buckets []string
+		for bucket := range database.BucketStats {
+			buckets = append(buckets, bucket)
+		}
+		sort.Strings(buckets)
+
+		for _, bucket := range buckets {
+			labels := []metricLabel{{"database", database.Name}, {"bucket", bucket}}
+			w.Value("bolt_ui_bucket_keys", labels, float64(database.BucketStats[bucket].KeyN))
+		}
+	}
+}
+
+type metricLabel struct {
+	Name  string
+	Value string
+}
+
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// metricsWriter writes metrics in the Prometheus text exposition format.
+type metricsWriter struct {
+	w   io.Writer
+	err error
+}
+
+func newMetricsWriter(w io.Writer) *metricsWriter {
+	return &metricsWriter{
+		w: w,
+	}
+}
+
+func (w *metricsWriter) Header(name, metricType, help string) {
+	w.printf("# HELP %s %s\n", name, help)
+	w.printf("# TYPE %s %s\n", name, metricType)
+}
+
+func (w *metricsWriter) Value(name string, labels []metricLabel, value float64) {
+	var formattedLabels []string
+	for _, label := range labels {
+		formattedLabels = append(formattedLabels, fmt.Sprintf(`%s="%s"`, label.Name, labelValueReplacer.Replace(label.Value)))
+	}
+
+	if len(formattedLabels) > 0 {
+		w.printf("%s{%s} %s\n", name, strings.Join(formattedLabels, ","), strconv.FormatFloat(value, 'g', -1, 64))
+	} else {
+		w.printf("%s %s\n", name, strconv.FormatFloat(value, 'g', -1, 64))
+	}
+}
+
+func (w *metricsWriter) Err() error {
+	return w.err
+}
+
+func (w *metricsWriter) printf(format string, a ...interface{}) {
+	if w.err != nil {
+		return
+	}
+	_, w.err = fmt.Fprintf(w.w, format, a...)
+}
+
+// statusRecorder remembers the status code written to the underlying
+// response writer.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func newStatusRecorder(w http.ResponseWriter) *statusRecorder {
+	return &statusRecorder{
+		ResponseWriter: w,
+	}
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	if s.status == 0 {
+		s.status = status
+	}
+	s.ResponseWriter.WriteHeader(status)
+}
+
+func (s *statusRecorder) Write(b []byte) (int, error) {
+	if s.status == 0 {
+		s.status = http.StatusOK
+	}
+	return s.ResponseWriter.Write(b)
+}
+
+func (s *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := s.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer can not be hijacked")
+	}
+	if s.status == 0 {
+		s.status = http.StatusSwitchingProtocols
+	}
+	return hijacker.Hijack()
+}
+
+func (s *statusRecorder) Flush() {
+	if flusher, ok := s.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Status returns the written status code. If nothing was written then
+// http.StatusOK is returned as that is what the server will send.
+func (s *statusRecorder) Status() int {
+	if s.status == 0 {
+		return http.StatusOK
+	}
+	return s.status
+}
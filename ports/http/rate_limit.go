@@ -0,0 +1,143 @@
+package http
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/boreq/rest"
+)
+
+// rateLimiterCleanupInterval specifies how often the buckets of the clients
+// which stopped making requests are removed.
+const rateLimiterCleanupInterval = time.Minute
+
+// rateLimiter implements a token bucket per client.
+type rateLimiter struct {
+	rate  float64
+	burst float64
+
+	mutex       sync.Mutex
+	buckets     map[string]*tokenBucket
+	lastCleanup time.Time
+}
+
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// newRateLimiter creates a limiter which permits rate requests per second on
+// average with bursts of up to burst requests.
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	return &rateLimiter{
+		rate:        rate,
+		burst:       float64(burst),
+		buckets:     make(map[string]*tokenBucket),
+		lastCleanup: time.Now(),
+	}
+}
+
+// Allow consumes a token from the bucket of the client. If the request is not
+// allowed the returned duration specifies when the next token will become
+// available.
+func (l *rateLimiter) Allow(client string, now time.Time) (bool, time.Duration) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if now.Sub(l.lastCleanup) > rateLimiterCleanupInterval {
+		l.cleanup(now)
+		l.lastCleanup = now
+	}
+
+	bucket, ok := l.buckets[client]
+	if !ok {
+		bucket = &tokenBucket{
+			tokens:  l.burst,
+			updated: now,
+		}
+		l.buckets[client] = bucket
+	}
+
+	l.refill(bucket, now)
+
+	if bucket.tokens < 1 {
+		wait := time.Duration((1 - bucket.tokens) / l.rate * float64(time.Second))
+		return false, wait
+	}
+
+	bucket.tokens--
+	return true, 0
+}
+
+func (l *rateLimiter) refill(bucket *tokenBucket, now time.Time) {
+	elapsed := now.Sub(bucket.updated).Seconds()
+	if elapsed > 0 {
+		bucket.tokens = math.Min(l.burst, bucket.tokens+elapsed*l.rate)
+		bucket.updated = now
+	}
+}
+
+// cleanup removes the buckets which are full as they are equivalent to
+// buckets which don't exist.
+func (l *rateLimiter) cleanup(now time.Time) {
+	for client, bucket := range l.buckets {
+		l.refill(bucket, now)
+		if bucket.tokens >= l.burst {
+			delete(l.buckets, client)
+		}
+	}
+}
+
+// rateLimit rejects the requests of clients which exceeded the limit. The
+// health check endpoint served under the base path is exempt. If
+// trustedProxyHeader is not empty the client address is read from the last
+// value of that header which should be set by a trusted proxy.
+func rateLimit(handler http.Handler, limiter *rateLimiter, basePath string, trustedProxyHeader string) http.Handler {
+	healthzPath := basePath + "/healthz"
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == healthzPath {
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		ok, wait := limiter.Allow(clientAddress(r, trustedProxyHeader), time.Now())
+		if !ok {
+			w.Header().Set("Retry-After", formatRetryAfter(wait))
+			rest.Wrap(func(r *http.Request) rest.RestResponse {
+				return errTooManyRequests
+			})(w, r)
+			return
+		}
+
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// formatRetryAfter formats the value of the Retry-After header which is
+// specified in whole seconds.
+func formatRetryAfter(wait time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(wait.Seconds())))
+}
+
+func clientAddress(r *http.Request, trustedProxyHeader string) string {
+	if trustedProxyHeader != "" {
+		if values := r.Header.Values(trustedProxyHeader); len(values) > 0 {
+			addresses := strings.Split(values[len(values)-1], ",")
+			if address := strings.TrimSpace(addresses[len(addresses)-1]); address != "" {
+				return address
+			}
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
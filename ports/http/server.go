@@ -1,9 +1,11 @@
 package http
 
 import (
+	"context"
 	"crypto/tls"
 	"net"
 	"net/http"
+	"sync"
 
 	"github.com/NYTimes/gziphandler"
 	"github.com/boreq/errors"
@@ -12,10 +14,28 @@ import (
 	"github.com/rs/cors"
 )
 
+// compressibleContentTypes lists the content types which are worth
+// compressing. Other content types such as images are usually already
+// compressed.
+var compressibleContentTypes = []string{
+	"application/json",
+	"application/javascript",
+	"application/octet-stream",
+	"image/svg+xml",
+	"text/css",
+	"text/html",
+	"text/javascript",
+	"text/plain",
+}
+
 type Server struct {
 	handler http.Handler
 	conf    *config.Config
 	log     logging.Logger
+	server  *http.Server
+
+	mutex    sync.Mutex
+	listener net.Listener
 }
 
 func NewServer(handler http.Handler, conf *config.Config) *Server {
@@ -23,36 +43,130 @@ func NewServer(handler http.Handler, conf *config.Config) *Server {
 		handler: handler,
 		conf:    conf,
 		log:     logging.New("ports/http.Server"),
+		server:  &http.Server{},
 	}
 }
 
+// Listen binds the configured address. Calling it is optional as Serve calls
+// it if needed, but it makes it possible to learn the bound address using
+// Addr before calling Serve e.g. when listening on port 0.
+func (s *Server) Listen() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.listener != nil {
+		return nil
+	}
+
+	l, err := s.listen()
+	if err != nil {
+		return errors.Wrap(err, "could not create listener")
+	}
+
+	s.listener = l
+	return nil
+}
+
+// Addr returns the bound address or nil if the server isn't listening yet.
+func (s *Server) Addr() net.Addr {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Addr()
+}
+
+// Serve blocks until the server stops. If the server is stopped using
+// Shutdown then nil is returned.
 func (s *Server) Serve() error {
 	handler := s.handler
 
 	if s.conf.InsecureCORS {
 		handler = cors.AllowAll().Handler(s.handler)
+	} else if len(s.conf.CORSOrigins) > 0 {
+		handler = cors.New(cors.Options{
+			AllowedOrigins: s.conf.CORSOrigins,
+			AllowedMethods: []string{
+				http.MethodGet,
+				http.MethodPost,
+				http.MethodPut,
+				http.MethodDelete,
+			},
+			AllowedHeaders: []string{
+				"Access-Token",
+				"Authorization",
+				"Content-Type",
+				"If-Match",
+			},
+			ExposedHeaders: []string{
+				"ETag",
+			},
+			AllowCredentials: true,
+		}).Handler(s.handler)
+	}
+
+	if s.conf.Compression {
+		compress, err := gziphandler.GzipHandlerWithOpts(
+			gziphandler.MinSize(s.conf.CompressionMinSize),
+			gziphandler.ContentTypes(compressibleContentTypes),
+		)
+		if err != nil {
+			return errors.Wrap(err, "could not create the compression handler")
+		}
+		handler = compress(handler)
 	}
 
-	handler = gziphandler.GzipHandler(handler)
+	if s.conf.RateLimit > 0 {
+		handler = rateLimit(handler, newRateLimiter(s.conf.RateLimit, s.conf.RateLimitBurst), s.conf.BasePath, s.conf.TrustedProxyHeader)
+	}
 
+	if s.conf.AccessLog {
+		handler = accessLog(handler, logging.New("ports/http.AccessLog"), s.conf.AccessLogLevel, s.conf.AccessLogBodies)
+	}
+
+	if err := s.Listen(); err != nil {
+		return errors.Wrap(err, "could not listen")
+	}
+
+	s.mutex.Lock()
+	l := s.listener
+	s.mutex.Unlock()
+
+	s.server.Handler = handler
+
+	if err := s.server.Serve(l); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return errors.Wrap(err, "serve error")
+	}
+
+	return nil
+}
+
+// Shutdown stops accepting new connections and waits for the in-flight
+// requests to complete or for the context to be cancelled, whichever comes
+// first. Calling Shutdown before Serve causes Serve to return immediately.
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.server.Shutdown(ctx)
+}
+
+func (s *Server) listen() (net.Listener, error) {
 	if s.conf.InsecureTLS {
 		s.log.Debug("starting an insecure listener", "address", s.conf.ServeAddress)
-		return http.ListenAndServe(s.conf.ServeAddress, handler)
+		return net.Listen("tcp", s.conf.ServeAddress)
 	}
 
 	s.log.Debug("starting listening", "address", s.conf.ServeAddress)
 
 	l, err := net.Listen("tcp", s.conf.ServeAddress)
 	if err != nil {
-		return errors.Wrap(err, "could not create listener")
+		return nil, err
 	}
 
-	l = tls.NewListener(l, &tls.Config{
+	return tls.NewListener(l, &tls.Config{
 		Certificates: []tls.Certificate{
 			s.conf.Certificate,
 		},
-	})
-
-	return http.Serve(l, handler)
-
+		MinVersion: s.conf.TLSMinVersion,
+	}), nil
 }
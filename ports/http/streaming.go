@@ -0,0 +1,56 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/boreq/rest"
+)
+
+// streamingHandlerFunc is a handler which writes the response body directly
+// to the response writer. The returned response is written only if it is not
+// nil which means that the handler must return nil if it already started
+// writing the body.
+type streamingHandlerFunc func(w http.ResponseWriter, r *http.Request) rest.RestResponse
+
+func wrapStreaming(handler streamingHandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if response := handler(w, r); response != nil {
+			rest.Wrap(func(r *http.Request) rest.RestResponse {
+				return response
+			})(w, r)
+		}
+	}
+}
+
+// attachmentWriter delays writing the headers which instruct the browser to
+// download the response as a file until the first call to Write. This way an
+// error response can still be returned if nothing was written.
+type attachmentWriter struct {
+	w           http.ResponseWriter
+	contentType string
+	filename    string
+	written     bool
+}
+
+func newAttachmentWriter(w http.ResponseWriter, contentType string, filename string) *attachmentWriter {
+	return &attachmentWriter{
+		w:           w,
+		contentType: contentType,
+		filename:    filename,
+	}
+}
+
+func (a *attachmentWriter) Write(b []byte) (int, error) {
+	if !a.written {
+		a.written = true
+		a.w.Header().Set("Content-Type", a.contentType)
+		a.w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, a.filename))
+		a.w.WriteHeader(http.StatusOK)
+	}
+	return a.w.Write(b)
+}
+
+func (a *attachmentWriter) Written() bool {
+	return a.written
+}
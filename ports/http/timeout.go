@@ -0,0 +1,23 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// withTimeout attaches a deadline to the context of each request. The
+// operations which iterate over large buckets stop once the deadline is
+// exceeded. A timeout of zero disables the deadline.
+func withTimeout(handler http.HandlerFunc, timeout time.Duration) http.HandlerFunc {
+	if timeout <= 0 {
+		return handler
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+
+		handler(w, r.WithContext(ctx))
+	}
+}
@@ -0,0 +1,242 @@
+package http
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/boreq/errors"
+)
+
+// This file implements the small subset of the WebSocket protocol (RFC 6455)
+// needed to push messages to the clients. Messages sent by the clients are
+// discarded.
+
+const (
+	websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+	websocketWriteTimeout   = 10 * time.Second
+	websocketMaxPayloadSize = 64 * 1024
+
+	websocketOpcodeText  = 0x1
+	websocketOpcodeClose = 0x8
+	websocketOpcodePing  = 0x9
+	websocketOpcodePong  = 0xa
+)
+
+var errWebsocketClosed = errors.New("websocket closed")
+
+type websocketConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+
+	mutex  sync.Mutex
+	closed chan struct{}
+	once   sync.Once
+}
+
+// upgradeWebsocket performs the opening handshake. If an error is returned
+// the response was not written.
+func upgradeWebsocket(w http.ResponseWriter, r *http.Request) (*websocketConn, error) {
+	if r.Method != http.MethodGet {
+		return nil, errors.New("method must be GET")
+	}
+
+	if !headerContainsToken(r.Header, "Connection", "upgrade") || !headerContainsToken(r.Header, "Upgrade", "websocket") {
+		return nil, errors.New("not a websocket upgrade request")
+	}
+
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		return nil, errors.New("unsupported websocket version")
+	}
+
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if key == "" {
+		return nil, errors.New("missing websocket key")
+	}
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		return nil, errors.New("response writer can not be hijacked")
+	}
+
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, errors.Wrap(err, "hijack failed")
+	}
+
+	response := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + websocketAccept(key) + "\r\n\r\n"
+
+	if err := conn.SetWriteDeadline(time.Now().Add(websocketWriteTimeout)); err != nil {
+		conn.Close()
+		return nil, errors.Wrap(err, "could not set the deadline")
+	}
+
+	if _, err := conn.Write([]byte(response)); err != nil {
+		conn.Close()
+		return nil, errors.Wrap(err, "could not write the handshake response")
+	}
+
+	c := &websocketConn{
+		conn:   conn,
+		reader: rw.Reader,
+		closed: make(chan struct{}),
+	}
+
+	go c.readLoop()
+
+	return c, nil
+}
+
+// WriteText sends a text message. It doesn't block for longer than the write
+// timeout.
+func (c *websocketConn) WriteText(payload []byte) error {
+	return c.writeFrame(websocketOpcodeText, payload)
+}
+
+// Closed is closed when the connection is closed.
+func (c *websocketConn) Closed() <-chan struct{} {
+	return c.closed
+}
+
+func (c *websocketConn) Close() error {
+	var err error
+	c.once.Do(func() {
+		close(c.closed)
+		err = c.conn.Close()
+	})
+	return err
+}
+
+func (c *websocketConn) readLoop() {
+	defer c.Close()
+
+	for {
+		opcode, payload, err := c.readFrame()
+		if err != nil {
+			return
+		}
+
+		switch opcode {
+		case websocketOpcodeClose:
+			c.writeFrame(websocketOpcodeClose, payload)
+			return
+		case websocketOpcodePing:
+			if err := c.writeFrame(websocketOpcodePong, payload); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (c *websocketConn) readFrame() (byte, []byte, error) {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(c.reader, header); err != nil {
+		return 0, nil, errors.Wrap(err, "could not read the header")
+	}
+
+	opcode := header[0] & 0x0f
+	masked := header[1]&0x80 != 0
+	length := uint64(header[1] & 0x7f)
+
+	switch length {
+	case 126:
+		b := make([]byte, 2)
+		if _, err := io.ReadFull(c.reader, b); err != nil {
+			return 0, nil, errors.Wrap(err, "could not read the length")
+		}
+		length = uint64(binary.BigEndian.Uint16(b))
+	case 127:
+		b := make([]byte, 8)
+		if _, err := io.ReadFull(c.reader, b); err != nil {
+			return 0, nil, errors.Wrap(err, "could not read the length")
+		}
+		length = binary.BigEndian.Uint64(b)
+	}
+
+	if !masked {
+		return 0, nil, errors.New("client frames must be masked")
+	}
+
+	if length > websocketMaxPayloadSize {
+		return 0, nil, errors.New("frame too large")
+	}
+
+	mask := make([]byte, 4)
+	if _, err := io.ReadFull(c.reader, mask); err != nil {
+		return 0, nil, errors.Wrap(err, "could not read the mask")
+	}
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(c.reader, payload); err != nil {
+		return 0, nil, errors.Wrap(err, "could not read the payload")
+	}
+
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+
+	return opcode, payload, nil
+}
+
+func (c *websocketConn) writeFrame(opcode byte, payload []byte) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	select {
+	case <-c.closed:
+		return errWebsocketClosed
+	default:
+	}
+
+	frame := []byte{0x80 | opcode}
+
+	switch {
+	case len(payload) < 126:
+		frame = append(frame, byte(len(payload)))
+	case len(payload) <= 0xffff:
+		frame = append(frame, 126, 0, 0)
+		binary.BigEndian.PutUint16(frame[2:], uint16(len(payload)))
+	default:
+		frame = append(frame, 127, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(frame[2:], uint64(len(payload)))
+	}
+
+	frame = append(frame, payload...)
+
+	if err := c.conn.SetWriteDeadline(time.Now().Add(websocketWriteTimeout)); err != nil {
+		return errors.Wrap(err, "could not set the deadline")
+	}
+
+	if _, err := c.conn.Write(frame); err != nil {
+		return errors.Wrap(err, "write failed")
+	}
+
+	return nil
+}
+
+func websocketAccept(key string) string {
+	h := sha1.Sum([]byte(key + websocketGUID))
+	return base64.StdEncoding.EncodeToString(h[:])
+}
+
+func headerContainsToken(header http.Header, name, token string) bool {
+	for _, value := range header.Values(name) {
+		for _, t := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+	return false
+}